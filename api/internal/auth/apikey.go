@@ -0,0 +1,30 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// apiKeyPrefix marks ChaseApp API keys so leaked keys are recognisable.
+const apiKeyPrefix = "cak_"
+
+// GenerateAPIKey returns a new random API key and the short prefix shown in
+// key listings.
+func GenerateAPIKey() (key, prefix string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("generate api key: %w", err)
+	}
+	key = apiKeyPrefix + base64.RawURLEncoding.EncodeToString(buf)
+	return key, key[:len(apiKeyPrefix)+6], nil
+}
+
+// HashAPIKey returns the stored form of an API key. Keys carry 256 bits of
+// entropy, so a fast unsalted hash is sufficient.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,221 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// jwksMinRefetch rate-limits refetches triggered by unknown key IDs, so
+// tokens with bogus kids cannot hammer the identity provider.
+const jwksMinRefetch = time.Minute
+
+// After a failed fetch, the next waits jwksRetryMin, doubling with each
+// further failure up to jwksRetryMax, so an unavailable provider is not
+// retried on every request.
+const (
+	jwksRetryMin = 5 * time.Second
+	jwksRetryMax = 5 * time.Minute
+)
+
+// jwk is a single JSON Web Key.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// JWKS fetches and caches the signing keys published at a JWKS URL. Keys are
+// refreshed after the refresh interval, or early when a token names a key ID
+// the cache does not know, which picks up provider key rotation. Fetches
+// run outside the lock, one at a time; requests for cached keys do not wait
+// for them, and cached keys are served while the provider is unavailable.
+type JWKS struct {
+	url     string
+	refresh time.Duration
+	client  *http.Client
+
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+	fetching  chan struct{} // Closed when the fetch in progress finishes
+	failures  int           // Consecutive failed fetches
+	retryAt   time.Time     // No fetch before this after a failure
+	err       error         // Error of the last failed fetch
+}
+
+// NewJWKS creates a JWKS cache for url.
+func NewJWKS(url string, refresh time.Duration) *JWKS {
+	if refresh <= 0 {
+		refresh = time.Hour
+	}
+	return &JWKS{
+		url:     url,
+		refresh: refresh,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Key returns the public key with the given ID.
+func (j *JWKS) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	j.mu.Lock()
+	now := time.Now()
+	key, err := j.lookup(kid)
+	stale := now.Sub(j.fetchedAt) > j.refresh
+	refetch := stale || (err != nil && now.Sub(j.fetchedAt) > jwksMinRefetch)
+	if !refetch || now.Before(j.retryAt) {
+		j.mu.Unlock()
+		return key, err
+	}
+	if j.fetching == nil {
+		j.fetching = make(chan struct{})
+		// The fetch serves every waiting request, so it must not be
+		// cancelled with this one.
+		go j.fetch(context.WithoutCancel(ctx), j.fetching)
+	}
+	done := j.fetching
+	j.mu.Unlock()
+
+	if err == nil {
+		return key, nil
+	}
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.lookup(kid)
+}
+
+// lookup returns the cached key with the given ID. A token without a key ID
+// is accepted when the set has a single key. j.mu must be held.
+func (j *JWKS) lookup(kid string) (crypto.PublicKey, error) {
+	if j.keys == nil && j.err != nil {
+		return nil, j.err
+	}
+	if kid == "" && len(j.keys) == 1 {
+		for _, key := range j.keys {
+			return key, nil
+		}
+	}
+	key, ok := j.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+// fetch replaces the cached keys with those published at the URL, or backs
+// off further fetches if that fails, then closes done.
+func (j *JWKS) fetch(ctx context.Context, done chan struct{}) {
+	keys, err := j.fetchKeys(ctx)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err != nil {
+		j.failures++
+		j.retryAt = time.Now().Add(min(jwksRetryMin<<min(j.failures-1, 10), jwksRetryMax))
+		j.err = err
+	} else {
+		j.keys = keys
+		j.fetchedAt = time.Now()
+		j.failures = 0
+		j.retryAt = time.Time{}
+		j.err = nil
+	}
+	j.fetching = nil
+	close(done)
+}
+
+func (j *JWKS) fetchKeys(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create jwks request: %w", err)
+	}
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch jwks: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch jwks: status %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("decode jwks: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			// Skip key types we do not verify rather than failing the set.
+			continue
+		}
+		keys[k.Kid] = key
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("decode modulus: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("decode exponent: %w", err)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("decode x: %w", err)
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("decode y: %w", err)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	default:
+		return nil, errors.New("unsupported key type " + k.Kty)
+	}
+}
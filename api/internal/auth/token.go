@@ -7,80 +7,197 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
+
 	"chaseapp.tv/api/internal/config"
 )
 
-// ChatTokenSigner creates signed JWTs for chat authentication.
+// defaultChatKeyID is the kid of the legacy CHAT_SIGNING_KEY.
+const defaultChatKeyID = "default"
+
+// ChatTokenSigner creates and verifies signed JWTs for chat authentication.
+// Tokens are signed with the active key and carry its ID in the kid header;
+// every configured key verifies, so keys can be rotated without
+// invalidating tokens already issued.
 type ChatTokenSigner struct {
-	cfg config.ChatConfig
+	cfg    config.ChatConfig
+	keys   map[string][]byte
+	active string
+	now    func() time.Time
 }
 
 // NewChatTokenSigner creates a new signer.
 func NewChatTokenSigner(cfg config.ChatConfig) (*ChatTokenSigner, error) {
-	if cfg.SigningKey == "" {
+	keys := cfg.SigningKeys
+	if len(keys) == 0 && cfg.SigningKey != "" {
+		keys = []config.ChatSigningKey{{ID: defaultChatKeyID, Secret: cfg.SigningKey}}
+	}
+	if len(keys) == 0 {
 		return nil, errors.New("chat signing key is required")
 	}
 	if cfg.TokenTTL <= 0 {
 		cfg.TokenTTL = 15 * time.Minute
 	}
-	return &ChatTokenSigner{cfg: cfg}, nil
+
+	s := &ChatTokenSigner{
+		cfg:    cfg,
+		keys:   make(map[string][]byte, len(keys)),
+		active: cfg.ActiveKeyID,
+		now:    time.Now,
+	}
+	for _, k := range keys {
+		s.keys[k.ID] = []byte(k.Secret)
+	}
+	if s.active == "" {
+		s.active = keys[0].ID
+	}
+	if _, ok := s.keys[s.active]; !ok {
+		return nil, fmt.Errorf("chat signing key %q is not configured", s.active)
+	}
+	return s, nil
 }
 
 // ChatClaims are the claims embedded in the chat token.
 type ChatClaims struct {
-	Issuer      string    `json:"iss,omitempty"`
-	Audience    string    `json:"aud,omitempty"`
-	Subject     string    `json:"sub"`
-	Email       string    `json:"email,omitempty"`
-	Permissions []string  `json:"permissions"`
-	IssuedAt    time.Time `json:"iat"`
-	ExpiresAt   time.Time `json:"exp"`
+	ID          string   `json:"jti"`
+	Issuer      string   `json:"iss,omitempty"`
+	Audience    string   `json:"aud,omitempty"`
+	Subject     string   `json:"sub"`
+	Email       string   `json:"email,omitempty"`
+	Role        string   `json:"role,omitempty"`
+	Channel     string   `json:"channel,omitempty"`
+	Permissions []string `json:"permissions"`
+	IssuedAt    int64    `json:"iat"`
+	ExpiresAt   int64    `json:"exp"`
 }
 
-// Sign builds an HS256 JWT for the provided user and permissions.
-func (s *ChatTokenSigner) Sign(userID, email string, permissions []string) (string, error) {
+// Expiry returns the exp claim as a time.
+func (c *ChatClaims) Expiry() time.Time {
+	return time.Unix(c.ExpiresAt, 0)
+}
+
+// ChatGrant is what a chat token allows: the user's chat role and the
+// permissions they hold, scoped to Channel when it is set.
+type ChatGrant struct {
+	Role        string
+	Channel     string
+	Permissions []string
+}
+
+// Sign builds an HS256 JWT for the provided user and grant, with a fresh jti,
+// and returns it with its claims.
+func (s *ChatTokenSigner) Sign(userID, email string, grant ChatGrant) (string, *ChatClaims, error) {
 	if userID == "" {
-		return "", errors.New("user id is required")
+		return "", nil, errors.New("user id is required")
 	}
-	if len(permissions) == 0 {
-		return "", errors.New("at least one permission is required")
+	if len(grant.Permissions) == 0 {
+		return "", nil, errors.New("at least one permission is required")
 	}
 
-	now := time.Now().UTC()
-	claims := ChatClaims{
+	now := s.now().UTC()
+	claims := &ChatClaims{
+		ID:          uuid.NewString(),
 		Issuer:      s.cfg.Issuer,
 		Audience:    s.cfg.Audience,
 		Subject:     userID,
 		Email:       email,
-		Permissions: permissions,
-		IssuedAt:    now,
-		ExpiresAt:   now.Add(s.cfg.TokenTTL),
+		Role:        grant.Role,
+		Channel:     grant.Channel,
+		Permissions: grant.Permissions,
+		IssuedAt:    now.Unix(),
+		ExpiresAt:   now.Add(s.cfg.TokenTTL).Unix(),
 	}
 
 	header := map[string]string{
 		"alg": "HS256",
 		"typ": "JWT",
+		"kid": s.active,
 	}
 
 	headerJSON, err := json.Marshal(header)
 	if err != nil {
-		return "", fmt.Errorf("marshal header: %w", err)
+		return "", nil, fmt.Errorf("marshal header: %w", err)
 	}
 	claimsJSON, err := json.Marshal(claims)
 	if err != nil {
-		return "", fmt.Errorf("marshal claims: %w", err)
+		return "", nil, fmt.Errorf("marshal claims: %w", err)
 	}
 
 	headerEnc := base64.RawURLEncoding.EncodeToString(headerJSON)
 	claimsEnc := base64.RawURLEncoding.EncodeToString(claimsJSON)
 	unsigned := headerEnc + "." + claimsEnc
 
-	mac := hmac.New(sha256.New, []byte(s.cfg.SigningKey))
+	mac := hmac.New(sha256.New, s.keys[s.active])
 	mac.Write([]byte(unsigned))
-	signature := mac.Sum(nil)
-	sigEnc := base64.RawURLEncoding.EncodeToString(signature)
+	sigEnc := base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+
+	return unsigned + "." + sigEnc, claims, nil
+}
+
+// Verify checks the token's signature, issuer, audience and expiry and
+// returns its claims. It does not consult the revocation list.
+func (s *ChatTokenSigner) Verify(token string) (*ChatClaims, error) {
+	return s.verify(token, s.cfg.Leeway)
+}
 
-	return unsigned + "." + sigEnc, nil
+// VerifyExpired is like Verify but accepts tokens up to grace past expiry,
+// for refreshing them.
+func (s *ChatTokenSigner) VerifyExpired(token string, grace time.Duration) (*ChatClaims, error) {
+	return s.verify(token, max(grace, s.cfg.Leeway))
+}
+
+func (s *ChatTokenSigner) verify(token string, leeway time.Duration) (*ChatClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed", ErrInvalidToken)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrInvalidToken, err)
+	}
+	if header.Alg != "HS256" {
+		return nil, fmt.Errorf("%w: unexpected algorithm %q", ErrInvalidToken, header.Alg)
+	}
+	kid := header.Kid
+	if kid == "" {
+		// Tokens issued before key rotation carry no kid.
+		kid = defaultChatKeyID
+	}
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidToken, header.Kid)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: signature encoding", ErrInvalidToken)
+	}
+	if err := verifySignature(header.Alg, key, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	var claims ChatClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: claims: %v", ErrInvalidToken, err)
+	}
+	switch {
+	case claims.ID == "":
+		return nil, fmt.Errorf("%w: missing jti", ErrInvalidToken)
+	case claims.Subject == "":
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidToken)
+	case s.now().After(claims.Expiry().Add(leeway)):
+		return nil, fmt.Errorf("%w: token expired", ErrInvalidToken)
+	case s.cfg.Issuer != "" && claims.Issuer != s.cfg.Issuer:
+		return nil, fmt.Errorf("%w: unexpected issuer %q", ErrInvalidToken, claims.Issuer)
+	case s.cfg.Audience != "" && claims.Audience != s.cfg.Audience:
+		return nil, fmt.Errorf("%w: audience mismatch", ErrInvalidToken)
+	}
+	return &claims, nil
 }
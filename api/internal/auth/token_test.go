@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"chaseapp.tv/api/internal/config"
+)
+
+func TestChatTokenSignVerify(t *testing.T) {
+	signer, err := NewChatTokenSigner(config.ChatConfig{
+		SigningKeys: []config.ChatSigningKey{{ID: "2026-10", Secret: "new"}, {ID: "2026-04", Secret: "old"}},
+		Issuer:      "chaseapp",
+		Audience:    "chat",
+		TokenTTL:    time.Minute,
+	})
+	require.NoError(t, err)
+
+	token, claims, err := signer.Sign("user-1", "a@example.com", ChatGrant{Role: "viewer", Channel: "c1", Permissions: []string{"chat:read"}})
+	require.NoError(t, err)
+	require.NotEmpty(t, claims.ID)
+
+	header, err := base64.RawURLEncoding.DecodeString(strings.Split(token, ".")[0])
+	require.NoError(t, err)
+	var h map[string]string
+	require.NoError(t, json.Unmarshal(header, &h))
+	require.Equal(t, "2026-10", h["kid"])
+
+	got, err := signer.Verify(token)
+	require.NoError(t, err)
+	require.Equal(t, claims.ID, got.ID)
+	require.Equal(t, "c1", got.Channel)
+
+	// Tampered claims fail.
+	parts := strings.Split(token, ".")
+	_, err = signer.Verify(parts[0] + "." + parts[1] + "x." + parts[2])
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	// After expiry the token only verifies within the refresh grace.
+	signer.now = func() time.Time { return time.Now().Add(3 * time.Minute) }
+	_, err = signer.Verify(token)
+	require.ErrorIs(t, err, ErrInvalidToken)
+	_, err = signer.VerifyExpired(token, 5*time.Minute)
+	require.NoError(t, err)
+}
+
+func TestChatTokenKeyRotation(t *testing.T) {
+	old, err := NewChatTokenSigner(config.ChatConfig{SigningKey: "legacy", TokenTTL: time.Minute})
+	require.NoError(t, err)
+	token, _, err := old.Sign("user-1", "", ChatGrant{Permissions: []string{"chat:read"}})
+	require.NoError(t, err)
+
+	rotated, err := NewChatTokenSigner(config.ChatConfig{
+		SigningKeys: []config.ChatSigningKey{{ID: "2026-10", Secret: "new"}, {ID: "default", Secret: "legacy"}},
+		TokenTTL:    time.Minute,
+	})
+	require.NoError(t, err)
+	_, err = rotated.Verify(token)
+	require.NoError(t, err)
+
+	retired, err := NewChatTokenSigner(config.ChatConfig{
+		SigningKeys: []config.ChatSigningKey{{ID: "2026-10", Secret: "new"}},
+		TokenTTL:    time.Minute,
+	})
+	require.NoError(t, err)
+	_, err = retired.Verify(token)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	_, err = NewChatTokenSigner(config.ChatConfig{
+		SigningKeys: []config.ChatSigningKey{{ID: "2026-10", Secret: "new"}},
+		ActiveKeyID: "missing",
+	})
+	require.Error(t, err)
+}
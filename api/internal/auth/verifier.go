@@ -0,0 +1,269 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"chaseapp.tv/api/internal/config"
+)
+
+// ErrInvalidToken is returned for tokens that fail verification.
+var ErrInvalidToken = errors.New("invalid token")
+
+// Audience is the JWT aud claim, which may be a string or an array.
+type Audience []string
+
+// UnmarshalJSON accepts both forms of the aud claim.
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+// Claims are the registered and identity claims read from an access token.
+type Claims struct {
+	Issuer    string   `json:"iss"`
+	Subject   string   `json:"sub"`
+	Audience  Audience `json:"aud"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf"`
+	IssuedAt  int64    `json:"iat"`
+	Email     string   `json:"email"`
+}
+
+// Verifier validates bearer JWTs against a JWKS, a static public key or an
+// HMAC secret, and checks issuer, audience and lifetime.
+type Verifier struct {
+	jwks      *JWKS
+	publicKey crypto.PublicKey
+	secret    []byte
+	issuer    string
+	audience  string
+	leeway    time.Duration
+	now       func() time.Time
+}
+
+// NewVerifier creates a Verifier from cfg. At least one key source must be
+// configured.
+func NewVerifier(cfg config.AuthConfig) (*Verifier, error) {
+	v := &Verifier{
+		issuer:   cfg.JWTIssuer,
+		audience: cfg.JWTAudience,
+		leeway:   cfg.JWTLeeway,
+		now:      time.Now,
+	}
+
+	if cfg.JWKSURL != "" {
+		v.jwks = NewJWKS(cfg.JWKSURL, cfg.JWKSRefresh)
+	}
+	if cfg.JWTPublicKeyPath != "" {
+		key, err := loadPublicKey(cfg.JWTPublicKeyPath)
+		if err != nil {
+			return nil, err
+		}
+		v.publicKey = key
+	}
+	if cfg.JWTSecret != "" {
+		v.secret = []byte(cfg.JWTSecret)
+	}
+
+	if v.jwks == nil && v.publicKey == nil && v.secret == nil {
+		return nil, errors.New("jwt verification requires AUTH_JWKS_URL, AUTH_JWT_PUBLIC_KEY_PATH or AUTH_JWT_SECRET")
+	}
+	return v, nil
+}
+
+// Verify checks the token's signature and claims and returns its claims.
+func (v *Verifier) Verify(ctx context.Context, token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed", ErrInvalidToken)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrInvalidToken, err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: signature encoding", ErrInvalidToken)
+	}
+
+	key, err := v.key(ctx, header.Alg, header.Kid)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if err := verifySignature(header.Alg, key, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: claims: %v", ErrInvalidToken, err)
+	}
+	if err := v.validate(&claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return &claims, nil
+}
+
+// key selects the verification key for alg. The algorithm family must match
+// the key type, so an RSA public key can never be used as an HMAC secret.
+func (v *Verifier) key(ctx context.Context, alg, kid string) (any, error) {
+	switch {
+	case strings.HasPrefix(alg, "HS"):
+		if v.secret == nil {
+			return nil, fmt.Errorf("unexpected algorithm %s", alg)
+		}
+		return v.secret, nil
+	case strings.HasPrefix(alg, "RS"), strings.HasPrefix(alg, "ES"):
+		if v.jwks != nil {
+			key, err := v.jwks.Key(ctx, kid)
+			if err == nil || v.publicKey == nil {
+				return key, err
+			}
+		}
+		if v.publicKey == nil {
+			return nil, fmt.Errorf("unexpected algorithm %s", alg)
+		}
+		return v.publicKey, nil
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", alg)
+	}
+}
+
+func (v *Verifier) validate(c *Claims) error {
+	now := v.now()
+	if c.Subject == "" {
+		return errors.New("missing subject")
+	}
+	if c.ExpiresAt == 0 {
+		return errors.New("missing expiry")
+	}
+	if now.After(time.Unix(c.ExpiresAt, 0).Add(v.leeway)) {
+		return errors.New("token expired")
+	}
+	if c.NotBefore != 0 && now.Add(v.leeway).Before(time.Unix(c.NotBefore, 0)) {
+		return errors.New("token not yet valid")
+	}
+	if v.issuer != "" && c.Issuer != v.issuer {
+		return fmt.Errorf("unexpected issuer %q", c.Issuer)
+	}
+	if v.audience != "" && !slices.Contains(c.Audience, v.audience) {
+		return errors.New("audience mismatch")
+	}
+	return nil
+}
+
+func verifySignature(alg string, key any, signed string, signature []byte) error {
+	var h func() hash.Hash
+	var hashID crypto.Hash
+	switch alg[2:] {
+	case "256":
+		h, hashID = sha256.New, crypto.SHA256
+	case "384":
+		h, hashID = sha512.New384, crypto.SHA384
+	case "512":
+		h, hashID = sha512.New, crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(h, k)
+		mac.Write([]byte(signed))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return errors.New("signature mismatch")
+		}
+		return nil
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(alg, "RS") {
+			return fmt.Errorf("algorithm %s does not match rsa key", alg)
+		}
+		digest := h()
+		digest.Write([]byte(signed))
+		if err := rsa.VerifyPKCS1v15(k, hashID, digest.Sum(nil), signature); err != nil {
+			return errors.New("signature mismatch")
+		}
+		return nil
+	case *ecdsa.PublicKey:
+		if !strings.HasPrefix(alg, "ES") {
+			return fmt.Errorf("algorithm %s does not match ecdsa key", alg)
+		}
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return errors.New("signature mismatch")
+		}
+		digest := h()
+		digest.Write([]byte(signed))
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(k, digest.Sum(nil), r, s) {
+			return errors.New("signature mismatch")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported key type %T", key)
+	}
+}
+
+func decodeSegment(seg string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// loadPublicKey reads a PEM public key or certificate.
+func loadPublicKey(path string) (crypto.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read jwt public key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("jwt public key is not PEM encoded")
+	}
+	if block.Type == "CERTIFICATE" {
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parse jwt certificate: %w", err)
+		}
+		return cert.PublicKey, nil
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse jwt public key: %w", err)
+	}
+	return key, nil
+}
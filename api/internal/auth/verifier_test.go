@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"chaseapp.tv/api/internal/config"
+)
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]any) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": kid})
+	body, _ := json.Marshal(claims)
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(body)
+	digest := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func rsaJWK(kid string, key *rsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func TestVerifierJWKSRotation(t *testing.T) {
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var rotated atomic.Bool
+	var fetches atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		keys := []map[string]string{rsaJWK("old", &oldKey.PublicKey)}
+		if rotated.Load() {
+			keys = []map[string]string{rsaJWK("new", &newKey.PublicKey)}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"keys": keys})
+	}))
+	defer srv.Close()
+
+	v, err := NewVerifier(config.AuthConfig{
+		JWKSURL:     srv.URL,
+		JWTIssuer:   "https://auth.chaseapp.tv/",
+		JWTAudience: "chaseapp-api",
+	})
+	require.NoError(t, err)
+
+	claims := map[string]any{
+		"iss":   "https://auth.chaseapp.tv/",
+		"aud":   []string{"chaseapp-api", "other"},
+		"sub":   "8b0c3f3e-8f5e-4a55-9d4e-8a7c1e2f7a10",
+		"email": "pilot@chaseapp.tv",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}
+
+	got, err := v.Verify(context.Background(), signRS256(t, oldKey, "old", claims))
+	require.NoError(t, err)
+	require.Equal(t, "pilot@chaseapp.tv", got.Email)
+
+	// A token signed with a rotated-in key triggers a refetch, which is
+	// rate-limited; pretend the last fetch is old enough.
+	rotated.Store(true)
+	v.jwks.fetchedAt = time.Now().Add(-2 * jwksMinRefetch)
+	_, err = v.Verify(context.Background(), signRS256(t, newKey, "new", claims))
+	require.NoError(t, err)
+	require.EqualValues(t, 2, fetches.Load())
+
+	claims["aud"] = "someone-else"
+	_, err = v.Verify(context.Background(), signRS256(t, newKey, "new", claims))
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	claims["aud"] = "chaseapp-api"
+	claims["exp"] = time.Now().Add(-time.Hour).Unix()
+	_, err = v.Verify(context.Background(), signRS256(t, newKey, "new", claims))
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestJWKSBacksOffAfterFailure(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var down atomic.Bool
+	var fetches atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		if down.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{rsaJWK("old", &key.PublicKey)}})
+	}))
+	defer srv.Close()
+
+	jwks := NewJWKS(srv.URL, time.Hour)
+	ctx := context.Background()
+	_, err = jwks.Key(ctx, "old")
+	require.NoError(t, err)
+
+	// An unknown key ID refetches; the provider is down, so further
+	// requests wait out the backoff instead of refetching.
+	down.Store(true)
+	jwks.fetchedAt = time.Now().Add(-2 * jwksMinRefetch)
+	_, err = jwks.Key(ctx, "new")
+	require.Error(t, err)
+	_, err = jwks.Key(ctx, "new")
+	require.Error(t, err)
+	require.EqualValues(t, 2, fetches.Load())
+
+	// Cached keys are still served, even once stale.
+	jwks.fetchedAt = time.Now().Add(-2 * time.Hour)
+	got, err := jwks.Key(ctx, "old")
+	require.NoError(t, err)
+	require.Equal(t, &key.PublicKey, got)
+	require.EqualValues(t, 2, fetches.Load())
+}
+
+func TestVerifierRejectsAlgorithmMismatch(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	v := &Verifier{publicKey: &key.PublicKey, now: time.Now}
+
+	header, _ := json.Marshal(map[string]string{"alg": "none"})
+	body, _ := json.Marshal(map[string]any{"sub": "u", "exp": time.Now().Add(time.Hour).Unix()})
+	token := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(body) + "."
+	_, err = v.Verify(context.Background(), token)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	// HS256 must not fall back to using the public key as an HMAC secret.
+	header, _ = json.Marshal(map[string]string{"alg": "HS256"})
+	token = base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(body) + ".c2ln"
+	_, err = v.Verify(context.Background(), token)
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
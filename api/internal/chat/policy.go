@@ -0,0 +1,129 @@
+// Package chat decides what chat access users are granted.
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+
+	"chaseapp.tv/api/internal/auth"
+	"chaseapp.tv/api/internal/model"
+)
+
+// ErrBanned is returned when the user is banned from the channel.
+var ErrBanned = errors.New("user is banned from chat")
+
+// Store loads users' chat roles and sanctions.
+type Store interface {
+	GetRole(ctx context.Context, userID uuid.UUID) (model.ChatRole, error)
+	ActiveModeration(ctx context.Context, userID uuid.UUID, channelID *uuid.UUID) ([]model.ChatModerationAction, error)
+}
+
+// SiteRoles loads the site roles granted to a user.
+type SiteRoles interface {
+	GetRoles(ctx context.Context, userID uuid.UUID) ([]model.Role, error)
+}
+
+// Policy derives chat token grants from chat roles and moderation state.
+type Policy struct {
+	repo   Store
+	users  SiteRoles
+	admins map[string]bool
+}
+
+// NewPolicy creates a Policy. Users in adminIDs are site admins, as they are
+// for middleware.Roles.
+func NewPolicy(repo Store, users SiteRoles, adminIDs []string) *Policy {
+	admins := make(map[string]bool, len(adminIDs))
+	for _, id := range adminIDs {
+		admins[id] = true
+	}
+	return &Policy{repo: repo, users: users, admins: admins}
+}
+
+// Role returns the user's effective chat role: their stored chat role,
+// raised to moderator or admin for site moderators and admins.
+func (p *Policy) Role(ctx context.Context, userID uuid.UUID, siteRoles []model.Role) (model.ChatRole, error) {
+	role, err := p.repo.GetRole(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	return effectiveRole(role, siteRoles), nil
+}
+
+// UserRole returns the effective chat role of a user other than the one
+// making the request, loading their site roles.
+func (p *Policy) UserRole(ctx context.Context, userID uuid.UUID) (model.ChatRole, error) {
+	siteRoles, err := p.users.GetRoles(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if p.admins[userID.String()] && !slices.Contains(siteRoles, model.RoleAdmin) {
+		siteRoles = append(siteRoles, model.RoleAdmin)
+	}
+	return p.Role(ctx, userID, siteRoles)
+}
+
+// Grant returns what the user may do in channel, or in chat generally when
+// channel is nil. Requested narrows the permissions when non-empty. A token
+// without a channel is valid in every channel, so it is subject to every
+// sanction: a ban from any channel refuses it and a mute in any channel
+// withholds chat:write.
+func (p *Policy) Grant(ctx context.Context, userID uuid.UUID, siteRoles []model.Role, channel *uuid.UUID, requested []string) (auth.ChatGrant, error) {
+	role, err := p.Role(ctx, userID, siteRoles)
+	if err != nil {
+		return auth.ChatGrant{}, err
+	}
+
+	sanctions, err := p.repo.ActiveModeration(ctx, userID, channel)
+	if err != nil {
+		return auth.ChatGrant{}, fmt.Errorf("load chat sanctions: %w", err)
+	}
+
+	grant := auth.ChatGrant{Role: string(role)}
+	if channel != nil {
+		grant.Channel = channel.String()
+	}
+	grant.Permissions = permissions(role, sanctions, requested)
+	for _, s := range sanctions {
+		if s.Action == model.ChatSanctionBan {
+			return auth.ChatGrant{}, ErrBanned
+		}
+	}
+	return grant, nil
+}
+
+func effectiveRole(role model.ChatRole, siteRoles []model.Role) model.ChatRole {
+	if slices.Contains(siteRoles, model.RoleAdmin) && role.Rank() < model.ChatRoleAdmin.Rank() {
+		return model.ChatRoleAdmin
+	}
+	if slices.Contains(siteRoles, model.RoleModerator) && role.Rank() < model.ChatRoleModerator.Rank() {
+		return model.ChatRoleModerator
+	}
+	if !role.Valid() {
+		return model.ChatRoleViewer
+	}
+	return role
+}
+
+// permissions applies mutes and the requested subset to the role's permissions.
+func permissions(role model.ChatRole, sanctions []model.ChatModerationAction, requested []string) []string {
+	muted := slices.ContainsFunc(sanctions, func(s model.ChatModerationAction) bool {
+		return s.Action == model.ChatSanctionMute
+	})
+
+	var perms []string
+	for _, perm := range role.Permissions() {
+		if muted && perm == model.ChatPermWrite {
+			continue
+		}
+		if len(requested) > 0 && !slices.Contains(requested, perm) {
+			continue
+		}
+		perms = append(perms, perm)
+	}
+	return perms
+}
@@ -0,0 +1,79 @@
+package chat
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"chaseapp.tv/api/internal/model"
+)
+
+// fakeStore holds sanctions and filters them by channel as
+// ChatRepository.ActiveModeration does.
+type fakeStore struct {
+	sanctions []model.ChatModerationAction
+}
+
+func (s *fakeStore) GetRole(context.Context, uuid.UUID) (model.ChatRole, error) {
+	return model.ChatRoleViewer, nil
+}
+
+func (s *fakeStore) ActiveModeration(_ context.Context, userID uuid.UUID, channelID *uuid.UUID) ([]model.ChatModerationAction, error) {
+	var out []model.ChatModerationAction
+	for _, a := range s.sanctions {
+		if a.UserID != userID {
+			continue
+		}
+		if channelID == nil || a.ChannelID == nil || *a.ChannelID == *channelID {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+func TestEffectiveRole(t *testing.T) {
+	require.Equal(t, model.ChatRoleViewer, effectiveRole(model.ChatRoleViewer, []model.Role{model.RoleUser}))
+	require.Equal(t, model.ChatRoleModerator, effectiveRole(model.ChatRoleViewer, []model.Role{model.RoleModerator}))
+	require.Equal(t, model.ChatRoleAdmin, effectiveRole(model.ChatRoleModerator, []model.Role{model.RoleAdmin}))
+	require.Equal(t, model.ChatRoleAdmin, effectiveRole(model.ChatRoleAdmin, []model.Role{model.RoleModerator}))
+	require.Equal(t, model.ChatRoleViewer, effectiveRole("", nil))
+}
+
+func TestPermissions(t *testing.T) {
+	mute := []model.ChatModerationAction{{Action: model.ChatSanctionMute}}
+
+	require.Equal(t, []string{model.ChatPermRead, model.ChatPermWrite}, permissions(model.ChatRoleViewer, nil, nil))
+	require.Equal(t, []string{model.ChatPermRead}, permissions(model.ChatRoleViewer, mute, nil))
+	require.Equal(t, []string{model.ChatPermRead}, permissions(model.ChatRoleModerator, nil, []string{model.ChatPermRead, "chat:unknown"}))
+	require.Contains(t, permissions(model.ChatRoleModerator, mute, nil), model.ChatPermModerate)
+	require.NotContains(t, permissions(model.ChatRoleModerator, nil, nil), model.ChatPermAdmin)
+}
+
+func TestGrantUnscopedHonoursChannelSanctions(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	banned, muted, other := uuid.New(), uuid.New(), uuid.New()
+	store := &fakeStore{}
+	policy := NewPolicy(store, nil, nil)
+
+	// Banned in one channel: unscoped tokens would be valid there too.
+	store.sanctions = []model.ChatModerationAction{{UserID: userID, ChannelID: &banned, Action: model.ChatSanctionBan}}
+	_, err := policy.Grant(ctx, userID, nil, &banned, nil)
+	require.ErrorIs(t, err, ErrBanned)
+	_, err = policy.Grant(ctx, userID, nil, nil, nil)
+	require.ErrorIs(t, err, ErrBanned)
+	grant, err := policy.Grant(ctx, userID, nil, &other, nil)
+	require.NoError(t, err)
+	require.Contains(t, grant.Permissions, model.ChatPermWrite)
+
+	// Muted in one channel: unscoped tokens cannot write.
+	store.sanctions = []model.ChatModerationAction{{UserID: userID, ChannelID: &muted, Action: model.ChatSanctionMute}}
+	grant, err = policy.Grant(ctx, userID, nil, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{model.ChatPermRead}, grant.Permissions)
+	grant, err = policy.Grant(ctx, userID, nil, &other, nil)
+	require.NoError(t, err)
+	require.Contains(t, grant.Permissions, model.ChatPermWrite)
+}
@@ -0,0 +1,99 @@
+package chat
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"chaseapp.tv/api/internal/model"
+)
+
+// revocationSyncOverlap is how far before the latest loaded revoked_at each
+// sync re-reads. revoked_at is the revoking transaction's start time, so a
+// revocation can commit after a later one has been loaded; re-reading
+// revocations already cached is harmless.
+const revocationSyncOverlap = time.Minute
+
+// revocationSource loads revoked chat tokens.
+type revocationSource interface {
+	RevokedTokens(ctx context.Context, since, expiresAfter time.Time) ([]model.ChatToken, error)
+}
+
+// RevocationList is an in-memory cache of revoked chat token IDs, kept
+// until retain after each token expires so expired tokens in their refresh
+// grace period stay revoked. Tokens revoked by this process are added
+// immediately; those revoked by other replicas appear on the next Sync.
+type RevocationList struct {
+	source  revocationSource
+	retain  time.Duration
+	mu      sync.RWMutex
+	revoked map[string]time.Time // jti -> token expiry
+	synced  time.Time            // latest revoked_at loaded
+	now     func() time.Time
+}
+
+// NewRevocationList creates an empty RevocationList.
+func NewRevocationList(source revocationSource, retain time.Duration) *RevocationList {
+	return &RevocationList{
+		source:  source,
+		retain:  retain,
+		revoked: make(map[string]time.Time),
+		now:     time.Now,
+	}
+}
+
+// Revoked reports whether the token ID is revoked.
+func (l *RevocationList) Revoked(jti string) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	_, ok := l.revoked[jti]
+	return ok
+}
+
+// Add marks a token revoked until it expires.
+func (l *RevocationList) Add(jti string, expires time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.revoked[jti] = expires
+}
+
+// Len returns the number of cached revocations.
+func (l *RevocationList) Len() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return len(l.revoked)
+}
+
+// Sync loads revocations recorded since the last sync, less
+// revocationSyncOverlap, and drops expired entries. The cursor follows the
+// database's revoked_at so clock skew between replicas cannot skip a
+// revocation.
+func (l *RevocationList) Sync(ctx context.Context) error {
+	l.mu.RLock()
+	since := l.synced
+	l.mu.RUnlock()
+	if !since.IsZero() {
+		since = since.Add(-revocationSyncOverlap)
+	}
+
+	cutoff := l.now().Add(-l.retain)
+	tokens, err := l.source.RevokedTokens(ctx, since, cutoff)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, t := range tokens {
+		l.revoked[t.ID.String()] = t.ExpiresAt
+		if t.RevokedAt != nil && t.RevokedAt.After(l.synced) {
+			l.synced = *t.RevokedAt
+		}
+	}
+	for jti, expires := range l.revoked {
+		if expires.Before(cutoff) {
+			delete(l.revoked, jti)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package chat
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"chaseapp.tv/api/internal/model"
+)
+
+type fakeRevocations struct {
+	tokens []model.ChatToken
+	since  []time.Time
+}
+
+func (f *fakeRevocations) RevokedTokens(_ context.Context, since, _ time.Time) ([]model.ChatToken, error) {
+	f.since = append(f.since, since)
+	var out []model.ChatToken
+	for _, t := range f.tokens {
+		if t.RevokedAt.After(since) {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+func TestRevocationListSync(t *testing.T) {
+	now := time.Now()
+	revokedAt := now.Add(-time.Minute)
+	live := model.ChatToken{ID: uuid.New(), ExpiresAt: now.Add(time.Hour), RevokedAt: &revokedAt}
+	inGrace := model.ChatToken{ID: uuid.New(), ExpiresAt: now.Add(-time.Minute), RevokedAt: &revokedAt}
+	source := &fakeRevocations{tokens: []model.ChatToken{live, inGrace}}
+
+	list := NewRevocationList(source, 5*time.Minute)
+	require.NoError(t, list.Sync(context.Background()))
+	require.True(t, list.Revoked(live.ID.String()))
+	require.True(t, list.Revoked(inGrace.ID.String()))
+
+	// The next sync resumes shortly before the latest revoked_at, picking
+	// up a revocation that started earlier but committed after the sync.
+	lateAt := revokedAt.Add(-10 * time.Second)
+	late := model.ChatToken{ID: uuid.New(), ExpiresAt: now.Add(time.Hour), RevokedAt: &lateAt}
+	source.tokens = append(source.tokens, late)
+	require.NoError(t, list.Sync(context.Background()))
+	require.Equal(t, revokedAt.Add(-revocationSyncOverlap), source.since[1])
+	require.True(t, list.Revoked(late.ID.String()))
+
+	list.Add("local", now.Add(time.Hour))
+	require.True(t, list.Revoked("local"))
+
+	// Entries are dropped once past expiry plus retention.
+	list.now = func() time.Time { return now.Add(2 * time.Hour) }
+	require.NoError(t, list.Sync(context.Background()))
+	require.Zero(t, list.Len())
+}
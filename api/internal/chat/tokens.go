@@ -0,0 +1,169 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"chaseapp.tv/api/internal/auth"
+	"chaseapp.tv/api/internal/config"
+	"chaseapp.tv/api/internal/model"
+	"chaseapp.tv/api/internal/repository"
+)
+
+// ErrTokenRevoked is returned for chat tokens on the revocation list.
+var ErrTokenRevoked = fmt.Errorf("%w: revoked", auth.ErrInvalidToken)
+
+// tokenCleanupInterval is how often expired tokens are deleted.
+const tokenCleanupInterval = time.Hour
+
+// Tokens issues, verifies and revokes chat tokens. It is the single place
+// both the API and the websocket gateway validate chat tokens.
+type Tokens struct {
+	signer  *auth.ChatTokenSigner
+	repo    *repository.ChatRepository
+	revoked *RevocationList
+	grace   time.Duration
+	retain  time.Duration
+	sync    time.Duration
+	logger  *slog.Logger
+}
+
+// NewTokens creates a Tokens service.
+func NewTokens(signer *auth.ChatTokenSigner, repo *repository.ChatRepository, cfg config.ChatConfig, logger *slog.Logger) *Tokens {
+	if cfg.RevocationSync <= 0 {
+		cfg.RevocationSync = 15 * time.Second
+	}
+	// Revocations must outlive expiry for as long as a token still verifies.
+	retain := max(cfg.RefreshGrace, cfg.Leeway)
+	return &Tokens{
+		signer:  signer,
+		repo:    repo,
+		revoked: NewRevocationList(repo, retain),
+		grace:   cfg.RefreshGrace,
+		retain:  retain,
+		sync:    cfg.RevocationSync,
+		logger:  logger,
+	}
+}
+
+// Issue signs a token for the grant and records its jti.
+func (t *Tokens) Issue(ctx context.Context, userID uuid.UUID, email string, grant auth.ChatGrant) (string, *auth.ChatClaims, error) {
+	token, claims, err := t.signer.Sign(userID.String(), email, grant)
+	if err != nil {
+		return "", nil, err
+	}
+
+	record := &model.ChatToken{
+		ID:        uuid.MustParse(claims.ID),
+		UserID:    userID,
+		IssuedAt:  time.Unix(claims.IssuedAt, 0),
+		ExpiresAt: claims.Expiry(),
+	}
+	if grant.Channel != "" {
+		channelID, err := uuid.Parse(grant.Channel)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid channel %q: %w", grant.Channel, err)
+		}
+		record.ChannelID = &channelID
+	}
+	if err := t.repo.RecordToken(ctx, record); err != nil {
+		return "", nil, err
+	}
+	return token, claims, nil
+}
+
+// Verify validates a token and checks it has not been revoked.
+func (t *Tokens) Verify(ctx context.Context, token string) (*auth.ChatClaims, error) {
+	claims, err := t.signer.Verify(token)
+	if err != nil {
+		return nil, err
+	}
+	return t.checkRevoked(claims)
+}
+
+// VerifyRefresh is like Verify but accepts tokens within the refresh grace
+// period after expiry.
+func (t *Tokens) VerifyRefresh(ctx context.Context, token string) (*auth.ChatClaims, error) {
+	claims, err := t.signer.VerifyExpired(token, t.grace)
+	if err != nil {
+		return nil, err
+	}
+	return t.checkRevoked(claims)
+}
+
+func (t *Tokens) checkRevoked(claims *auth.ChatClaims) (*auth.ChatClaims, error) {
+	if t.revoked.Revoked(claims.ID) {
+		return nil, ErrTokenRevoked
+	}
+	return claims, nil
+}
+
+// Revoke adds the token to the revocation list.
+func (t *Tokens) Revoke(ctx context.Context, claims *auth.ChatClaims, reason string) error {
+	jti, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return fmt.Errorf("invalid jti %q: %w", claims.ID, err)
+	}
+	if _, err := t.repo.RevokeToken(ctx, jti, reason); err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	t.revoked.Add(claims.ID, claims.Expiry())
+	return nil
+}
+
+// RevokeUser revokes the user's live tokens for the channel, or all of them
+// when channelID is nil, and returns how many were revoked.
+func (t *Tokens) RevokeUser(ctx context.Context, userID uuid.UUID, channelID *uuid.UUID, reason string) (int, error) {
+	tokens, err := t.repo.RevokeUserTokens(ctx, userID, channelID, reason, time.Now().Add(-t.retain))
+	if err != nil {
+		return 0, err
+	}
+	for _, tok := range tokens {
+		t.revoked.Add(tok.ID.String(), tok.ExpiresAt)
+	}
+	return len(tokens), nil
+}
+
+// Revocations returns tokens revoked after since that may still verify, for
+// services that keep their own copy of the list.
+func (t *Tokens) Revocations(ctx context.Context, since time.Time) ([]model.ChatToken, error) {
+	return t.repo.RevokedTokens(ctx, since, time.Now().Add(-t.retain))
+}
+
+// Run keeps the revocation list in sync and deletes expired tokens until
+// the context is cancelled.
+func (t *Tokens) Run(ctx context.Context) {
+	if err := t.revoked.Sync(ctx); err != nil {
+		t.logger.Warn("chat revocation sync failed", slog.Any("error", err))
+	}
+
+	syncTicker := time.NewTicker(t.sync)
+	defer syncTicker.Stop()
+	cleanupTicker := time.NewTicker(tokenCleanupInterval)
+	defer cleanupTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-syncTicker.C:
+			if err := t.revoked.Sync(ctx); err != nil {
+				t.logger.Warn("chat revocation sync failed", slog.Any("error", err))
+			}
+		case <-cleanupTicker.C:
+			deleted, err := t.repo.DeleteExpiredTokens(ctx, time.Now().Add(-t.retain))
+			if err != nil {
+				t.logger.Warn("chat token cleanup failed", slog.Any("error", err))
+				continue
+			}
+			if deleted > 0 {
+				t.logger.Info("deleted expired chat tokens", slog.Int64("count", deleted))
+			}
+		}
+	}
+}
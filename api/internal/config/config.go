@@ -3,8 +3,10 @@ package config
 
 import (
 	"fmt"
+	"net/netip"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -15,7 +17,10 @@ type Config struct {
 	NATS          NATSConfig
 	Search        SearchConfig
 	Push          PushConfig
+	Auth          AuthConfig
 	Chat          ChatConfig
+	ADSB          ADSBConfig
+	BoF           BoFConfig
 	External      ExternalConfig
 	Observability ObservabilityConfig
 }
@@ -27,6 +32,14 @@ type ServerConfig struct {
 	ReadTimeout     time.Duration
 	WriteTimeout    time.Duration
 	ShutdownTimeout time.Duration
+	// SSEHeartbeat is the interval between keep-alive comments on event
+	// streams.
+	SSEHeartbeat time.Duration
+	// WSThrottle is the minimum interval between aircraft batches on a
+	// WebSocket connection.
+	WSThrottle time.Duration
+	// WSStaleAfter drops queued aircraft positions older than this.
+	WSStaleAfter time.Duration
 }
 
 // DatabaseConfig holds PostgreSQL configuration.
@@ -81,18 +94,126 @@ type PushConfig struct {
 	APNsTeamID   string
 	APNsKeyPath  string
 	APNsBundleID string
+	APNsSandbox  bool
 	FCMProjectID string
 	FCMKeyPath   string
+	FCMTokenURL  string
+	FCMBaseURL   string
 	SafariPushID string
 	SafariWebURL string
+
+	// SafariWebsiteURL is the site allowed to request permission and the
+	// base for notification links. Defaults to SafariWebURL.
+	SafariWebsiteURL string
+	// SafariCertPath is the website push certificate, as a .p12 bundle or a
+	// PEM file holding the certificate and private key.
+	SafariCertPath     string
+	SafariCertPassword string
+	// SafariIntermediatePath is the Apple WWDR intermediate certificate (PEM).
+	SafariIntermediatePath string
+	// SafariIconDir holds the icon.iconset PNGs bundled into the package.
+	SafariIconDir string
+	// SafariAuthSecret signs per-user push package authentication tokens.
+	SafariAuthSecret string
+
+	// VAPIDPrivateKey is the base64url P-256 private key used for Web Push.
+	VAPIDPrivateKey string
+	// VAPIDSubject is the mailto: or https: contact sent in VAPID tokens.
+	VAPIDSubject string
+
+	// DeliveryConcurrency bounds in-flight provider requests per dispatch.
+	DeliveryConcurrency int
+	// DeliveryMaxRetries is the number of retries for transient failures.
+	DeliveryMaxRetries int
+	// DeliveryRetryBackoff is the base delay for exponential retry backoff.
+	DeliveryRetryBackoff time.Duration
+}
+
+// AuthConfig holds authentication and authorization configuration.
+type AuthConfig struct {
+	// Mode selects how requests are authenticated: "header" trusts the
+	// X-User-ID/X-User-Email headers set by Kong, "jwt" verifies bearer
+	// tokens in-process, and "both" requires the two to agree.
+	Mode string
+
+	// JWKSURL publishes the token signing keys; refreshed every JWKSRefresh
+	// and on unknown key IDs.
+	JWKSURL     string
+	JWKSRefresh time.Duration
+	// JWTPublicKeyPath is a static PEM public key or certificate.
+	JWTPublicKeyPath string
+	// JWTSecret verifies HS256 tokens.
+	JWTSecret   string
+	JWTIssuer   string
+	JWTAudience string
+	// JWTLeeway is the clock skew allowed on exp and nbf.
+	JWTLeeway time.Duration
+
+	// AdminUserIDs are always treated as admins, to bootstrap role grants.
+	AdminUserIDs []string
 }
 
 // ChatConfig holds chat token signing configuration.
 type ChatConfig struct {
+	// SigningKey is the legacy single HMAC key, used with key ID "default"
+	// when SigningKeys is empty.
 	SigningKey string
-	Issuer     string
-	Audience   string
-	TokenTTL   time.Duration
+	// SigningKeys are the keys tokens may be signed with; all of them verify.
+	SigningKeys []ChatSigningKey
+	// ActiveKeyID selects the key new tokens are signed with (default: the
+	// first key).
+	ActiveKeyID string
+	Issuer      string
+	Audience    string
+	TokenTTL    time.Duration
+	// Leeway is the clock skew allowed when verifying exp.
+	Leeway time.Duration
+	// RefreshGrace is how long after expiry a token can still be refreshed.
+	RefreshGrace time.Duration
+	// RevocationSync is how often the revocation list is reloaded.
+	RevocationSync time.Duration
+}
+
+// ChatSigningKey is an HMAC key identified by the JWT kid header.
+type ChatSigningKey struct {
+	ID     string
+	Secret string
+}
+
+// ADSBConfig holds ADS-B feeder ingestion settings.
+type ADSBConfig struct {
+	// SBSListenAddr is the TCP address for SBS-1 BaseStation feeds, e.g.
+	// ":30003". Empty disables the listener.
+	SBSListenAddr string
+	// SBSAllowedCIDRs restricts which feeders may connect; empty allows all.
+	SBSAllowedCIDRs []netip.Prefix
+	// SBSFlushInterval is how often buffered SBS messages are written.
+	SBSFlushInterval time.Duration
+	// WatchlistRefresh is how often the aircraft watchlist is reloaded.
+	WatchlistRefresh time.Duration
+	// EmergencyResetAfter ends an emergency episode for an aircraft that has
+	// not reported for this long, so a later emergency alerts again.
+	EmergencyResetAfter time.Duration
+	// EmergencyCreateChase creates a live aircraft chase for each emergency.
+	EmergencyCreateChase bool
+}
+
+// BoFConfig tunes "Birds of a Feather" detection: media and law
+// enforcement aircraft circling the same spot, which usually means a chase.
+type BoFConfig struct {
+	Enabled  bool
+	Interval time.Duration
+	// EpsMeters and MinPoints are the DBSCAN parameters; a cluster needs a
+	// core aircraft with at least MinPoints neighbours within EpsMeters.
+	EpsMeters int
+	MinPoints int
+	// MaxAltitude excludes higher traffic (feet) such as airliners overhead.
+	MaxAltitude int
+	// Dwell is how long a cluster must hold position before a draft chase
+	// is created.
+	Dwell time.Duration
+	// Cooldown suppresses further drafts near a reported spot.
+	Cooldown time.Duration
 }
 
 // ExternalConfig holds external API configuration.
@@ -122,6 +243,9 @@ func Load() (*Config, error) {
 			ReadTimeout:     getEnvDuration("SERVER_READ_TIMEOUT", 30*time.Second),
 			WriteTimeout:    getEnvDuration("SERVER_WRITE_TIMEOUT", 30*time.Second),
 			ShutdownTimeout: getEnvDuration("SERVER_SHUTDOWN_TIMEOUT", 30*time.Second),
+			SSEHeartbeat:    getEnvDuration("SERVER_SSE_HEARTBEAT", 15*time.Second),
+			WSThrottle:      getEnvDuration("SERVER_WS_THROTTLE", time.Second),
+			WSStaleAfter:    getEnvDuration("SERVER_WS_STALE_AFTER", 30*time.Second),
 		},
 		Database: DatabaseConfig{
 			Host:        getEnv("DB_HOST", "localhost"),
@@ -155,16 +279,65 @@ func Load() (*Config, error) {
 			APNsTeamID:   getEnv("APNS_TEAM_ID", ""),
 			APNsKeyPath:  getEnv("APNS_KEY_PATH", ""),
 			APNsBundleID: getEnv("APNS_BUNDLE_ID", ""),
+			APNsSandbox:  getEnvBool("APNS_SANDBOX", false),
 			FCMProjectID: getEnv("FCM_PROJECT_ID", ""),
 			FCMKeyPath:   getEnv("FCM_KEY_PATH", ""),
+			FCMTokenURL:  getEnv("FCM_TOKEN_URL", ""),
+			FCMBaseURL:   getEnv("FCM_BASE_URL", ""),
 			SafariPushID: getEnv("SAFARI_PUSH_ID", ""),
 			SafariWebURL: getEnv("SAFARI_WEB_SERVICE_URL", ""),
+
+			SafariWebsiteURL:       getEnv("SAFARI_WEBSITE_URL", ""),
+			SafariCertPath:         getEnv("SAFARI_CERT_PATH", ""),
+			SafariCertPassword:     getEnv("SAFARI_CERT_PASSWORD", ""),
+			SafariIntermediatePath: getEnv("SAFARI_INTERMEDIATE_CERT_PATH", ""),
+			SafariIconDir:          getEnv("SAFARI_ICON_DIR", "pushPackage/icon.iconset"),
+			SafariAuthSecret:       getEnv("SAFARI_AUTH_SECRET", ""),
+
+			VAPIDPrivateKey: getEnv("VAPID_PRIVATE_KEY", ""),
+			VAPIDSubject:    getEnv("VAPID_SUBJECT", ""),
+
+			DeliveryConcurrency:  getEnvInt("PUSH_CONCURRENCY", 16),
+			DeliveryMaxRetries:   getEnvInt("PUSH_MAX_RETRIES", 3),
+			DeliveryRetryBackoff: getEnvDuration("PUSH_RETRY_BACKOFF", 500*time.Millisecond),
+		},
+		Auth: AuthConfig{
+			Mode:             getEnv("AUTH_MODE", "header"),
+			JWKSURL:          getEnv("AUTH_JWKS_URL", ""),
+			JWKSRefresh:      getEnvDuration("AUTH_JWKS_REFRESH", time.Hour),
+			JWTPublicKeyPath: getEnv("AUTH_JWT_PUBLIC_KEY_PATH", ""),
+			JWTSecret:        getEnv("AUTH_JWT_SECRET", ""),
+			JWTIssuer:        getEnv("AUTH_JWT_ISSUER", ""),
+			JWTAudience:      getEnv("AUTH_JWT_AUDIENCE", ""),
+			JWTLeeway:        getEnvDuration("AUTH_JWT_LEEWAY", 30*time.Second),
+			AdminUserIDs:     getEnvList("ADMIN_USER_IDS"),
 		},
 		Chat: ChatConfig{
-			SigningKey: getEnv("CHAT_SIGNING_KEY", ""),
-			Issuer:     getEnv("CHAT_TOKEN_ISSUER", "chaseapp"),
-			Audience:   getEnv("CHAT_TOKEN_AUDIENCE", "chat"),
-			TokenTTL:   getEnvDuration("CHAT_TOKEN_TTL", 15*time.Minute),
+			SigningKey:     getEnv("CHAT_SIGNING_KEY", ""),
+			SigningKeys:    parseChatSigningKeys(getEnvList("CHAT_SIGNING_KEYS")),
+			ActiveKeyID:    getEnv("CHAT_SIGNING_KEY_ID", ""),
+			Issuer:         getEnv("CHAT_TOKEN_ISSUER", "chaseapp"),
+			Audience:       getEnv("CHAT_TOKEN_AUDIENCE", "chat"),
+			TokenTTL:       getEnvDuration("CHAT_TOKEN_TTL", 15*time.Minute),
+			Leeway:         getEnvDuration("CHAT_TOKEN_LEEWAY", 30*time.Second),
+			RefreshGrace:   getEnvDuration("CHAT_TOKEN_REFRESH_GRACE", 5*time.Minute),
+			RevocationSync: getEnvDuration("CHAT_REVOCATION_SYNC", 15*time.Second),
+		},
+		ADSB: ADSBConfig{
+			SBSListenAddr:        getEnv("ADSB_SBS_LISTEN_ADDR", ""),
+			SBSFlushInterval:     getEnvDuration("ADSB_SBS_FLUSH_INTERVAL", time.Second),
+			WatchlistRefresh:     getEnvDuration("ADSB_WATCHLIST_REFRESH", time.Minute),
+			EmergencyResetAfter:  getEnvDuration("ADSB_EMERGENCY_RESET_AFTER", 15*time.Minute),
+			EmergencyCreateChase: getEnvBool("ADSB_EMERGENCY_CREATE_CHASE", false),
+		},
+		BoF: BoFConfig{
+			Enabled:     getEnvBool("BOF_ENABLED", true),
+			Interval:    getEnvDuration("BOF_INTERVAL", 30*time.Second),
+			EpsMeters:   getEnvInt("BOF_EPS_METERS", 3000),
+			MinPoints:   getEnvInt("BOF_MIN_POINTS", 2),
+			MaxAltitude: getEnvInt("BOF_MAX_ALTITUDE", 6000),
+			Dwell:       getEnvDuration("BOF_DWELL", 5*time.Minute),
+			Cooldown:    getEnvDuration("BOF_COOLDOWN", time.Hour),
 		},
 		External: ExternalConfig{
 			USGSBaseURL:          getEnv("USGS_BASE_URL", "https://earthquake.usgs.gov"),
@@ -182,6 +355,12 @@ func Load() (*Config, error) {
 		},
 	}
 
+	cidrs, err := parseCIDRs(getEnvList("ADSB_SBS_ALLOWED_CIDRS"))
+	if err != nil {
+		return nil, fmt.Errorf("ADSB_SBS_ALLOWED_CIDRS: %w", err)
+	}
+	cfg.ADSB.SBSAllowedCIDRs = cidrs
+
 	return cfg, nil
 }
 
@@ -193,6 +372,49 @@ func getEnv(key, defaultVal string) string {
 	return defaultVal
 }
 
+// getEnvList returns a comma-separated environment variable as a slice.
+func getEnvList(key string) []string {
+	var list []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// parseChatSigningKeys parses "kid:secret" entries.
+func parseChatSigningKeys(entries []string) []ChatSigningKey {
+	var keys []ChatSigningKey
+	for _, entry := range entries {
+		id, secret, ok := strings.Cut(entry, ":")
+		if !ok || id == "" || secret == "" {
+			continue
+		}
+		keys = append(keys, ChatSigningKey{ID: id, Secret: secret})
+	}
+	return keys
+}
+
+// parseCIDRs parses CIDR prefixes; a bare address is taken as a single
+// host. Errors are returned rather than skipped so that a typo cannot
+// silently widen an allowlist.
+func parseCIDRs(values []string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, v := range values {
+		if addr, err := netip.ParseAddr(v); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(v)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
 // getEnvInt returns an environment variable as int or a default value.
 func getEnvInt(key string, defaultVal int) int {
 	if val := os.Getenv(key); val != "" {
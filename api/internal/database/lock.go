@@ -0,0 +1,69 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// AdvisoryLock is a PostgreSQL session advisory lock, used to run a worker
+// on only one replica. The lock is held on a connection taken out of the
+// pool, so it passes to another replica when the holder exits or loses its
+// connection. AdvisoryLock is not safe for concurrent use.
+type AdvisoryLock struct {
+	pool *pgxpool.Pool
+	key  int64
+	conn *pgxpool.Conn
+}
+
+// NewAdvisoryLock creates an advisory lock keyed by name.
+func NewAdvisoryLock(pool *pgxpool.Pool, name string) *AdvisoryLock {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return &AdvisoryLock{pool: pool, key: int64(h.Sum64())}
+}
+
+// TryAcquire reports whether this process holds the lock, taking it if it
+// is free. It does not wait for another holder to release it.
+func (l *AdvisoryLock) TryAcquire(ctx context.Context) (bool, error) {
+	if l.conn != nil {
+		if err := l.conn.Ping(ctx); err == nil {
+			return true, nil
+		}
+		// The session, and with it the lock, is gone.
+		_ = l.conn.Conn().Close(ctx)
+		l.conn.Release()
+		l.conn = nil
+	}
+
+	conn, err := l.pool.Acquire(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire connection for advisory lock: %w", err)
+	}
+	var locked bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, l.key).Scan(&locked); err != nil {
+		conn.Release()
+		return false, fmt.Errorf("failed to take advisory lock: %w", err)
+	}
+	if !locked {
+		conn.Release()
+		return false, nil
+	}
+	l.conn = conn
+	return true, nil
+}
+
+// Release gives up the lock if it is held.
+func (l *AdvisoryLock) Release(ctx context.Context) {
+	if l.conn == nil {
+		return
+	}
+	if _, err := l.conn.Exec(ctx, `SELECT pg_advisory_unlock($1)`, l.key); err != nil {
+		// Closing the session releases the lock too.
+		_ = l.conn.Conn().Close(ctx)
+	}
+	l.conn.Release()
+	l.conn = nil
+}
@@ -0,0 +1,318 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"chaseapp.tv/api/internal/model"
+)
+
+const (
+	// writeWait bounds each write; a client that cannot take a batch in
+	// this time is disconnected.
+	writeWait = 10 * time.Second
+	// pongWait is how long a connection may stay silent, including pongs.
+	pongWait = 60 * time.Second
+	// pingPeriod must be shorter than pongWait.
+	pingPeriod = pongWait * 9 / 10
+	// maxMessageSize limits client messages, which only carry a viewport.
+	maxMessageSize = 4096
+	// snapshotLimit caps the aircraft sent when a viewport is set.
+	snapshotLimit = 100
+)
+
+// AircraftLister looks up aircraft for the initial viewport snapshot.
+type AircraftLister interface {
+	List(ctx context.Context, opts model.AircraftListOptions) (*model.AircraftListResult, error)
+}
+
+// AircraftConfig tunes per-connection delivery.
+type AircraftConfig struct {
+	// Throttle is the minimum interval between batches to a connection.
+	Throttle time.Duration
+	// StaleAfter drops positions whose last_seen_at is older than this when
+	// the batch is sent.
+	StaleAfter time.Duration
+	// MaxPending caps the distinct aircraft queued per connection.
+	MaxPending int
+}
+
+// AircraftHub fans aircraft updates out to WebSocket clients, each receiving
+// only the aircraft inside its viewport. Between batches a connection keeps
+// just the latest position per aircraft, so a slow client gets fewer,
+// fresher updates instead of an ever-growing backlog.
+type AircraftHub struct {
+	cfg     AircraftConfig
+	source  AircraftLister
+	logger  *slog.Logger
+	mu      sync.RWMutex
+	clients map[*aircraftClient]struct{}
+	done    chan struct{}
+	close   sync.Once
+}
+
+// NewAircraftHub creates a new AircraftHub. source may be nil to skip
+// viewport snapshots.
+func NewAircraftHub(cfg AircraftConfig, source AircraftLister, logger *slog.Logger) *AircraftHub {
+	if cfg.Throttle <= 0 {
+		cfg.Throttle = time.Second
+	}
+	if cfg.MaxPending <= 0 {
+		cfg.MaxPending = 5000
+	}
+	return &AircraftHub{
+		cfg:     cfg,
+		source:  source,
+		logger:  logger,
+		clients: make(map[*aircraftClient]struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// Close disconnects every client, for server shutdown.
+func (h *AircraftHub) Close() {
+	h.close.Do(func() { close(h.done) })
+}
+
+// Publish offers updated aircraft to every connected client.
+func (h *AircraftHub) Publish(aircraft []model.Aircraft) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for c := range h.clients {
+		for i := range aircraft {
+			c.offer(aircraft[i])
+		}
+	}
+}
+
+// Clients returns the number of connected clients.
+func (h *AircraftHub) Clients() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients)
+}
+
+// clientMessage is sent by clients to set their viewport.
+type clientMessage struct {
+	Type string    `json:"type"`
+	BBox []float64 `json:"bbox"`
+}
+
+// serverMessage is a batch of aircraft or an error.
+type serverMessage struct {
+	Type     string           `json:"type"`
+	Aircraft []model.Aircraft `json:"aircraft,omitempty"`
+	Dropped  int              `json:"dropped,omitempty"`
+	Error    string           `json:"error,omitempty"`
+}
+
+// Serve runs a client connection until it closes or ctx is cancelled. The
+// client receives nothing until a viewport is set, either initially or with
+// a {"type": "viewport", "bbox": [minLng, minLat, maxLng, maxLat]} message.
+func (h *AircraftHub) Serve(ctx context.Context, conn *websocket.Conn, initial *BBox) {
+	c := newAircraftClient(h.cfg.MaxPending)
+	h.mu.Lock()
+	h.clients[c] = struct{}{}
+	h.mu.Unlock()
+
+	defer func() {
+		h.mu.Lock()
+		delete(h.clients, c)
+		h.mu.Unlock()
+		conn.Close()
+	}()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make(chan string, 1)
+	if initial != nil {
+		h.setViewport(ctx, c, *initial)
+	}
+	go h.read(ctx, cancel, conn, c, errs)
+
+	throttle := time.NewTicker(h.cfg.Throttle)
+	defer throttle.Stop()
+	ping := time.NewTicker(pingPeriod)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-h.done:
+			cancel()
+		case <-ctx.Done():
+			_ = conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseGoingAway, ""), time.Now().Add(time.Second))
+			return
+		case msg := <-errs:
+			if err := write(conn, serverMessage{Type: "error", Error: msg}); err != nil {
+				return
+			}
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				return
+			}
+		case <-throttle.C:
+			batch, dropped := c.take(time.Now(), h.cfg.StaleAfter)
+			if len(batch) == 0 && dropped == 0 {
+				continue
+			}
+			if err := write(conn, serverMessage{Type: "aircraft", Aircraft: batch, Dropped: dropped}); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// read handles viewport messages and pongs, cancelling the connection when
+// the client goes away.
+func (h *AircraftHub) read(ctx context.Context, cancel context.CancelFunc, conn *websocket.Conn, c *aircraftClient, errs chan<- string) {
+	defer cancel()
+
+	conn.SetReadLimit(maxMessageSize)
+	_ = conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(pongWait))
+
+		var msg clientMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			reportError(errs, "invalid message")
+			continue
+		}
+
+		if msg.Type != "viewport" {
+			reportError(errs, "unknown message type")
+			continue
+		}
+		bbox, err := NewBBox(msg.BBox)
+		if err != nil {
+			reportError(errs, err.Error())
+			continue
+		}
+		h.setViewport(ctx, c, bbox)
+	}
+}
+
+// setViewport moves the client's viewport and queues the aircraft already
+// known inside it.
+func (h *AircraftHub) setViewport(ctx context.Context, c *aircraftClient, bbox BBox) {
+	c.setBBox(bbox)
+	if h.source == nil || bbox.crossesAntimeridian() {
+		return
+	}
+
+	result, err := h.source.List(ctx, model.AircraftListOptions{
+		Limit:  snapshotLimit,
+		MinLat: &bbox.MinLat,
+		MaxLat: &bbox.MaxLat,
+		MinLng: &bbox.MinLng,
+		MaxLng: &bbox.MaxLng,
+	})
+	if err != nil {
+		h.logger.Warn("aircraft viewport snapshot failed", slog.Any("error", err))
+		return
+	}
+	for i := range result.Aircraft {
+		c.offer(result.Aircraft[i])
+	}
+}
+
+func reportError(errs chan<- string, msg string) {
+	select {
+	case errs <- msg:
+	default:
+	}
+}
+
+func write(conn *websocket.Conn, msg serverMessage) error {
+	_ = conn.SetWriteDeadline(time.Now().Add(writeWait))
+	return conn.WriteJSON(msg)
+}
+
+// aircraftClient holds one connection's viewport and the latest queued
+// position per aircraft.
+type aircraftClient struct {
+	mu         sync.Mutex
+	bbox       *BBox
+	pending    map[string]model.Aircraft
+	maxPending int
+	dropped    int
+}
+
+func newAircraftClient(maxPending int) *aircraftClient {
+	return &aircraftClient{
+		pending:    make(map[string]model.Aircraft),
+		maxPending: maxPending,
+	}
+}
+
+// offer queues the aircraft if it is inside the viewport, replacing any
+// older queued position.
+func (c *aircraftClient) offer(a model.Aircraft) {
+	if a.Latitude == nil || a.Longitude == nil || a.ICAO == "" {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.bbox == nil || !c.bbox.Contains(*a.Latitude, *a.Longitude) {
+		return
+	}
+	if prev, ok := c.pending[a.ICAO]; ok {
+		if a.LastSeenAt.Before(prev.LastSeenAt) {
+			return
+		}
+	} else if len(c.pending) >= c.maxPending {
+		c.dropped++
+		return
+	}
+	c.pending[a.ICAO] = a
+}
+
+// setBBox changes the viewport and discards queued aircraft outside it.
+func (c *aircraftClient) setBBox(b BBox) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.bbox = &b
+	for icao, a := range c.pending {
+		if !b.Contains(*a.Latitude, *a.Longitude) {
+			delete(c.pending, icao)
+		}
+	}
+}
+
+// take returns the queued aircraft, minus those last seen more than
+// staleAfter ago, and the number dropped since the last batch.
+func (c *aircraftClient) take(now time.Time, staleAfter time.Duration) ([]model.Aircraft, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	batch := make([]model.Aircraft, 0, len(c.pending))
+	dropped := c.dropped
+	for icao, a := range c.pending {
+		delete(c.pending, icao)
+		if staleAfter > 0 && !a.LastSeenAt.IsZero() && now.Sub(a.LastSeenAt) > staleAfter {
+			dropped++
+			continue
+		}
+		batch = append(batch, a)
+	}
+	c.dropped = 0
+	return batch, dropped
+}
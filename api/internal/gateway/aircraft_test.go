@@ -0,0 +1,105 @@
+package gateway
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+
+	"chaseapp.tv/api/internal/model"
+)
+
+func aircraftAt(icao string, lat, lng float64, seen time.Time) model.Aircraft {
+	return model.Aircraft{ICAO: icao, Latitude: &lat, Longitude: &lng, LastSeenAt: seen}
+}
+
+func TestBBoxContains(t *testing.T) {
+	la, err := ParseBBox("-118.7,33.7,-117.6,34.4")
+	require.NoError(t, err)
+	require.True(t, la.Contains(34.05, -118.24))
+	require.False(t, la.Contains(40.7, -74.0))
+
+	pacific, err := NewBBox([]float64{170, -20, -170, 20})
+	require.NoError(t, err)
+	require.True(t, pacific.Contains(0, 179))
+	require.True(t, pacific.Contains(0, -175))
+	require.False(t, pacific.Contains(0, 0))
+
+	_, err = NewBBox([]float64{0, 10, 1, 5})
+	require.Error(t, err)
+}
+
+func TestAircraftClientCoalescesAndDropsStale(t *testing.T) {
+	now := time.Now()
+	c := newAircraftClient(2)
+	c.offer(aircraftAt("a1", 34, -118, now)) // no viewport yet
+	c.setBBox(BBox{MinLng: -119, MinLat: 33, MaxLng: -117, MaxLat: 35})
+
+	c.offer(aircraftAt("a1", 34, -118, now.Add(-time.Second)))
+	c.offer(aircraftAt("a1", 34.1, -118, now))
+	c.offer(aircraftAt("a1", 34.2, -118, now.Add(-2*time.Second))) // older, ignored
+	c.offer(aircraftAt("b2", 34, -118, now.Add(-time.Minute)))
+	c.offer(aircraftAt("c3", 34, -118, now)) // over MaxPending
+	c.offer(aircraftAt("d4", 40, -74, now))  // outside
+
+	batch, dropped := c.take(now, 30*time.Second)
+	require.Len(t, batch, 1)
+	require.Equal(t, "a1", batch[0].ICAO)
+	require.Equal(t, 34.1, *batch[0].Latitude)
+	require.Equal(t, 2, dropped) // c3 over the cap, b2 stale
+
+	batch, dropped = c.take(now, 30*time.Second)
+	require.Empty(t, batch)
+	require.Zero(t, dropped)
+}
+
+func TestAircraftHubServe(t *testing.T) {
+	hub := NewAircraftHub(AircraftConfig{Throttle: 20 * time.Millisecond}, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		hub.Serve(r.Context(), conn, nil)
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(hub.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.NoError(t, conn.WriteJSON(map[string]any{"type": "viewport", "bbox": []float64{-119, 33, -117, 35}}))
+	require.Eventually(t, func() bool {
+		hub.mu.RLock()
+		defer hub.mu.RUnlock()
+		for c := range hub.clients {
+			c.mu.Lock()
+			ok := c.bbox != nil
+			c.mu.Unlock()
+			return ok
+		}
+		return false
+	}, time.Second, 5*time.Millisecond)
+
+	now := time.Now()
+	hub.Publish([]model.Aircraft{aircraftAt("inside", 34, -118, now), aircraftAt("outside", 40, -74, now)})
+
+	var msg serverMessage
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	require.NoError(t, conn.ReadJSON(&msg))
+	require.Equal(t, "aircraft", msg.Type)
+	require.Len(t, msg.Aircraft, 1)
+	require.Equal(t, "inside", msg.Aircraft[0].ICAO)
+
+	require.NoError(t, conn.WriteJSON(map[string]any{"type": "viewport", "bbox": []float64{1, 2}}))
+	require.NoError(t, conn.ReadJSON(&msg))
+	require.Equal(t, "error", msg.Type)
+}
@@ -0,0 +1,62 @@
+// Package gateway pushes live data to browsers over WebSockets.
+package gateway
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// BBox is a viewport in degrees. MinLng greater than MaxLng denotes a box
+// crossing the antimeridian.
+type BBox struct {
+	MinLng float64
+	MinLat float64
+	MaxLng float64
+	MaxLat float64
+}
+
+// NewBBox builds a BBox from GeoJSON order: [minLng, minLat, maxLng, maxLat].
+func NewBBox(coords []float64) (BBox, error) {
+	if len(coords) != 4 {
+		return BBox{}, errors.New("bbox must be [minLng, minLat, maxLng, maxLat]")
+	}
+	b := BBox{MinLng: coords[0], MinLat: coords[1], MaxLng: coords[2], MaxLat: coords[3]}
+	if b.MinLat < -90 || b.MaxLat > 90 || b.MinLat > b.MaxLat {
+		return BBox{}, errors.New("bbox latitudes must be within -90..90 with min <= max")
+	}
+	if b.MinLng < -180 || b.MaxLng > 180 || b.MinLng > 180 || b.MaxLng < -180 {
+		return BBox{}, errors.New("bbox longitudes must be within -180..180")
+	}
+	return b, nil
+}
+
+// ParseBBox parses "minLng,minLat,maxLng,maxLat".
+func ParseBBox(s string) (BBox, error) {
+	parts := strings.Split(s, ",")
+	coords := make([]float64, 0, len(parts))
+	for _, p := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return BBox{}, errors.New("bbox must be four comma-separated numbers")
+		}
+		coords = append(coords, v)
+	}
+	return NewBBox(coords)
+}
+
+// Contains reports whether the point lies inside the box.
+func (b BBox) Contains(lat, lng float64) bool {
+	if lat < b.MinLat || lat > b.MaxLat {
+		return false
+	}
+	if b.MinLng <= b.MaxLng {
+		return lng >= b.MinLng && lng <= b.MaxLng
+	}
+	return lng >= b.MinLng || lng <= b.MaxLng
+}
+
+// crossesAntimeridian reports whether the box wraps past 180°.
+func (b BBox) crossesAntimeridian() bool {
+	return b.MinLng > b.MaxLng
+}
@@ -2,9 +2,14 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
 
 	"chaseapp.tv/api/internal/model"
 	"chaseapp.tv/api/internal/repository"
@@ -92,7 +97,54 @@ func (h *AircraftHandler) List(w http.ResponseWriter, r *http.Request) {
 	JSON(w, http.StatusOK, result)
 }
 
-// Cluster performs DBSCAN clustering on aircraft positions.
+// activeClusterWindow hides tracked clusters not updated recently, e.g. when
+// clustering is disabled.
+const activeClusterWindow = 5 * time.Minute
+
+// ListClusters returns the tracked aircraft clusters currently active. Their
+// IDs are stable across clustering runs and filter GET /api/v1/aircraft by
+// cluster_id.
+// GET /api/v1/aircraft/clusters
+func (h *AircraftHandler) ListClusters(w http.ResponseWriter, r *http.Request) {
+	clusters, err := h.repo.ListClusters(r.Context(), time.Now().Add(-activeClusterWindow))
+	if err != nil {
+		h.logger.Error("failed to list aircraft clusters", slog.Any("error", err))
+		Error(w, http.StatusInternalServerError, "Failed to retrieve clusters")
+		return
+	}
+
+	JSON(w, http.StatusOK, map[string]interface{}{"clusters": clusters})
+}
+
+// GetCluster returns a tracked aircraft cluster, including one that has
+// ended.
+// GET /api/v1/aircraft/clusters/{id}
+func (h *AircraftHandler) GetCluster(w http.ResponseWriter, r *http.Request) {
+	cluster, err := h.repo.GetCluster(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			Error(w, http.StatusNotFound, "Cluster not found")
+			return
+		}
+		h.logger.Error("failed to get aircraft cluster", slog.Any("error", err))
+		Error(w, http.StatusInternalServerError, "Failed to retrieve cluster")
+		return
+	}
+
+	JSON(w, http.StatusOK, cluster)
+}
+
+// History clustering limits and defaults.
+const (
+	defaultClusterHistory = 10 * time.Minute
+	maxClusterHistory     = time.Hour
+	defaultClusterEpsTime = time.Minute
+	// maxClusterPositions caps the history positions clustered per request.
+	maxClusterPositions = 50000
+)
+
+// Cluster performs DBSCAN clustering on aircraft positions, or ST-DBSCAN on
+// their recent history with use_history.
 // POST /api/v1/aircraft/cluster
 func (h *AircraftHandler) Cluster(w http.ResponseWriter, r *http.Request) {
 	var input model.ClusterAircraftInput
@@ -131,6 +183,11 @@ func (h *AircraftHandler) Cluster(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 
+	if input.UseHistory {
+		h.clusterHistory(w, r, input)
+		return
+	}
+
 	clusters := dbscan.ClusterPoints(points, input.EpsilonMeters, input.MinPoints)
 	results := make([]model.ClusterResult, 0, len(clusters))
 
@@ -180,3 +237,112 @@ func (h *AircraftHandler) Cluster(w http.ResponseWriter, r *http.Request) {
 
 	JSON(w, http.StatusOK, model.ClusterResponse{Clusters: results})
 }
+
+// clusterHistory clusters the input aircraft's positions over the last
+// history_seconds with ST-DBSCAN. Aircraft without history are clustered at
+// their given position as of now. Clusters formed by a single aircraft's
+// own track are dropped, and centroids are the mean of the clustered
+// positions.
+func (h *AircraftHandler) clusterHistory(w http.ResponseWriter, r *http.Request, input model.ClusterAircraftInput) {
+	window := defaultClusterHistory
+	if input.HistorySeconds != 0 {
+		window = time.Duration(input.HistorySeconds) * time.Second
+	}
+	if window <= 0 || window > maxClusterHistory {
+		Error(w, http.StatusBadRequest, "history_seconds must be between 1 and 3600")
+		return
+	}
+	epsTime := defaultClusterEpsTime
+	if input.EpsilonSeconds != 0 {
+		epsTime = time.Duration(input.EpsilonSeconds) * time.Second
+	}
+	if epsTime <= 0 || input.EpsilonAltitude < 0 {
+		Error(w, http.StatusBadRequest, "eps_seconds and eps_altitude must not be negative")
+		return
+	}
+
+	icaos := make([]string, 0, len(input.Points))
+	for _, p := range input.Points {
+		if p.ICAO != "" {
+			icaos = append(icaos, strings.ToUpper(p.ICAO))
+		}
+	}
+	now := time.Now().UTC()
+	history, err := h.repo.GetRecentHistory(r.Context(), icaos, now.Add(-window), maxClusterPositions)
+	if err != nil {
+		h.logger.Error("failed to get aircraft history for clustering", slog.Any("error", err))
+		Error(w, http.StatusInternalServerError, "Failed to retrieve aircraft history")
+		return
+	}
+
+	var points []dbscan.STPoint
+	for i, p := range input.Points {
+		id := strconv.Itoa(i)
+		positions := history[strings.ToUpper(p.ICAO)]
+		if len(positions) == 0 {
+			points = append(points, dbscan.STPoint{
+				Point:    dbscan.Point{ID: id, Lat: p.Latitude, Lng: p.Longitude},
+				Time:     now,
+				Altitude: feet(p.Altitude),
+			})
+			continue
+		}
+		for _, pos := range positions {
+			points = append(points, dbscan.STPoint{
+				Point:    dbscan.Point{ID: id, Lat: pos.Latitude, Lng: pos.Longitude},
+				Time:     pos.RecordedAt,
+				Altitude: feet(pos.Altitude),
+			})
+		}
+	}
+
+	clusters := dbscan.ClusterSTPoints(points, dbscan.STOptions{
+		EpsMeters:   input.EpsilonMeters,
+		EpsTime:     epsTime,
+		EpsAltitude: float64(input.EpsilonAltitude),
+		MinPoints:   input.MinPoints,
+	})
+
+	results := make([]model.ClusterResult, 0, len(clusters))
+	for _, c := range clusters {
+		result := model.ClusterResult{ID: c.ID}
+		seen := make(map[string]bool)
+		for _, pt := range c.Points {
+			result.CentroidLat += pt.Lat
+			result.CentroidLng += pt.Lng
+			if seen[pt.ID] {
+				continue
+			}
+			seen[pt.ID] = true
+			i, _ := strconv.Atoi(pt.ID)
+			cp := input.Points[i]
+			result.Points = append(result.Points, cp)
+			if cp.Category == model.AircraftCategoryMedia {
+				result.MediaPresent = true
+			}
+		}
+		if len(result.Points) < 2 {
+			continue
+		}
+		result.Size = len(result.Points)
+		result.CentroidLat /= float64(len(c.Points))
+		result.CentroidLng /= float64(len(c.Points))
+		results = append(results, result)
+	}
+
+	h.logger.Info("aircraft clustered with history",
+		slog.Int("clusters", len(results)),
+		slog.Int("points", len(input.Points)),
+		slog.Int("positions", len(points)),
+	)
+
+	JSON(w, http.StatusOK, model.ClusterResponse{Clusters: results})
+}
+
+func feet(altitude *int) *float64 {
+	if altitude == nil {
+		return nil
+	}
+	v := float64(*altitude)
+	return &v
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"time"
+
+	"chaseapp.tv/api/internal/ingest"
+	"chaseapp.tv/api/pkg/adsb"
+)
+
+// maxIngestBody caps an ingest payload after decompression.
+const maxIngestBody = 16 << 20
+
+// AircraftIngestHandler accepts ADS-B feeder uploads.
+type AircraftIngestHandler struct {
+	ingester *ingest.Ingester
+	logger   *slog.Logger
+}
+
+// NewAircraftIngestHandler creates a new AircraftIngestHandler.
+func NewAircraftIngestHandler(ingester *ingest.Ingester, logger *slog.Logger) *AircraftIngestHandler {
+	return &AircraftIngestHandler{
+		ingester: ingester,
+		logger:   logger,
+	}
+}
+
+// Ingest upserts aircraft from a dump1090/readsb aircraft.json document
+// (application/json) or SBS-1 BaseStation lines (text/plain). Bodies may
+// be gzip-encoded.
+// POST /api/v1/aircraft/ingest
+func (h *AircraftIngestHandler) Ingest(w http.ResponseWriter, r *http.Request) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/json" && mediaType != "text/plain" {
+		Error(w, http.StatusUnsupportedMediaType, "Content-Type must be application/json or text/plain")
+		return
+	}
+
+	var body io.Reader = r.Body
+	switch r.Header.Get("Content-Encoding") {
+	case "", "identity":
+	case "gzip":
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			Error(w, http.StatusBadRequest, "Invalid gzip body")
+			return
+		}
+		defer gz.Close()
+		body = gz
+	default:
+		Error(w, http.StatusUnsupportedMediaType, "Unsupported Content-Encoding")
+		return
+	}
+	body = http.MaxBytesReader(w, io.NopCloser(body), maxIngestBody)
+
+	received := time.Now()
+	var reports []adsb.Report
+	var invalid int
+	var err error
+	if mediaType == "application/json" {
+		var data []byte
+		if data, err = io.ReadAll(body); err == nil {
+			reports, err = adsb.ParseAircraftJSON(data, received)
+		}
+	} else {
+		reports, invalid, err = readSBS(body, received)
+	}
+
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		Error(w, http.StatusRequestEntityTooLarge, "Request body too large")
+		return
+	}
+	if err != nil {
+		Error(w, http.StatusBadRequest, "Invalid feed data")
+		return
+	}
+
+	res, err := h.ingester.Ingest(r.Context(), reports)
+	if err != nil {
+		h.logger.Error("failed to ingest aircraft", slog.Any("error", err))
+		Error(w, http.StatusInternalServerError, "Failed to ingest aircraft")
+		return
+	}
+	res.Skipped += invalid
+
+	JSON(w, http.StatusOK, res)
+}
+
+// readSBS parses SBS-1 lines, ignoring non-MSG records and counting lines
+// that fail to parse.
+func readSBS(r io.Reader, received time.Time) ([]adsb.Report, int, error) {
+	var reports []adsb.Report
+	var invalid int
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		report, err := adsb.ParseSBS(scanner.Text(), received)
+		if errors.Is(err, adsb.ErrNotSBSMessage) {
+			continue
+		}
+		if err != nil {
+			invalid++
+			continue
+		}
+		reports = append(reports, report)
+	}
+	return reports, invalid, scanner.Err()
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/websocket"
+
+	"chaseapp.tv/api/internal/gateway"
+)
+
+// AircraftStreamHandler upgrades clients to the live aircraft WebSocket.
+type AircraftStreamHandler struct {
+	hub      *gateway.AircraftHub
+	upgrader websocket.Upgrader
+	logger   *slog.Logger
+}
+
+// NewAircraftStreamHandler creates a new AircraftStreamHandler.
+func NewAircraftStreamHandler(hub *gateway.AircraftHub, logger *slog.Logger) *AircraftStreamHandler {
+	return &AircraftStreamHandler{
+		hub: hub,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 4096,
+			// Aircraft positions are public and CORS allows every origin.
+			CheckOrigin: func(*http.Request) bool { return true },
+		},
+		logger: logger,
+	}
+}
+
+// Stream pushes aircraft updates inside the client's viewport. The initial
+// viewport may be given as bbox=minLng,minLat,maxLng,maxLat.
+// GET /api/v1/aircraft/stream
+func (h *AircraftStreamHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	var initial *gateway.BBox
+	if v := r.URL.Query().Get("bbox"); v != "" {
+		bbox, err := gateway.ParseBBox(v)
+		if err != nil {
+			Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		initial = &bbox
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// The upgrader has already written the error response.
+		h.logger.Debug("websocket upgrade failed", slog.Any("error", err))
+		return
+	}
+
+	h.hub.Serve(r.Context(), conn, initial)
+}
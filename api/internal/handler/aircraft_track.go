@@ -0,0 +1,260 @@
+package handler
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"chaseapp.tv/api/internal/model"
+	"chaseapp.tv/api/internal/repository"
+	"chaseapp.tv/api/pkg/geojson"
+)
+
+const (
+	// defaultTrackWindow is the track length returned without from/to.
+	defaultTrackWindow = time.Hour
+	// maxTrackWindow bounds a request; history is pruned after a day.
+	maxTrackWindow = 24 * time.Hour
+	// maxTrackPoints bounds the positions read for one track.
+	maxTrackPoints = 10000
+
+	feetToMeters = 0.3048
+)
+
+// TrackProperties describes a track. Per-vertex values are parallel to the
+// LineString coordinates; altitude, speed and heading are null where the
+// position was reported without them.
+type TrackProperties struct {
+	ICAO           string      `json:"icao"`
+	Callsign       string      `json:"callsign,omitempty"`
+	Registration   string      `json:"registration,omitempty"`
+	From           time.Time   `json:"from"`
+	To             time.Time   `json:"to"`
+	ToleranceM     float64     `json:"tolerance_m"`
+	OriginalPoints int         `json:"original_points"`
+	Points         int         `json:"points"`
+	Truncated      bool        `json:"truncated,omitempty"` // more than maxTrackPoints positions in range
+	Times          []time.Time `json:"times"`
+	Altitudes      []*int      `json:"altitudes"`     // feet
+	GroundSpeeds   []*int      `json:"ground_speeds"` // knots
+	Tracks         []*int      `json:"tracks"`        // degrees
+}
+
+// TrackFeature is a GeoJSON Feature with a LineString geometry. Geometry is
+// null when fewer than two positions are available.
+type TrackFeature struct {
+	Type       string          `json:"type"`
+	Geometry   *TrackGeometry  `json:"geometry"`
+	Properties TrackProperties `json:"properties"`
+}
+
+// TrackGeometry is a GeoJSON LineString of [lng, lat] positions.
+type TrackGeometry struct {
+	Type        string       `json:"type"`
+	Coordinates [][2]float64 `json:"coordinates"`
+}
+
+// Track returns an aircraft's flight path between from and to (RFC 3339,
+// default the last hour) as a GeoJSON LineString Feature, or KML with
+// format=kml. tolerance simplifies the path with Douglas–Peucker, in meters.
+// GET /api/v1/aircraft/{icao}/track
+func (h *AircraftHandler) Track(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	q := r.URL.Query()
+
+	to := time.Now().UTC()
+	if v := q.Get("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			Error(w, http.StatusBadRequest, "to must be an RFC 3339 timestamp")
+			return
+		}
+		to = t
+	}
+	from := to.Add(-defaultTrackWindow)
+	if v := q.Get("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			Error(w, http.StatusBadRequest, "from must be an RFC 3339 timestamp")
+			return
+		}
+		from = t
+	}
+	if !from.Before(to) {
+		Error(w, http.StatusBadRequest, "from must be before to")
+		return
+	}
+	if to.Sub(from) > maxTrackWindow {
+		Error(w, http.StatusBadRequest, "Track range is limited to 24h")
+		return
+	}
+
+	var tolerance float64
+	if v := q.Get("tolerance"); v != "" {
+		t, err := strconv.ParseFloat(v, 64)
+		if err != nil || t < 0 {
+			Error(w, http.StatusBadRequest, "tolerance must be a non-negative number of meters")
+			return
+		}
+		tolerance = t
+	}
+
+	format := strings.ToLower(q.Get("format"))
+	if format == "" && strings.Contains(r.Header.Get("Accept"), "kml") {
+		format = "kml"
+	}
+	if format != "" && format != "geojson" && format != "kml" {
+		Error(w, http.StatusBadRequest, "format must be geojson or kml")
+		return
+	}
+
+	aircraft, err := h.repo.GetByICAO(ctx, strings.ToUpper(mux.Vars(r)["icao"]))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			Error(w, http.StatusNotFound, "Aircraft not found")
+			return
+		}
+		h.logger.Error("failed to get aircraft", slog.Any("error", err))
+		Error(w, http.StatusInternalServerError, "Failed to retrieve aircraft")
+		return
+	}
+
+	history, err := h.repo.GetTrack(ctx, aircraft.ID, from, to, maxTrackPoints)
+	if err != nil {
+		h.logger.Error("failed to get aircraft track", slog.Any("error", err), slog.String("icao", aircraft.ICAO))
+		Error(w, http.StatusInternalServerError, "Failed to retrieve track")
+		return
+	}
+
+	feature := buildTrack(aircraft, history, from, to, tolerance)
+	feature.Properties.Truncated = len(history) == maxTrackPoints
+
+	if format == "kml" {
+		if err := writeTrackKML(w, feature); err != nil {
+			h.logger.Warn("failed to write track", slog.Any("error", err))
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/geo+json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(feature); err != nil {
+		h.logger.Warn("failed to write track", slog.Any("error", err))
+	}
+}
+
+// buildTrack simplifies history and converts it to a LineString Feature.
+func buildTrack(a *model.Aircraft, history []model.AircraftHistory, from, to time.Time, tolerance float64) TrackFeature {
+	points := make([]geojson.Point, len(history))
+	for i, h := range history {
+		points[i] = geojson.Point{X: h.Longitude, Y: h.Latitude}
+	}
+	keep := geojson.Simplify(points, tolerance)
+
+	props := TrackProperties{
+		ICAO:           a.ICAO,
+		Callsign:       a.Callsign,
+		Registration:   a.Registration,
+		From:           from,
+		To:             to,
+		ToleranceM:     tolerance,
+		OriginalPoints: len(history),
+		Points:         len(keep),
+		Times:          make([]time.Time, 0, len(keep)),
+		Altitudes:      make([]*int, 0, len(keep)),
+		GroundSpeeds:   make([]*int, 0, len(keep)),
+		Tracks:         make([]*int, 0, len(keep)),
+	}
+	coords := make([][2]float64, 0, len(keep))
+	for _, i := range keep {
+		h := history[i]
+		coords = append(coords, [2]float64{h.Longitude, h.Latitude})
+		props.Times = append(props.Times, h.RecordedAt.UTC())
+		props.Altitudes = append(props.Altitudes, h.Altitude)
+		props.GroundSpeeds = append(props.GroundSpeeds, h.GroundSpeed)
+		props.Tracks = append(props.Tracks, h.Track)
+	}
+
+	feature := TrackFeature{Type: "Feature", Properties: props}
+	if len(coords) >= 2 {
+		feature.Geometry = &TrackGeometry{Type: "LineString", Coordinates: coords}
+	}
+	return feature
+}
+
+type kmlDocument struct {
+	XMLName   xml.Name     `xml:"http://www.opengis.net/kml/2.2 kml"`
+	Name      string       `xml:"Document>name"`
+	Placemark kmlPlacemark `xml:"Document>Placemark"`
+}
+
+type kmlPlacemark struct {
+	Name        string         `xml:"name"`
+	Description string         `xml:"description,omitempty"`
+	Begin       string         `xml:"TimeSpan>begin,omitempty"`
+	End         string         `xml:"TimeSpan>end,omitempty"`
+	LineString  *kmlLineString `xml:"LineString,omitempty"`
+}
+
+type kmlLineString struct {
+	AltitudeMode string `xml:"altitudeMode"`
+	Coordinates  string `xml:"coordinates"`
+}
+
+// writeTrackKML renders a track as a KML LineString. Altitudes are absolute
+// when every vertex has one, otherwise the path is clamped to the ground.
+func writeTrackKML(w http.ResponseWriter, f TrackFeature) error {
+	name := f.Properties.ICAO
+	if f.Properties.Callsign != "" {
+		name = f.Properties.Callsign + " (" + f.Properties.ICAO + ")"
+	}
+	doc := kmlDocument{
+		Name: name,
+		Placemark: kmlPlacemark{
+			Name:        name,
+			Description: fmt.Sprintf("%d of %d positions", f.Properties.Points, f.Properties.OriginalPoints),
+		},
+	}
+	if n := len(f.Properties.Times); n > 0 {
+		doc.Placemark.Begin = f.Properties.Times[0].Format(time.RFC3339)
+		doc.Placemark.End = f.Properties.Times[n-1].Format(time.RFC3339)
+	}
+
+	if f.Geometry != nil {
+		absolute := true
+		for _, alt := range f.Properties.Altitudes {
+			if alt == nil {
+				absolute = false
+				break
+			}
+		}
+		line := &kmlLineString{AltitudeMode: "clampToGround"}
+		if absolute {
+			line.AltitudeMode = "absolute"
+		}
+		coords := make([]string, len(f.Geometry.Coordinates))
+		for i, c := range f.Geometry.Coordinates {
+			if absolute {
+				coords[i] = fmt.Sprintf("%.6f,%.6f,%.0f", c[0], c[1], float64(*f.Properties.Altitudes[i])*feetToMeters)
+			} else {
+				coords[i] = fmt.Sprintf("%.6f,%.6f", c[0], c[1])
+			}
+		}
+		line.Coordinates = strings.Join(coords, " ")
+		doc.Placemark.LineString = line
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.google-earth.kml+xml")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(xml.Header))
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(doc)
+}
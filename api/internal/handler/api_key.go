@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"chaseapp.tv/api/internal/auth"
+	"chaseapp.tv/api/internal/model"
+	"chaseapp.tv/api/internal/repository"
+)
+
+// APIKeyHandler handles admin management of machine client API keys.
+type APIKeyHandler struct {
+	repo   *repository.APIKeyRepository
+	logger *slog.Logger
+}
+
+// NewAPIKeyHandler creates a new APIKeyHandler.
+func NewAPIKeyHandler(repo *repository.APIKeyRepository, logger *slog.Logger) *APIKeyHandler {
+	return &APIKeyHandler{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// Create issues a new API key. The key is only returned in this response.
+// POST /api/v1/admin/api-keys
+func (h *APIKeyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var input model.CreateAPIKeyInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		Error(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if input.Name == "" {
+		Error(w, http.StatusBadRequest, "Name is required")
+		return
+	}
+	if len(input.Scopes) == 0 {
+		Error(w, http.StatusBadRequest, "At least one scope is required")
+		return
+	}
+	for _, s := range input.Scopes {
+		if !s.Valid() {
+			Error(w, http.StatusBadRequest, "Invalid scope: "+string(s))
+			return
+		}
+	}
+	if input.ExpiresAt != nil && input.ExpiresAt.Before(time.Now()) {
+		Error(w, http.StatusBadRequest, "expires_at must be in the future")
+		return
+	}
+
+	key, prefix, err := auth.GenerateAPIKey()
+	if err != nil {
+		h.logger.Error("failed to generate api key", slog.Any("error", err))
+		Error(w, http.StatusInternalServerError, "Failed to create API key")
+		return
+	}
+
+	var createdBy *uuid.UUID
+	if id, err := uuid.Parse(actingUserID(r)); err == nil {
+		createdBy = &id
+	}
+
+	apiKey, err := h.repo.Create(r.Context(), input, auth.HashAPIKey(key), prefix, createdBy)
+	if err != nil {
+		h.logger.Error("failed to create api key", slog.Any("error", err))
+		Error(w, http.StatusInternalServerError, "Failed to create API key")
+		return
+	}
+
+	h.logger.Info("api key issued",
+		slog.String("id", apiKey.ID.String()),
+		slog.String("name", apiKey.Name),
+		slog.Any("scopes", apiKey.Scopes),
+		slog.String("by", actingUserID(r)),
+	)
+	JSON(w, http.StatusCreated, model.IssuedAPIKey{APIKey: *apiKey, Key: key})
+}
+
+// List returns all API keys with their usage.
+// GET /api/v1/admin/api-keys
+func (h *APIKeyHandler) List(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.repo.List(r.Context())
+	if err != nil {
+		h.logger.Error("failed to list api keys", slog.Any("error", err))
+		Error(w, http.StatusInternalServerError, "Failed to retrieve API keys")
+		return
+	}
+
+	JSON(w, http.StatusOK, map[string]interface{}{"api_keys": keys})
+}
+
+// Revoke disables an API key.
+// DELETE /api/v1/admin/api-keys/{id}
+func (h *APIKeyHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, http.StatusBadRequest, "Invalid API key ID")
+		return
+	}
+
+	key, err := h.repo.Revoke(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			Error(w, http.StatusNotFound, "API key not found")
+			return
+		}
+		h.logger.Error("failed to revoke api key", slog.Any("error", err), slog.String("id", id.String()))
+		Error(w, http.StatusInternalServerError, "Failed to revoke API key")
+		return
+	}
+
+	h.logger.Info("api key revoked", slog.String("id", id.String()), slog.String("by", actingUserID(r)))
+	JSON(w, http.StatusOK, key)
+}
@@ -2,36 +2,56 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"log/slog"
 	"net/http"
+	"time"
+
+	"github.com/google/uuid"
 
 	"chaseapp.tv/api/internal/auth"
+	"chaseapp.tv/api/internal/chat"
 	"chaseapp.tv/api/internal/middleware"
+	"chaseapp.tv/api/internal/repository"
 )
 
 // AuthHandler handles authentication-related endpoints.
 type AuthHandler struct {
-	signer *auth.ChatTokenSigner
+	tokens *chat.Tokens
+	chat   *repository.ChatRepository
+	policy *chat.Policy
 	logger *slog.Logger
 }
 
 // NewAuthHandler creates an AuthHandler.
-func NewAuthHandler(signer *auth.ChatTokenSigner, logger *slog.Logger) *AuthHandler {
+func NewAuthHandler(tokens *chat.Tokens, chatRepo *repository.ChatRepository, policy *chat.Policy, logger *slog.Logger) *AuthHandler {
 	return &AuthHandler{
-		signer: signer,
+		tokens: tokens,
+		chat:   chatRepo,
+		policy: policy,
 		logger: logger,
 	}
 }
 
 type chatTokenRequest struct {
+	// Permissions narrows the granted permissions; it cannot widen them.
 	Permissions []string `json:"permissions"`
+	// ChaseID scopes the token to the chase's chat channel.
+	ChaseID *uuid.UUID `json:"chase_id,omitempty"`
 }
 
 type chatTokenResponse struct {
-	Token string `json:"token"`
+	Token       string     `json:"token"`
+	TokenID     string     `json:"jti"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	ChannelID   *uuid.UUID `json:"channel_id,omitempty"`
+	Role        string     `json:"role"`
+	Permissions []string   `json:"permissions"`
 }
 
-// GetChatToken generates a JWT token for the chat service.
+// GetChatToken generates a JWT token for the chat service. Permissions come
+// from the user's chat role and active mutes; banned users are refused.
 // POST /api/v1/auth/chat-token
 func (h *AuthHandler) GetChatToken(w http.ResponseWriter, r *http.Request) {
 	user, ok := middleware.UserFromContext(r.Context())
@@ -39,24 +59,200 @@ func (h *AuthHandler) GetChatToken(w http.ResponseWriter, r *http.Request) {
 		Error(w, http.StatusUnauthorized, "Unauthorized")
 		return
 	}
+	userID, err := uuid.Parse(user.ID)
+	if err != nil {
+		Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
 
 	var req chatTokenRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err.Error() != "EOF" {
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
 		Error(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
-	perms := req.Permissions
-	if len(perms) == 0 {
-		perms = []string{"chat:read", "chat:write"}
+	var channelID *uuid.UUID
+	if req.ChaseID != nil {
+		channel, err := h.chat.EnsureChannel(r.Context(), *req.ChaseID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				Error(w, http.StatusNotFound, "Chase not found")
+				return
+			}
+			h.logger.Error("failed to resolve chat channel", slog.Any("error", err))
+			Error(w, http.StatusInternalServerError, "Failed to generate token")
+			return
+		}
+		channelID = &channel.ID
+	}
+
+	h.issue(w, r, userID, user.Email, channelID, req.Permissions)
+}
+
+type chatTokenRefreshRequest struct {
+	Token string `json:"token"`
+	// Permissions narrows the refreshed token like chatTokenRequest.
+	Permissions []string `json:"permissions"`
+}
+
+// RefreshChatToken exchanges a chat token, valid or within the refresh grace
+// period after expiry, for a new one with permissions re-derived from the
+// user's current chat role and sanctions. The old token is revoked.
+// POST /api/v1/auth/chat-token/refresh
+func (h *AuthHandler) RefreshChatToken(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.UserFromContext(r.Context())
+	if !ok || user.ID == "" {
+		Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	userID, err := uuid.Parse(user.ID)
+	if err != nil {
+		Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req chatTokenRefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		Error(w, http.StatusBadRequest, "token is required")
+		return
+	}
+
+	claims, err := h.tokens.VerifyRefresh(r.Context(), req.Token)
+	if err != nil {
+		Error(w, http.StatusUnauthorized, "Invalid chat token")
+		return
+	}
+	if claims.Subject != user.ID {
+		Error(w, http.StatusForbidden, "Chat token belongs to another user")
+		return
+	}
+
+	var channelID *uuid.UUID
+	if claims.Channel != "" {
+		id, err := uuid.Parse(claims.Channel)
+		if err != nil {
+			Error(w, http.StatusUnauthorized, "Invalid chat token")
+			return
+		}
+		channelID = &id
+	}
+
+	if err := h.tokens.Revoke(r.Context(), claims, "refreshed"); err != nil {
+		h.logger.Error("failed to revoke refreshed chat token", slog.Any("error", err))
+		Error(w, http.StatusInternalServerError, "Failed to refresh token")
+		return
+	}
+	h.issue(w, r, userID, user.Email, channelID, req.Permissions)
+}
+
+// issue resolves the user's grant and writes a new chat token.
+func (h *AuthHandler) issue(w http.ResponseWriter, r *http.Request, userID uuid.UUID, email string, channelID *uuid.UUID, requested []string) {
+	grant, err := h.policy.Grant(r.Context(), userID, middleware.RolesFromContext(r.Context()), channelID, requested)
+	if err != nil {
+		switch {
+		case errors.Is(err, chat.ErrBanned):
+			Error(w, http.StatusForbidden, "You are banned from this chat")
+		case errors.Is(err, repository.ErrNotFound):
+			Error(w, http.StatusNotFound, "User not found")
+		default:
+			h.logger.Error("failed to resolve chat grant", slog.Any("error", err))
+			Error(w, http.StatusInternalServerError, "Failed to generate token")
+		}
+		return
+	}
+	if len(grant.Permissions) == 0 {
+		Error(w, http.StatusForbidden, "None of the requested permissions are granted")
+		return
 	}
 
-	token, err := h.signer.Sign(user.ID, user.Email, perms)
+	token, claims, err := h.tokens.Issue(r.Context(), userID, email, grant)
 	if err != nil {
-		h.logger.Error("failed to sign chat token", slog.Any("error", err))
+		h.logger.Error("failed to issue chat token", slog.Any("error", err))
 		Error(w, http.StatusInternalServerError, "Failed to generate token")
 		return
 	}
 
-	JSON(w, http.StatusOK, chatTokenResponse{Token: token})
+	JSON(w, http.StatusOK, chatTokenResponse{
+		Token:       token,
+		TokenID:     claims.ID,
+		ExpiresAt:   claims.Expiry().UTC(),
+		ChannelID:   channelID,
+		Role:        grant.Role,
+		Permissions: grant.Permissions,
+	})
+}
+
+type chatTokenBody struct {
+	Token string `json:"token"`
+}
+
+type chatTokenIntrospection struct {
+	Active bool             `json:"active"`
+	Claims *auth.ChatClaims `json:"claims,omitempty"`
+}
+
+// VerifyChatToken reports whether a chat token is valid and not revoked,
+// and returns its claims. Used by the chat service.
+// POST /api/v1/auth/chat-token/verify
+func (h *AuthHandler) VerifyChatToken(w http.ResponseWriter, r *http.Request) {
+	var req chatTokenBody
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		Error(w, http.StatusBadRequest, "token is required")
+		return
+	}
+
+	claims, err := h.tokens.Verify(r.Context(), req.Token)
+	if err != nil {
+		JSON(w, http.StatusOK, chatTokenIntrospection{Active: false})
+		return
+	}
+	JSON(w, http.StatusOK, chatTokenIntrospection{Active: true, Claims: claims})
+}
+
+// RevokeChatToken revokes a chat token, e.g. on sign-out. Holding the
+// token is sufficient.
+// POST /api/v1/auth/chat-token/revoke
+func (h *AuthHandler) RevokeChatToken(w http.ResponseWriter, r *http.Request) {
+	var req chatTokenBody
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		Error(w, http.StatusBadRequest, "token is required")
+		return
+	}
+
+	claims, err := h.tokens.VerifyRefresh(r.Context(), req.Token)
+	if err != nil {
+		// Invalid, expired and already revoked tokens need no revoking.
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if err := h.tokens.Revoke(r.Context(), claims, "revoked"); err != nil {
+		h.logger.Error("failed to revoke chat token", slog.Any("error", err))
+		Error(w, http.StatusInternalServerError, "Failed to revoke token")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ChatTokenRevocations lists revoked chat tokens that may still verify, so
+// services validating tokens locally can mirror the revocation list.
+// GET /api/v1/auth/chat-token/revocations?since=
+func (h *AuthHandler) ChatTokenRevocations(w http.ResponseWriter, r *http.Request) {
+	var since time.Time
+	if v := r.URL.Query().Get("since"); v != "" {
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			Error(w, http.StatusBadRequest, "since must be an RFC 3339 timestamp")
+			return
+		}
+		since = t
+	}
+
+	tokens, err := h.tokens.Revocations(r.Context(), since)
+	if err != nil {
+		h.logger.Error("failed to list chat token revocations", slog.Any("error", err))
+		Error(w, http.StatusInternalServerError, "Failed to retrieve revocations")
+		return
+	}
+
+	JSON(w, http.StatusOK, map[string]interface{}{"revocations": tokens})
 }
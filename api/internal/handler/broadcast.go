@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"chaseapp.tv/api/internal/model"
+	"chaseapp.tv/api/internal/push"
+)
+
+// BroadcastHandler handles operator-triggered push broadcasts.
+type BroadcastHandler struct {
+	dispatcher *push.Dispatcher
+	logger     *slog.Logger
+}
+
+// NewBroadcastHandler creates a new BroadcastHandler.
+func NewBroadcastHandler(dispatcher *push.Dispatcher, logger *slog.Logger) *BroadcastHandler {
+	return &BroadcastHandler{
+		dispatcher: dispatcher,
+		logger:     logger,
+	}
+}
+
+// BroadcastRequest is a manual notification and the audience to send it to.
+type BroadcastRequest struct {
+	Title    string          `json:"title"`
+	Body     string          `json:"body"`
+	ImageURL string          `json:"image_url,omitempty"`
+	ChaseID  *uuid.UUID      `json:"chase_id,omitempty"` // deep link target
+	Target   BroadcastTarget `json:"target"`
+	DryRun   bool            `json:"dry_run"`
+}
+
+// BroadcastTarget selects recipients; set criteria are combined.
+type BroadcastTarget struct {
+	Topic     string           `json:"topic,omitempty"`
+	Platforms []model.Platform `json:"platforms,omitempty"`
+	UserIDs   []uuid.UUID      `json:"user_ids,omitempty"`
+	Area      *BroadcastArea   `json:"area,omitempty"`
+}
+
+// BroadcastArea targets users whose home location is within RadiusKm.
+type BroadcastArea struct {
+	Lat      float64 `json:"lat"`
+	Lng      float64 `json:"lng"`
+	RadiusKm float64 `json:"radius_km"`
+}
+
+// Broadcast starts sending a manual push notification and returns the ID
+// its delivery receipts will be recorded under, or with dry_run reports how
+// many devices per platform it would reach.
+// POST /api/v1/admin/push/broadcast
+func (h *BroadcastHandler) Broadcast(w http.ResponseWriter, r *http.Request) {
+	var req BroadcastRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Error(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if msg := validateBroadcast(&req); msg != "" {
+		Error(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	n := push.Notification{
+		Title:    req.Title,
+		Body:     req.Body,
+		ImageURL: req.ImageURL,
+	}
+	if req.ChaseID != nil {
+		n.ChaseID = req.ChaseID.String()
+	}
+
+	target := push.BroadcastTarget{
+		Topic:     req.Target.Topic,
+		Platforms: req.Target.Platforms,
+		UserIDs:   req.Target.UserIDs,
+	}
+	if a := req.Target.Area; a != nil {
+		target.Area = &push.BroadcastArea{
+			Center:   model.Location{Lat: a.Lat, Lng: a.Lng},
+			RadiusKm: a.RadiusKm,
+		}
+	}
+
+	if req.DryRun {
+		preview, err := h.dispatcher.Preview(r.Context(), n, target)
+		if err != nil {
+			h.logger.Error("failed to resolve broadcast recipients", slog.Any("error", err))
+			Error(w, http.StatusInternalServerError, "Failed to resolve recipients")
+			return
+		}
+		JSON(w, http.StatusOK, preview)
+		return
+	}
+
+	id, err := h.dispatcher.StartBroadcast(r.Context(), n, target)
+	if err != nil {
+		h.logger.Error("failed to broadcast notification", slog.Any("error", err))
+		Error(w, http.StatusInternalServerError, "Failed to send notification")
+		return
+	}
+
+	h.logger.Info("manual broadcast started",
+		slog.String("topic", req.Target.Topic),
+		slog.String("notification_id", id.String()),
+	)
+	JSON(w, http.StatusAccepted, map[string]any{"notification_id": id})
+}
+
+// validateBroadcast returns a client-facing message for an invalid request.
+func validateBroadcast(req *BroadcastRequest) string {
+	if req.Title == "" || req.Body == "" {
+		return "Title and body are required"
+	}
+
+	t := req.Target
+	if t.Topic == "" && len(t.Platforms) == 0 && len(t.UserIDs) == 0 && t.Area == nil {
+		return "Target requires a topic, platforms, user_ids or area"
+	}
+	for _, p := range t.Platforms {
+		switch p {
+		case model.PlatformIOS, model.PlatformAndroid, model.PlatformWeb, model.PlatformSafari, push.PlatformNtfy:
+		default:
+			return "Invalid platform: " + string(p)
+		}
+	}
+	if a := t.Area; a != nil {
+		if a.Lat < -90 || a.Lat > 90 || a.Lng < -180 || a.Lng > 180 {
+			return "Invalid area coordinates"
+		}
+		if a.RadiusKm <= 0 {
+			return "Area radius_km must be positive"
+		}
+	}
+	return ""
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"chaseapp.tv/api/internal/model"
+)
+
+func TestValidateBroadcast(t *testing.T) {
+	valid := func() BroadcastRequest {
+		return BroadcastRequest{
+			Title:  "Pursuit in LA",
+			Body:   "Tune in now",
+			Target: BroadcastTarget{Topic: "chases"},
+		}
+	}
+
+	req := valid()
+	require.Empty(t, validateBroadcast(&req))
+
+	req = valid()
+	req.Body = ""
+	require.NotEmpty(t, validateBroadcast(&req))
+
+	req = valid()
+	req.Target = BroadcastTarget{}
+	require.NotEmpty(t, validateBroadcast(&req), "empty target must not mean everyone")
+
+	req = valid()
+	req.Target = BroadcastTarget{UserIDs: []uuid.UUID{uuid.New()}, Platforms: []model.Platform{"pager"}}
+	require.Equal(t, "Invalid platform: pager", validateBroadcast(&req))
+
+	req = valid()
+	req.Target = BroadcastTarget{Area: &BroadcastArea{Lat: 34.05, Lng: -118.24}}
+	require.NotEmpty(t, validateBroadcast(&req))
+	req.Target.Area.RadiusKm = 50
+	require.Empty(t, validateBroadcast(&req))
+}
@@ -73,6 +73,14 @@ func (h *ChaseHandler) List(w http.ResponseWriter, r *http.Request) {
 		opts.State = state
 	}
 
+	if draft := r.URL.Query().Get("draft"); draft == "true" || draft == "1" {
+		if !middleware.HasRole(ctx, model.RoleAdmin, model.RoleModerator) {
+			Error(w, http.StatusForbidden, "Drafts are only visible to staff")
+			return
+		}
+		opts.Drafts = true
+	}
+
 	result, err := h.repo.List(ctx, opts)
 	if err != nil {
 		h.logger.Error("failed to list chases", slog.Any("error", err))
@@ -127,9 +135,12 @@ func (h *ChaseHandler) Create(w http.ResponseWriter, r *http.Request) {
 		slog.String("title", chase.Title),
 	)
 
-	h.publishChaseEvent(realtime.SubjectChaseCreated, chase)
-	if chase.Live {
-		h.publishChaseEvent(realtime.SubjectChaseLive, chase)
+	// Drafts are announced when they are published.
+	if !chase.Draft {
+		h.publishChaseEvent(realtime.SubjectChaseCreated, chase)
+		if chase.Live {
+			h.publishChaseEvent(realtime.SubjectChaseLive, chase)
+		}
 	}
 
 	JSON(w, http.StatusCreated, chase)
@@ -160,6 +171,10 @@ func (h *ChaseHandler) Get(w http.ResponseWriter, r *http.Request) {
 		Error(w, http.StatusInternalServerError, "Failed to retrieve chase")
 		return
 	}
+	if chase.Draft && !middleware.HasRole(ctx, model.RoleAdmin, model.RoleModerator) {
+		Error(w, http.StatusNotFound, "Chase not found")
+		return
+	}
 
 	// Optionally increment view count
 	if r.URL.Query().Get("track_view") == "true" {
@@ -194,6 +209,15 @@ func (h *ChaseHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Publishing a draft announces it as a new chase.
+	wasDraft := false
+	if input.Draft != nil && !*input.Draft {
+		current, err := h.repo.GetByID(ctx, id)
+		if err == nil {
+			wasDraft = current.Draft
+		}
+	}
+
 	chase, wasLive, err := h.repo.Update(ctx, id, input)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
@@ -213,14 +237,24 @@ func (h *ChaseHandler) Update(w http.ResponseWriter, r *http.Request) {
 		slog.Bool("live", chase.Live),
 	)
 
-	h.publishChaseEvent(realtime.SubjectChaseUpdated, chase)
+	switch {
+	case chase.Draft:
+		// Drafts stay private until published.
+	case wasDraft:
+		h.publishChaseEvent(realtime.SubjectChaseCreated, chase)
+		if chase.Live {
+			h.publishChaseEvent(realtime.SubjectChaseLive, chase)
+		}
+	default:
+		h.publishChaseEvent(realtime.SubjectChaseUpdated, chase)
 
-	if !wasLive && chase.Live {
-		h.publishChaseEvent(realtime.SubjectChaseLive, chase)
-	}
+		if !wasLive && chase.Live {
+			h.publishChaseEvent(realtime.SubjectChaseLive, chase)
+		}
 
-	if wasLive && !chase.Live {
-		h.publishChaseEvent(realtime.SubjectChaseEnded, chase)
+		if wasLive && !chase.Live {
+			h.publishChaseEvent(realtime.SubjectChaseEnded, chase)
+		}
 	}
 
 	JSON(w, http.StatusOK, chase)
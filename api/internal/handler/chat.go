@@ -0,0 +1,266 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"chaseapp.tv/api/internal/chat"
+	"chaseapp.tv/api/internal/middleware"
+	"chaseapp.tv/api/internal/model"
+	"chaseapp.tv/api/internal/repository"
+)
+
+// ChatHandler handles chat roles and moderation.
+type ChatHandler struct {
+	repo   *repository.ChatRepository
+	policy *chat.Policy
+	tokens *chat.Tokens
+	logger *slog.Logger
+}
+
+// NewChatHandler creates a new ChatHandler.
+func NewChatHandler(repo *repository.ChatRepository, policy *chat.Policy, tokens *chat.Tokens, logger *slog.Logger) *ChatHandler {
+	return &ChatHandler{
+		repo:   repo,
+		policy: policy,
+		tokens: tokens,
+		logger: logger,
+	}
+}
+
+// Moderate mutes or bans a user in a channel, or everywhere with global.
+// Moderators may only act on users with a lower chat role, and global
+// sanctions require a chat admin. The user's affected chat tokens are
+// revoked so the sanction applies before they expire.
+// POST /api/v1/chat/channels/{id}/moderation
+func (h *ChatHandler) Moderate(w http.ResponseWriter, r *http.Request) {
+	moderatorID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+	channelID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, http.StatusBadRequest, "Invalid channel ID")
+		return
+	}
+
+	var input model.CreateChatModerationInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		Error(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if input.UserID == uuid.Nil {
+		Error(w, http.StatusBadRequest, "user_id is required")
+		return
+	}
+	if input.Action != model.ChatSanctionMute && input.Action != model.ChatSanctionBan {
+		Error(w, http.StatusBadRequest, "action must be mute or ban")
+		return
+	}
+	var expiresAt *time.Time
+	if input.Duration != "" {
+		d, err := time.ParseDuration(input.Duration)
+		if err != nil || d <= 0 {
+			Error(w, http.StatusBadRequest, "Invalid duration")
+			return
+		}
+		t := time.Now().Add(d)
+		expiresAt = &t
+	}
+
+	role, ok := h.requireModerator(w, r, moderatorID)
+	if !ok {
+		return
+	}
+	if input.Global && role != model.ChatRoleAdmin {
+		Error(w, http.StatusForbidden, "Global sanctions require a chat admin")
+		return
+	}
+
+	if _, err := h.repo.GetChannel(r.Context(), channelID); err != nil {
+		h.chatError(w, err, "Channel not found")
+		return
+	}
+	targetRole, err := h.policy.UserRole(r.Context(), input.UserID)
+	if err != nil {
+		h.chatError(w, err, "User not found")
+		return
+	}
+	if targetRole.Rank() >= role.Rank() {
+		Error(w, http.StatusForbidden, "Cannot moderate a user with an equal or higher chat role")
+		return
+	}
+
+	action := &model.ChatModerationAction{
+		UserID:      input.UserID,
+		Action:      input.Action,
+		Reason:      input.Reason,
+		ModeratorID: &moderatorID,
+		ExpiresAt:   expiresAt,
+	}
+	if !input.Global {
+		action.ChannelID = &channelID
+	}
+	if err := h.repo.CreateModeration(r.Context(), action); err != nil {
+		h.logger.Error("failed to record moderation action", slog.Any("error", err))
+		Error(w, http.StatusInternalServerError, "Failed to moderate user")
+		return
+	}
+
+	revoked, err := h.tokens.RevokeUser(r.Context(), action.UserID, action.ChannelID, string(action.Action))
+	if err != nil {
+		h.logger.Error("failed to revoke chat tokens", slog.Any("error", err), slog.String("user_id", action.UserID.String()))
+	}
+
+	h.logger.Info("chat moderation",
+		slog.String("action", string(action.Action)),
+		slog.Int("tokens_revoked", revoked),
+		slog.String("user_id", action.UserID.String()),
+		slog.String("channel_id", channelID.String()),
+		slog.Bool("global", input.Global),
+		slog.String("by", moderatorID.String()),
+	)
+	JSON(w, http.StatusCreated, action)
+}
+
+// ListModeration returns a channel's moderation history.
+// GET /api/v1/chat/channels/{id}/moderation
+func (h *ChatHandler) ListModeration(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+	channelID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, http.StatusBadRequest, "Invalid channel ID")
+		return
+	}
+	if _, ok := h.requireModerator(w, r, userID); !ok {
+		return
+	}
+
+	actions, err := h.repo.ListModeration(r.Context(), channelID)
+	if err != nil {
+		h.logger.Error("failed to list moderation actions", slog.Any("error", err))
+		Error(w, http.StatusInternalServerError, "Failed to retrieve moderation actions")
+		return
+	}
+
+	JSON(w, http.StatusOK, map[string]interface{}{"actions": actions})
+}
+
+// LiftModeration ends a mute or ban. As with Moderate, global sanctions
+// require a chat admin, and only the issuing moderator or a user with a
+// higher chat role may lift a sanction.
+// DELETE /api/v1/chat/moderation/{id}
+func (h *ChatHandler) LiftModeration(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, http.StatusBadRequest, "Invalid moderation ID")
+		return
+	}
+	role, ok := h.requireModerator(w, r, userID)
+	if !ok {
+		return
+	}
+
+	existing, err := h.repo.GetModeration(r.Context(), id)
+	if err != nil {
+		h.chatError(w, err, "Moderation action not found")
+		return
+	}
+	if existing.ChannelID == nil && role != model.ChatRoleAdmin {
+		Error(w, http.StatusForbidden, "Global sanctions require a chat admin")
+		return
+	}
+	if existing.ModeratorID != nil && *existing.ModeratorID != userID {
+		issuerRole, err := h.policy.UserRole(r.Context(), *existing.ModeratorID)
+		if errors.Is(err, repository.ErrNotFound) {
+			// Sanctions outlive deleted moderator accounts; leave them to admins.
+			issuerRole, err = model.ChatRoleModerator, nil
+		}
+		if err != nil {
+			h.chatError(w, err, "Moderator not found")
+			return
+		}
+		if issuerRole.Rank() >= role.Rank() {
+			Error(w, http.StatusForbidden, "Cannot lift a sanction issued by an equal or higher chat role")
+			return
+		}
+	}
+
+	action, err := h.repo.LiftModeration(r.Context(), id)
+	if err != nil {
+		h.chatError(w, err, "Moderation action not found")
+		return
+	}
+
+	h.logger.Info("chat moderation lifted", slog.String("id", id.String()), slog.String("by", userID.String()))
+	JSON(w, http.StatusOK, action)
+}
+
+type chatRoleRequest struct {
+	Role model.ChatRole `json:"role"`
+}
+
+// SetRole changes a user's chat role.
+// PUT /api/v1/admin/users/{id}/chat-role
+func (h *ChatHandler) SetRole(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	var req chatRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Error(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if !req.Role.Valid() {
+		Error(w, http.StatusBadRequest, "Invalid chat role")
+		return
+	}
+
+	if err := h.repo.SetRole(r.Context(), id, req.Role); err != nil {
+		h.chatError(w, err, "User not found")
+		return
+	}
+
+	h.logger.Info("chat role set", slog.String("user_id", id.String()), slog.String("role", string(req.Role)), slog.String("by", actingUserID(r)))
+	JSON(w, http.StatusOK, map[string]interface{}{"user_id": id, "chat_role": req.Role})
+}
+
+// requireModerator resolves the caller's effective chat role and rejects
+// callers below moderator.
+func (h *ChatHandler) requireModerator(w http.ResponseWriter, r *http.Request, userID uuid.UUID) (model.ChatRole, bool) {
+	role, err := h.policy.Role(r.Context(), userID, middleware.RolesFromContext(r.Context()))
+	if err != nil {
+		h.chatError(w, err, "User not found")
+		return "", false
+	}
+	if role.Rank() < model.ChatRoleModerator.Rank() {
+		Error(w, http.StatusForbidden, "Chat moderator role required")
+		return "", false
+	}
+	return role, true
+}
+
+func (h *ChatHandler) chatError(w http.ResponseWriter, err error, notFound string) {
+	if errors.Is(err, repository.ErrNotFound) {
+		Error(w, http.StatusNotFound, notFound)
+		return
+	}
+	h.logger.Error("chat request failed", slog.Any("error", err))
+	Error(w, http.StatusInternalServerError, "Internal server error")
+}
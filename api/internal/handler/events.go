@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"chaseapp.tv/api/internal/model"
+	"chaseapp.tv/api/internal/realtime"
+)
+
+// sseRetry is the reconnection delay suggested to EventSource clients.
+const sseRetry = 3 * time.Second
+
+// EventsHandler streams chase events to browsers over Server-Sent Events.
+type EventsHandler struct {
+	broker    *realtime.ChaseBroker
+	heartbeat time.Duration
+	logger    *slog.Logger
+}
+
+// NewEventsHandler creates a new EventsHandler.
+func NewEventsHandler(broker *realtime.ChaseBroker, heartbeat time.Duration, logger *slog.Logger) *EventsHandler {
+	if heartbeat <= 0 {
+		heartbeat = 15 * time.Second
+	}
+	return &EventsHandler{
+		broker:    broker,
+		heartbeat: heartbeat,
+		logger:    logger,
+	}
+}
+
+// chaseEventFilter selects events by chase type and state. Empty lists
+// match everything. The type may also be given as type, like the list
+// endpoint.
+type chaseEventFilter struct {
+	types  []model.ChaseType
+	states []string
+}
+
+func parseChaseEventFilter(r *http.Request) chaseEventFilter {
+	q := r.URL.Query()
+	types := q.Get("chase_type")
+	if types == "" {
+		types = q.Get("type")
+	}
+
+	var f chaseEventFilter
+	for _, t := range splitList(types) {
+		f.types = append(f.types, model.ChaseType(t))
+	}
+	for _, s := range splitList(q.Get("state")) {
+		f.states = append(f.states, strings.ToUpper(s))
+	}
+	return f
+}
+
+func (f chaseEventFilter) match(chase *model.Chase) bool {
+	if chase == nil {
+		return len(f.types) == 0 && len(f.states) == 0
+	}
+	if len(f.types) > 0 && !slices.Contains(f.types, chase.ChaseType) {
+		return false
+	}
+	if len(f.states) > 0 && !slices.Contains(f.states, strings.ToUpper(chase.State)) {
+		return false
+	}
+	return true
+}
+
+func splitList(v string) []string {
+	var out []string
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// lastEventID reads the sequence to resume after from the Last-Event-ID
+// header, or the last_event_id query parameter for the first connection.
+func lastEventID(r *http.Request) uint64 {
+	v := r.Header.Get("Last-Event-ID")
+	if v == "" {
+		v = r.URL.Query().Get("last_event_id")
+	}
+	seq, _ := strconv.ParseUint(v, 10, 64)
+	return seq
+}
+
+// Stream relays chase events as Server-Sent Events, filtered by chase_type
+// and state (comma-separated). Event IDs are chases stream sequences; a
+// reconnecting client's Last-Event-ID replays what it missed.
+// GET /api/v1/chases/events
+func (h *EventsHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	if h.broker == nil {
+		Error(w, http.StatusServiceUnavailable, "Event stream unavailable")
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	// The server write timeout would otherwise end the stream.
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		h.logger.Debug("failed to clear write deadline", slog.Any("error", err))
+	}
+
+	filter := parseChaseEventFilter(r)
+	after := lastEventID(r)
+
+	// Subscribe before replaying so nothing published meanwhile is missed;
+	// live events already replayed are skipped by sequence.
+	events, unsubscribe := h.broker.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(evt realtime.ChaseStreamEvent) error {
+		if !filter.match(evt.Chase) {
+			return nil
+		}
+		data, err := json.Marshal(evt.ChaseEvent)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", evt.Seq, evt.Event, data); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", sseRetry.Milliseconds()); err != nil {
+		return
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	ctx := r.Context()
+	if after > 0 {
+		last, err := h.broker.Replay(ctx, after, send)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			h.logger.Warn("chase event replay failed", slog.Any("error", err), slog.Uint64("after", after))
+		}
+		after = last
+	}
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		case evt, ok := <-events:
+			if !ok {
+				// Dropped for falling behind, or shutting down; the client
+				// reconnects with its Last-Event-ID.
+				return
+			}
+			if evt.Seq <= after {
+				continue
+			}
+			if err := send(evt); err != nil {
+				return
+			}
+		}
+	}
+}
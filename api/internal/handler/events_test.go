@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"chaseapp.tv/api/internal/model"
+)
+
+func TestChaseEventFilter(t *testing.T) {
+	r := httptest.NewRequest("GET", "/api/v1/chases/events?chase_type=chase,aircraft&state=ca", nil)
+	f := parseChaseEventFilter(r)
+
+	require.True(t, f.match(&model.Chase{ChaseType: model.ChaseTypeChase, State: "CA"}))
+	require.False(t, f.match(&model.Chase{ChaseType: model.ChaseTypeRocket, State: "CA"}))
+	require.False(t, f.match(&model.Chase{ChaseType: model.ChaseTypeAircraft, State: "TX"}))
+	require.False(t, f.match(nil))
+
+	all := parseChaseEventFilter(httptest.NewRequest("GET", "/api/v1/chases/events", nil))
+	require.True(t, all.match(&model.Chase{ChaseType: model.ChaseTypeWeather}))
+}
+
+func TestLastEventID(t *testing.T) {
+	r := httptest.NewRequest("GET", "/api/v1/chases/events?last_event_id=7", nil)
+	require.Equal(t, uint64(7), lastEventID(r))
+
+	r.Header.Set("Last-Event-ID", "42")
+	require.Equal(t, uint64(42), lastEventID(r))
+
+	r.Header.Set("Last-Event-ID", "bogus")
+	require.Zero(t, lastEventID(r))
+}
@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"chaseapp.tv/api/internal/middleware"
+	"chaseapp.tv/api/internal/model"
+	"chaseapp.tv/api/internal/repository"
+)
+
+// NotificationHandler handles notification history and inbox requests.
+type NotificationHandler struct {
+	repo   *repository.NotificationRepository
+	logger *slog.Logger
+}
+
+// NewNotificationHandler creates a new NotificationHandler.
+func NewNotificationHandler(repo *repository.NotificationRepository, logger *slog.Logger) *NotificationHandler {
+	return &NotificationHandler{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// List returns the global feed of sent notifications. Staff can pass
+// ?private=true to include broadcasts addressed to chosen users or an area.
+// GET /api/v1/notifications
+func (h *NotificationHandler) List(w http.ResponseWriter, r *http.Request) {
+	opts := notificationListOptions(r)
+	opts.Topic = r.URL.Query().Get("topic")
+	if chaseID := r.URL.Query().Get("chase_id"); chaseID != "" {
+		id, err := uuid.Parse(chaseID)
+		if err != nil {
+			Error(w, http.StatusBadRequest, "Invalid chase ID")
+			return
+		}
+		opts.ChaseID = &id
+	}
+	if private := r.URL.Query().Get("private"); private == "true" || private == "1" {
+		if !middleware.HasRole(r.Context(), model.RoleAdmin, model.RoleModerator) {
+			Error(w, http.StatusForbidden, "Private notifications are only visible to staff")
+			return
+		}
+		opts.Private = true
+	}
+
+	result, err := h.repo.List(r.Context(), opts)
+	if err != nil {
+		h.logger.Error("failed to list notifications", slog.Any("error", err))
+		Error(w, http.StatusInternalServerError, "Failed to retrieve notifications")
+		return
+	}
+
+	JSON(w, http.StatusOK, result)
+}
+
+// Get returns a single notification with its per-platform delivery counts.
+// GET /api/v1/notifications/{id}
+func (h *NotificationHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, http.StatusBadRequest, "Invalid notification ID")
+		return
+	}
+
+	notification, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			Error(w, http.StatusNotFound, "Notification not found")
+			return
+		}
+		h.logger.Error("failed to get notification", slog.Any("error", err), slog.String("id", id.String()))
+		Error(w, http.StatusInternalServerError, "Failed to retrieve notification")
+		return
+	}
+	if notification.Private && !middleware.HasRole(r.Context(), model.RoleAdmin, model.RoleModerator) {
+		Error(w, http.StatusNotFound, "Notification not found")
+		return
+	}
+
+	JSON(w, http.StatusOK, notification)
+}
+
+// Inbox returns the notifications sent to the current user.
+// GET /api/v1/me/notifications
+func (h *NotificationHandler) Inbox(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	opts := notificationListOptions(r)
+	if unread := r.URL.Query().Get("unread"); unread == "true" || unread == "1" {
+		opts.UnreadOnly = true
+	}
+
+	result, err := h.repo.ListInbox(r.Context(), userID, opts)
+	if err != nil {
+		h.logger.Error("failed to list inbox", slog.Any("error", err))
+		Error(w, http.StatusInternalServerError, "Failed to retrieve notifications")
+		return
+	}
+
+	JSON(w, http.StatusOK, result)
+}
+
+// MarkRead marks an inbox notification as read.
+// POST /api/v1/me/notifications/{id}/read
+func (h *NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	h.setRead(w, r, true)
+}
+
+// MarkUnread marks an inbox notification as unread.
+// DELETE /api/v1/me/notifications/{id}/read
+func (h *NotificationHandler) MarkUnread(w http.ResponseWriter, r *http.Request) {
+	h.setRead(w, r, false)
+}
+
+// MarkAllRead marks the current user's whole inbox as read.
+// POST /api/v1/me/notifications/read
+func (h *NotificationHandler) MarkAllRead(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	updated, err := h.repo.MarkAllRead(r.Context(), userID)
+	if err != nil {
+		h.logger.Error("failed to mark notifications read", slog.Any("error", err))
+		Error(w, http.StatusInternalServerError, "Failed to update notifications")
+		return
+	}
+
+	JSON(w, http.StatusOK, map[string]int64{"updated": updated})
+}
+
+func (h *NotificationHandler) setRead(w http.ResponseWriter, r *http.Request, read bool) {
+	userID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, http.StatusBadRequest, "Invalid notification ID")
+		return
+	}
+
+	if err := h.repo.SetRead(r.Context(), userID, id, read); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			Error(w, http.StatusNotFound, "Notification not found")
+			return
+		}
+		h.logger.Error("failed to update read state", slog.Any("error", err), slog.String("id", id.String()))
+		Error(w, http.StatusInternalServerError, "Failed to update notification")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func notificationListOptions(r *http.Request) model.NotificationListOptions {
+	opts := model.NotificationListOptions{
+		Page:  1,
+		Limit: 20,
+	}
+	if page := r.URL.Query().Get("page"); page != "" {
+		if p, err := strconv.Atoi(page); err == nil && p > 0 {
+			opts.Page = p
+		}
+	}
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		if l, err := strconv.Atoi(limit); err == nil && l > 0 && l <= 100 {
+			opts.Limit = l
+		}
+	}
+	return opts
+}
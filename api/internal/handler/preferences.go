@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"chaseapp.tv/api/internal/middleware"
+	"chaseapp.tv/api/internal/model"
+	"chaseapp.tv/api/internal/repository"
+)
+
+// NotificationPreferencesHandler handles the current user's notification preferences.
+type NotificationPreferencesHandler struct {
+	repo   *repository.NotificationPreferencesRepository
+	logger *slog.Logger
+}
+
+// NewNotificationPreferencesHandler creates a new NotificationPreferencesHandler.
+func NewNotificationPreferencesHandler(repo *repository.NotificationPreferencesRepository, logger *slog.Logger) *NotificationPreferencesHandler {
+	return &NotificationPreferencesHandler{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// Get returns the current user's notification preferences.
+// GET /api/v1/me/notification-preferences
+func (h *NotificationPreferencesHandler) Get(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	prefs, err := h.repo.Get(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			Error(w, http.StatusNotFound, "User not found")
+			return
+		}
+		h.logger.Error("failed to get notification preferences", slog.Any("error", err))
+		Error(w, http.StatusInternalServerError, "Failed to retrieve notification preferences")
+		return
+	}
+
+	JSON(w, http.StatusOK, prefs)
+}
+
+// Update replaces the current user's notification preferences.
+// PUT /api/v1/me/notification-preferences
+func (h *NotificationPreferencesHandler) Update(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var input model.UpdateNotificationPreferencesInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		Error(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if msg := validateNotificationPreferences(&input); msg != "" {
+		Error(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	prefs, err := h.repo.Upsert(r.Context(), userID, input)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			Error(w, http.StatusNotFound, "User not found")
+			return
+		}
+		h.logger.Error("failed to update notification preferences", slog.Any("error", err))
+		Error(w, http.StatusInternalServerError, "Failed to update notification preferences")
+		return
+	}
+
+	JSON(w, http.StatusOK, prefs)
+}
+
+// Delete resets the current user's notification preferences to the defaults.
+// DELETE /api/v1/me/notification-preferences
+func (h *NotificationPreferencesHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Delete(r.Context(), userID); err != nil {
+		h.logger.Error("failed to delete notification preferences", slog.Any("error", err))
+		Error(w, http.StatusInternalServerError, "Failed to reset notification preferences")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// validateNotificationPreferences checks input and normalises states to
+// upper case. It returns a client-facing message, or "" when valid.
+func validateNotificationPreferences(input *model.UpdateNotificationPreferencesInput) string {
+	validTypes := []model.ChaseType{
+		model.ChaseTypeChase, model.ChaseTypeRocket, model.ChaseTypeWeather, model.ChaseTypeAircraft,
+	}
+	for _, t := range input.ChaseTypes {
+		if !slices.Contains(validTypes, t) {
+			return "Invalid chase type: " + string(t)
+		}
+	}
+
+	if loc := input.HomeLocation; loc != nil {
+		if loc.Lat < -90 || loc.Lat > 90 || loc.Lng < -180 || loc.Lng > 180 {
+			return "Invalid home location"
+		}
+	}
+	if input.RadiusKm < 0 {
+		return "Radius must not be negative"
+	}
+	if input.RadiusKm > 0 && input.HomeLocation == nil {
+		return "Home location is required with a radius"
+	}
+
+	for i, s := range input.States {
+		input.States[i] = strings.ToUpper(strings.TrimSpace(s))
+	}
+
+	if q := input.QuietHours; q != nil {
+		if _, err := time.Parse("15:04", q.Start); err != nil {
+			return "Quiet hours start must be HH:MM"
+		}
+		if _, err := time.Parse("15:04", q.End); err != nil {
+			return "Quiet hours end must be HH:MM"
+		}
+		if q.Timezone == "" {
+			q.Timezone = "UTC"
+		}
+		if _, err := time.LoadLocation(q.Timezone); err != nil {
+			return "Invalid timezone"
+		}
+	}
+
+	return ""
+}
+
+// currentUserID returns the authenticated user's ID, writing an error
+// response when it is missing or malformed.
+func currentUserID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	user, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		Error(w, http.StatusUnauthorized, "Authentication required")
+		return uuid.Nil, false
+	}
+	id, err := uuid.Parse(user.ID)
+	if err != nil {
+		Error(w, http.StatusUnauthorized, "Invalid user ID")
+		return uuid.Nil, false
+	}
+	return id, true
+}
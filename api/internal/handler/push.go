@@ -1,12 +1,19 @@
 package handler
 
 import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"io"
 	"log/slog"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/gorilla/mux"
 
 	"chaseapp.tv/api/internal/config"
 	"chaseapp.tv/api/internal/middleware"
@@ -19,28 +26,46 @@ import (
 type PushHandler struct {
 	tokenRepo *repository.PushTokenRepository
 	userRepo  *repository.UserRepository
+	safari    *push.SafariPackager
 	cfg       config.PushConfig
 	logger    *slog.Logger
 }
 
 // NewPushHandler creates a new PushHandler.
 func NewPushHandler(tokenRepo *repository.PushTokenRepository, userRepo *repository.UserRepository, cfg config.PushConfig, logger *slog.Logger) *PushHandler {
-	return &PushHandler{
+	h := &PushHandler{
 		tokenRepo: tokenRepo,
 		userRepo:  userRepo,
 		cfg:       cfg,
 		logger:    logger,
 	}
+
+	if p, err := push.NewSafariPackager(cfg); err != nil {
+		logger.Warn("safari push packages disabled", slog.Any("error", err))
+	} else {
+		h.safari = p
+	}
+
+	return h
 }
 
-// SubscribeRequest represents a push subscription request.
+// SubscribeRequest represents a push subscription request. Web clients may
+// send their PushSubscription JSON (endpoint and keys) instead of a token.
 type SubscribeRequest struct {
-	Token      string   `json:"token"`
-	Platform   string   `json:"platform"`
-	DeviceID   string   `json:"device_id,omitempty"`
-	DeviceName string   `json:"device_name,omitempty"`
-	AppVersion string   `json:"app_version,omitempty"`
-	Topics     []string `json:"topics,omitempty"`
+	Token      string       `json:"token"`
+	Platform   string       `json:"platform"`
+	DeviceID   string       `json:"device_id,omitempty"`
+	DeviceName string       `json:"device_name,omitempty"`
+	AppVersion string       `json:"app_version,omitempty"`
+	Topics     []string     `json:"topics,omitempty"`
+	Endpoint   string       `json:"endpoint,omitempty"`
+	Keys       *WebPushKeys `json:"keys,omitempty"`
+}
+
+// WebPushKeys are the encryption keys of a browser PushSubscription.
+type WebPushKeys struct {
+	P256dh string `json:"p256dh"`
+	Auth   string `json:"auth"`
 }
 
 // Subscribe registers a device for push notifications.
@@ -54,11 +79,6 @@ func (h *PushHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.Token == "" {
-		Error(w, http.StatusBadRequest, "Token is required")
-		return
-	}
-
 	platform := model.Platform(req.Platform)
 	if platform != model.PlatformIOS && platform != model.PlatformAndroid &&
 		platform != model.PlatformWeb && platform != model.PlatformSafari {
@@ -66,6 +86,31 @@ func (h *PushHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Web push subscriptions are addressed by their endpoint URL.
+	var metadata map[string]interface{}
+	if platform == model.PlatformWeb {
+		if req.Token == "" {
+			req.Token = req.Endpoint
+		}
+		if err := push.ValidateWebPushEndpoint(req.Token); err != nil {
+			Error(w, http.StatusBadRequest, "Endpoint must be an https URL on a known push service")
+			return
+		}
+		if req.Keys == nil || req.Keys.P256dh == "" || req.Keys.Auth == "" {
+			Error(w, http.StatusBadRequest, "Keys p256dh and auth are required")
+			return
+		}
+		metadata = map[string]interface{}{
+			model.WebPushKeyP256dh: req.Keys.P256dh,
+			model.WebPushKeyAuth:   req.Keys.Auth,
+		}
+	}
+
+	if req.Token == "" {
+		Error(w, http.StatusBadRequest, "Token is required")
+		return
+	}
+
 	// Get user ID from context if authenticated
 	var userID *uuid.UUID
 	if userIDStr, ok := ctx.Value(middleware.UserIDKey).(string); ok && userIDStr != "" {
@@ -81,6 +126,7 @@ func (h *PushHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
 		DeviceName: req.DeviceName,
 		AppVersion: req.AppVersion,
 		Topics:     req.Topics,
+		Metadata:   metadata,
 	}
 
 	token, err := h.tokenRepo.Create(ctx, userID, input)
@@ -108,6 +154,7 @@ func (h *PushHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
 // UnsubscribeRequest represents a push unsubscription request.
 type UnsubscribeRequest struct {
 	Token    string   `json:"token"`
+	Endpoint string   `json:"endpoint,omitempty"` // web push alternative to token
 	Platform string   `json:"platform"`
 	Topics   []string `json:"topics,omitempty"` // If empty, fully unsubscribe
 }
@@ -123,6 +170,9 @@ func (h *PushHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.Token == "" {
+		req.Token = req.Endpoint
+	}
 	if req.Token == "" {
 		Error(w, http.StatusBadRequest, "Token is required")
 		return
@@ -172,10 +222,92 @@ func (h *PushHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// GetSafariPushPackage generates a Safari push notification package.
+// GetSafariPushPackage generates a signed Safari push package for the
+// current user.
 // GET /api/v1/push/safari-package
 func (h *PushHandler) GetSafariPushPackage(w http.ResponseWriter, r *http.Request) {
-	zipBytes, err := push.BuildSafariPackage(h.cfg)
+	var userID *uuid.UUID
+	if user, ok := middleware.UserFromContext(r.Context()); ok {
+		if uid, err := uuid.Parse(user.ID); err == nil {
+			userID = &uid
+		}
+	}
+	h.writeSafariPackage(w, userID)
+}
+
+// GetSafariUserInfo returns the userInfo a signed-in page passes to
+// window.safari.pushNotification.requestPermission, so the push package
+// Safari then requests is bound to the current user.
+// GET /api/v1/push/safari-user-info
+func (h *PushHandler) GetSafariUserInfo(w http.ResponseWriter, r *http.Request) {
+	if h.safari == nil {
+		Error(w, http.StatusNotFound, "Safari push is not configured")
+		return
+	}
+
+	user, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		Error(w, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+	userID, err := uuid.Parse(user.ID)
+	if err != nil {
+		Error(w, http.StatusUnauthorized, "Invalid user")
+		return
+	}
+
+	JSON(w, http.StatusOK, map[string]string{"token": h.safari.UserInfoToken(userID, time.Now())})
+}
+
+// SafariPushPackage serves the push package Safari requests when a page calls
+// window.safari.pushNotification.requestPermission. Safari sends no
+// credentials, only the page's userInfo as the JSON body; a package without a
+// userInfo token is anonymous.
+// POST /api/v1/push/safari/v2/pushPackages/{pushID}
+func (h *PushHandler) SafariPushPackage(w http.ResponseWriter, r *http.Request) {
+	if mux.Vars(r)["pushID"] != h.cfg.SafariPushID {
+		Error(w, http.StatusNotFound, "Unknown website push ID")
+		return
+	}
+	if h.safari == nil {
+		Error(w, http.StatusNotFound, "Safari push is not configured")
+		return
+	}
+
+	var userInfo struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&userInfo); err != nil && !errors.Is(err, io.EOF) {
+		Error(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	var userID *uuid.UUID
+	if userInfo.Token != "" {
+		uid, err := h.safari.ParseUserInfoToken(userInfo.Token, time.Now())
+		if err != nil {
+			Error(w, http.StatusUnauthorized, "Invalid user info token")
+			return
+		}
+		userID = &uid
+	}
+	h.writeSafariPackage(w, userID)
+}
+
+func (h *PushHandler) writeSafariPackage(w http.ResponseWriter, userID *uuid.UUID) {
+	if h.safari == nil {
+		Error(w, http.StatusNotFound, "Safari push is not configured")
+		return
+	}
+
+	authToken, err := h.safari.AuthToken(userID)
+	if err != nil {
+		h.logger.Error("failed to mint safari auth token", slog.Any("error", err))
+		Error(w, http.StatusInternalServerError, "Failed to build Safari push package")
+		return
+	}
+
+	zipBytes, err := h.safari.Build(authToken)
 	if err != nil {
 		h.logger.Error("failed to build safari package", slog.Any("error", err))
 		Error(w, http.StatusInternalServerError, "Failed to build Safari push package")
@@ -187,3 +319,149 @@ func (h *PushHandler) GetSafariPushPackage(w http.ResponseWriter, r *http.Reques
 	w.WriteHeader(http.StatusOK)
 	_, _ = w.Write(zipBytes)
 }
+
+// SafariRegisterDevice records the device token Safari issues once the user
+// grants permission. New devices are subscribed to chases; re-registering
+// keeps a device's topics.
+// POST /api/v1/push/safari/v2/devices/{deviceToken}/registrations/{pushID}
+func (h *PushHandler) SafariRegisterDevice(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	deviceToken, authToken, userID, ok := h.authorizeSafari(w, r)
+	if !ok {
+		return
+	}
+
+	topics := []string{"chases"}
+	existing, err := h.tokenRepo.GetByToken(ctx, deviceToken, model.PlatformSafari)
+	switch {
+	case err == nil:
+		topics = existing.SubscribedTopics
+	case !errors.Is(err, repository.ErrNotFound):
+		h.logger.Error("failed to get safari token", slog.Any("error", err))
+		Error(w, http.StatusInternalServerError, "Failed to register device")
+		return
+	}
+
+	token, err := h.tokenRepo.Create(ctx, userID, model.CreatePushTokenInput{
+		Token:    deviceToken,
+		Platform: model.PlatformSafari,
+		Topics:   topics,
+		Metadata: map[string]interface{}{
+			model.SafariAuthTokenHash: safariAuthTokenHash(authToken),
+		},
+	})
+	if err != nil {
+		h.logger.Error("failed to register safari token", slog.Any("error", err))
+		Error(w, http.StatusInternalServerError, "Failed to register device")
+		return
+	}
+
+	h.logger.Info("safari push token registered", slog.String("id", token.ID.String()))
+	w.WriteHeader(http.StatusOK)
+}
+
+// SafariUnregisterDevice deactivates a Safari device token when the user
+// revokes permission. Only the authentication token the device registered
+// with can unregister it.
+// DELETE /api/v1/push/safari/v2/devices/{deviceToken}/registrations/{pushID}
+func (h *PushHandler) SafariUnregisterDevice(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	deviceToken, authToken, _, ok := h.authorizeSafari(w, r)
+	if !ok {
+		return
+	}
+
+	existing, err := h.tokenRepo.GetByToken(ctx, deviceToken, model.PlatformSafari)
+	if errors.Is(err, repository.ErrNotFound) {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to get safari token", slog.Any("error", err))
+		Error(w, http.StatusInternalServerError, "Failed to unregister device")
+		return
+	}
+	registered, _ := existing.Metadata[model.SafariAuthTokenHash].(string)
+	if subtle.ConstantTimeCompare([]byte(registered), []byte(safariAuthTokenHash(authToken))) != 1 {
+		Error(w, http.StatusUnauthorized, "Authentication token does not match the registration")
+		return
+	}
+
+	if err := h.tokenRepo.DeactivateByToken(ctx, deviceToken, model.PlatformSafari); err != nil {
+		h.logger.Error("failed to deactivate safari token", slog.Any("error", err))
+		Error(w, http.StatusInternalServerError, "Failed to unregister device")
+		return
+	}
+
+	h.logger.Info("safari push token deactivated", slog.String("id", existing.ID.String()))
+	w.WriteHeader(http.StatusOK)
+}
+
+// SafariLog records errors Safari reports about the push package or web service.
+// POST /api/v1/push/safari/v2/log
+func (h *PushHandler) SafariLog(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Logs []string `json:"logs"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Error(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	for _, entry := range req.Logs {
+		h.logger.Warn("safari push log", slog.String("message", entry))
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// authorizeSafari validates the website push ID and the
+// "ApplePushNotifications <authenticationToken>" header on web service calls,
+// returning the device token, the authentication token and its user.
+func (h *PushHandler) authorizeSafari(w http.ResponseWriter, r *http.Request) (string, string, *uuid.UUID, bool) {
+	vars := mux.Vars(r)
+	if h.safari == nil || vars["pushID"] != h.cfg.SafariPushID {
+		Error(w, http.StatusNotFound, "Unknown website push ID")
+		return "", "", nil, false
+	}
+
+	authToken, ok := strings.CutPrefix(r.Header.Get("Authorization"), "ApplePushNotifications ")
+	if !ok {
+		Error(w, http.StatusUnauthorized, "Missing authentication token")
+		return "", "", nil, false
+	}
+	userID, err := h.safari.ParseAuthToken(authToken)
+	if err != nil {
+		Error(w, http.StatusUnauthorized, "Invalid authentication token")
+		return "", "", nil, false
+	}
+
+	return vars["deviceToken"], authToken, userID, true
+}
+
+// safariAuthTokenHash is stored with a Safari registration so later calls can
+// be checked against the authentication token it registered with.
+func safariAuthTokenHash(authToken string) string {
+	h := sha256.Sum256([]byte(authToken))
+	return hex.EncodeToString(h[:])
+}
+
+// GetVAPIDPublicKey returns the application server key browsers pass to
+// PushManager.subscribe.
+// GET /api/v1/push/vapid-public-key
+func (h *PushHandler) GetVAPIDPublicKey(w http.ResponseWriter, r *http.Request) {
+	if h.cfg.VAPIDPrivateKey == "" {
+		Error(w, http.StatusNotFound, "Web push is not configured")
+		return
+	}
+
+	key, err := push.VAPIDPublicKey(h.cfg.VAPIDPrivateKey)
+	if err != nil {
+		h.logger.Error("failed to derive vapid public key", slog.Any("error", err))
+		Error(w, http.StatusInternalServerError, "Failed to load VAPID key")
+		return
+	}
+
+	JSON(w, http.StatusOK, map[string]string{"public_key": key})
+}
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"chaseapp.tv/api/internal/middleware"
+	"chaseapp.tv/api/internal/model"
+	"chaseapp.tv/api/internal/repository"
+)
+
+// RoleHandler handles admin management of user roles.
+type RoleHandler struct {
+	users  *repository.UserRepository
+	logger *slog.Logger
+}
+
+// NewRoleHandler creates a new RoleHandler.
+func NewRoleHandler(users *repository.UserRepository, logger *slog.Logger) *RoleHandler {
+	return &RoleHandler{
+		users:  users,
+		logger: logger,
+	}
+}
+
+// RolesResponse lists a user's roles.
+type RolesResponse struct {
+	UserID uuid.UUID    `json:"user_id"`
+	Roles  []model.Role `json:"roles"`
+}
+
+// Get returns a user's roles.
+// GET /api/v1/admin/users/{id}/roles
+func (h *RoleHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	roles, err := h.users.GetRoles(r.Context(), id)
+	if err != nil {
+		h.roleError(w, err, id)
+		return
+	}
+
+	JSON(w, http.StatusOK, RolesResponse{UserID: id, Roles: roles})
+}
+
+// Grant gives a user a role.
+// PUT /api/v1/admin/users/{id}/roles/{role}
+func (h *RoleHandler) Grant(w http.ResponseWriter, r *http.Request) {
+	id, role, ok := roleParams(w, r)
+	if !ok {
+		return
+	}
+
+	roles, err := h.users.GrantRole(r.Context(), id, role)
+	if err != nil {
+		h.roleError(w, err, id)
+		return
+	}
+
+	h.logger.Info("role granted", slog.String("user_id", id.String()), slog.String("role", string(role)), slog.String("by", actingUserID(r)))
+	JSON(w, http.StatusOK, RolesResponse{UserID: id, Roles: roles})
+}
+
+// Revoke removes a role from a user. The base user role cannot be revoked,
+// and admins cannot revoke their own admin role.
+// DELETE /api/v1/admin/users/{id}/roles/{role}
+func (h *RoleHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	id, role, ok := roleParams(w, r)
+	if !ok {
+		return
+	}
+	if role == model.RoleUser {
+		Error(w, http.StatusBadRequest, "The user role cannot be revoked")
+		return
+	}
+	if role == model.RoleAdmin && actingUserID(r) == id.String() {
+		Error(w, http.StatusBadRequest, "Admins cannot revoke their own admin role")
+		return
+	}
+
+	roles, err := h.users.RevokeRole(r.Context(), id, role)
+	if err != nil {
+		h.roleError(w, err, id)
+		return
+	}
+
+	h.logger.Info("role revoked", slog.String("user_id", id.String()), slog.String("role", string(role)), slog.String("by", actingUserID(r)))
+	JSON(w, http.StatusOK, RolesResponse{UserID: id, Roles: roles})
+}
+
+func (h *RoleHandler) roleError(w http.ResponseWriter, err error, id uuid.UUID) {
+	if errors.Is(err, repository.ErrNotFound) {
+		Error(w, http.StatusNotFound, "User not found")
+		return
+	}
+	h.logger.Error("failed to update user roles", slog.Any("error", err), slog.String("user_id", id.String()))
+	Error(w, http.StatusInternalServerError, "Failed to update user roles")
+}
+
+func roleParams(w http.ResponseWriter, r *http.Request) (uuid.UUID, model.Role, bool) {
+	vars := mux.Vars(r)
+	id, err := uuid.Parse(vars["id"])
+	if err != nil {
+		Error(w, http.StatusBadRequest, "Invalid user ID")
+		return uuid.Nil, "", false
+	}
+	role := model.Role(vars["role"])
+	if !role.Valid() {
+		Error(w, http.StatusBadRequest, "Invalid role")
+		return uuid.Nil, "", false
+	}
+	return id, role, true
+}
+
+func actingUserID(r *http.Request) string {
+	if user, ok := middleware.UserFromContext(r.Context()); ok {
+		return user.ID
+	}
+	return ""
+}
@@ -0,0 +1,253 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/mail"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"chaseapp.tv/api/internal/model"
+	"chaseapp.tv/api/internal/realtime"
+	"chaseapp.tv/api/internal/repository"
+)
+
+const (
+	// provisionCacheTTL bounds how long a subject-to-user mapping is reused
+	// before the account is looked up again. Cached users are still checked
+	// for deletion on every request.
+	provisionCacheTTL = 5 * time.Minute
+	// provisionCacheSize bounds the number of cached mappings.
+	provisionCacheSize = 10000
+)
+
+// UserHandler handles the current user's account.
+type UserHandler struct {
+	users     *repository.UserRepository
+	tokens    *repository.PushTokenRepository
+	publisher *realtime.Publisher
+	logger    *slog.Logger
+
+	mu    sync.Mutex
+	known map[string]provisioned // subject -> user
+}
+
+type provisioned struct {
+	userID  string
+	expires time.Time
+}
+
+// NewUserHandler creates a new UserHandler.
+func NewUserHandler(users *repository.UserRepository, tokens *repository.PushTokenRepository, publisher *realtime.Publisher, logger *slog.Logger) *UserHandler {
+	return &UserHandler{
+		users:     users,
+		tokens:    tokens,
+		publisher: publisher,
+		logger:    logger,
+		known:     map[string]provisioned{},
+	}
+}
+
+// Provision returns the user ID for an authenticated subject, creating the
+// account on first login and publishing users.created. A subject that is
+// already a user ID, as Kong forwards it, is used as is.
+func (h *UserHandler) Provision(ctx context.Context, subject, email, provider string) (string, error) {
+	if userID, ok := h.lookup(subject); ok {
+		// Another replica may have deleted the account since it was
+		// cached; treat that as a miss so the lookup below reports it.
+		exists, err := h.users.Exists(ctx, uuid.MustParse(userID))
+		if err != nil {
+			return "", err
+		}
+		if exists {
+			return userID, nil
+		}
+		h.forget(userID)
+	}
+
+	if id, err := uuid.Parse(subject); err == nil {
+		_, err := h.users.GetByID(ctx, id)
+		if err == nil {
+			h.remember(subject, id.String())
+			return id.String(), nil
+		}
+		if !errors.Is(err, repository.ErrNotFound) {
+			return "", err
+		}
+	}
+
+	if provider == "" {
+		provider = string(model.AuthProviderOIDC)
+	}
+	user, created, err := h.users.FindOrCreate(ctx, model.CreateUserInput{
+		ExternalID: subject,
+		Email:      email,
+		Provider:   model.AuthProvider(provider),
+	})
+	if err != nil {
+		return "", fmt.Errorf("find or create user: %w", err)
+	}
+
+	if created {
+		h.logger.Info("provisioned user", slog.String("user_id", user.ID.String()), slog.String("provider", provider))
+		if err := h.publisher.PublishUserCreated(user); err != nil {
+			h.logger.Warn("failed to publish users.created", slog.Any("error", err), slog.String("user_id", user.ID.String()))
+		}
+	}
+
+	h.remember(subject, user.ID.String())
+	return user.ID.String(), nil
+}
+
+func (h *UserHandler) lookup(subject string) (string, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	p, ok := h.known[subject]
+	if !ok || time.Now().After(p.expires) {
+		delete(h.known, subject)
+		return "", false
+	}
+	return p.userID, true
+}
+
+func (h *UserHandler) remember(subject, userID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	if len(h.known) >= provisionCacheSize {
+		for s, p := range h.known {
+			if now.After(p.expires) {
+				delete(h.known, s)
+			}
+		}
+		// Still full of live entries: evict arbitrary ones.
+		for s := range h.known {
+			if len(h.known) < provisionCacheSize {
+				break
+			}
+			delete(h.known, s)
+		}
+	}
+	h.known[subject] = provisioned{userID: userID, expires: now.Add(provisionCacheTTL)}
+}
+
+func (h *UserHandler) forget(userID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for subject, p := range h.known {
+		if p.userID == userID {
+			delete(h.known, subject)
+		}
+	}
+}
+
+// Get returns the current user's account.
+// GET /api/v1/me
+func (h *UserHandler) Get(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := h.users.GetByID(r.Context(), userID)
+	if err != nil {
+		h.userError(w, err, "Failed to retrieve account")
+		return
+	}
+
+	JSON(w, http.StatusOK, user)
+}
+
+// Update changes the current user's profile.
+// PATCH /api/v1/me
+func (h *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var input model.UpdateUserInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		Error(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if input.Email != nil && *input.Email != "" {
+		if _, err := mail.ParseAddress(*input.Email); err != nil {
+			Error(w, http.StatusBadRequest, "Invalid email")
+			return
+		}
+	}
+
+	user, err := h.users.Update(r.Context(), userID, input)
+	if err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			Error(w, http.StatusConflict, "Email is already in use")
+			return
+		}
+		h.userError(w, err, "Failed to update account")
+		return
+	}
+
+	JSON(w, http.StatusOK, user)
+}
+
+// Delete deletes the current user's account, their devices and preferences,
+// and detaches them from the chases they created. The account cannot be
+// signed in to again.
+// DELETE /api/v1/me
+func (h *UserHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.users.DeleteAccount(r.Context(), userID); err != nil {
+		h.userError(w, err, "Failed to delete account")
+		return
+	}
+	h.forget(userID.String())
+
+	h.logger.Info("deleted user account", slog.String("user_id", userID.String()))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Devices lists the current user's registered push devices.
+// GET /api/v1/me/devices
+func (h *UserHandler) Devices(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	tokens, err := h.tokens.GetByUserID(r.Context(), userID)
+	if err != nil {
+		h.logger.Error("failed to list devices", slog.Any("error", err))
+		Error(w, http.StatusInternalServerError, "Failed to retrieve devices")
+		return
+	}
+	if tokens == nil {
+		tokens = []model.PushToken{}
+	}
+	for i := range tokens {
+		// Metadata holds web push encryption keys; they stay server-side.
+		tokens[i].Metadata = nil
+	}
+
+	JSON(w, http.StatusOK, map[string]interface{}{"devices": tokens})
+}
+
+func (h *UserHandler) userError(w http.ResponseWriter, err error, msg string) {
+	if errors.Is(err, repository.ErrNotFound) {
+		Error(w, http.StatusNotFound, "User not found")
+		return
+	}
+	h.logger.Error("user account request failed", slog.Any("error", err))
+	Error(w, http.StatusInternalServerError, msg)
+}
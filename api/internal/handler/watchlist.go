@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"chaseapp.tv/api/internal/ingest"
+	"chaseapp.tv/api/internal/model"
+	"chaseapp.tv/api/internal/repository"
+)
+
+var icaoPattern = regexp.MustCompile(`^[0-9A-F]{6}$`)
+
+// WatchlistHandler handles the aircraft watchlist.
+type WatchlistHandler struct {
+	repo      *repository.WatchlistRepository
+	watchlist *ingest.Watchlist
+	logger    *slog.Logger
+}
+
+// NewWatchlistHandler creates a new WatchlistHandler. Changes are applied
+// to watchlist immediately rather than at its next refresh.
+func NewWatchlistHandler(repo *repository.WatchlistRepository, watchlist *ingest.Watchlist, logger *slog.Logger) *WatchlistHandler {
+	return &WatchlistHandler{
+		repo:      repo,
+		watchlist: watchlist,
+		logger:    logger,
+	}
+}
+
+// List returns watchlist entries, optionally filtered by category or group.
+// GET /api/v1/aircraft/watchlist
+func (h *WatchlistHandler) List(w http.ResponseWriter, r *http.Request) {
+	opts := model.WatchlistListOptions{
+		Category: model.AircraftCategory(r.URL.Query().Get("category")),
+		Group:    r.URL.Query().Get("group"),
+	}
+
+	entries, err := h.repo.List(r.Context(), opts)
+	if err != nil {
+		h.logger.Error("failed to list watchlist", slog.Any("error", err))
+		Error(w, http.StatusInternalServerError, "Failed to retrieve watchlist")
+		return
+	}
+
+	JSON(w, http.StatusOK, map[string]interface{}{"watchlist": entries})
+}
+
+// Get returns a watchlist entry.
+// GET /api/v1/aircraft/watchlist/{id}
+func (h *WatchlistHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, http.StatusBadRequest, "Invalid watchlist entry ID")
+		return
+	}
+
+	entry, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		h.writeError(w, err, "Failed to retrieve watchlist entry")
+		return
+	}
+
+	JSON(w, http.StatusOK, entry)
+}
+
+// Create adds an aircraft to the watchlist and tags it.
+// POST /api/v1/aircraft/watchlist
+func (h *WatchlistHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var input model.CreateWatchlistEntryInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		Error(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	input.ICAO = normalizeTail(input.ICAO)
+	input.Registration = normalizeTail(input.Registration)
+	if msg := validateWatchlistEntry(input.ICAO, input.Registration, input.Category, input.ImageURL); msg != "" {
+		Error(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	entry, err := h.repo.Create(r.Context(), input)
+	if err != nil {
+		h.writeError(w, err, "Failed to create watchlist entry")
+		return
+	}
+	h.reload(r)
+
+	h.logger.Info("watchlist entry added",
+		slog.String("id", entry.ID.String()),
+		slog.String("icao", entry.ICAO),
+		slog.String("registration", entry.Registration),
+		slog.String("by", actingUserID(r)),
+	)
+	JSON(w, http.StatusCreated, entry)
+}
+
+// Update modifies a watchlist entry and re-tags aircraft.
+// PATCH /api/v1/aircraft/watchlist/{id}
+func (h *WatchlistHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, http.StatusBadRequest, "Invalid watchlist entry ID")
+		return
+	}
+
+	var input model.UpdateWatchlistEntryInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		Error(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	current, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		h.writeError(w, err, "Failed to update watchlist entry")
+		return
+	}
+	icao, registration, category, imageURL := current.ICAO, current.Registration, current.Category, current.ImageURL
+	if input.ICAO != nil {
+		*input.ICAO = normalizeTail(*input.ICAO)
+		icao = *input.ICAO
+	}
+	if input.Registration != nil {
+		*input.Registration = normalizeTail(*input.Registration)
+		registration = *input.Registration
+	}
+	if input.Category != nil {
+		category = *input.Category
+	}
+	if input.ImageURL != nil {
+		imageURL = *input.ImageURL
+	}
+	if msg := validateWatchlistEntry(icao, registration, category, imageURL); msg != "" {
+		Error(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	entry, err := h.repo.Update(r.Context(), id, input)
+	if err != nil {
+		h.writeError(w, err, "Failed to update watchlist entry")
+		return
+	}
+	h.reload(r)
+
+	JSON(w, http.StatusOK, entry)
+}
+
+// Delete removes a watchlist entry and untags its aircraft.
+// DELETE /api/v1/aircraft/watchlist/{id}
+func (h *WatchlistHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, http.StatusBadRequest, "Invalid watchlist entry ID")
+		return
+	}
+
+	if err := h.repo.Delete(r.Context(), id); err != nil {
+		h.writeError(w, err, "Failed to delete watchlist entry")
+		return
+	}
+	h.reload(r)
+
+	h.logger.Info("watchlist entry removed", slog.String("id", id.String()), slog.String("by", actingUserID(r)))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// reload refreshes the ingest index; on failure the periodic reload
+// catches up.
+func (h *WatchlistHandler) reload(r *http.Request) {
+	if h.watchlist == nil {
+		return
+	}
+	if err := h.watchlist.Reload(r.Context()); err != nil {
+		h.logger.Warn("failed to reload aircraft watchlist", slog.Any("error", err))
+	}
+}
+
+func (h *WatchlistHandler) writeError(w http.ResponseWriter, err error, msg string) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		Error(w, http.StatusNotFound, "Watchlist entry not found")
+	case errors.Is(err, repository.ErrConflict):
+		Error(w, http.StatusConflict, "An entry for this ICAO address or tail number already exists")
+	default:
+		h.logger.Error(strings.ToLower(msg), slog.Any("error", err))
+		Error(w, http.StatusInternalServerError, msg)
+	}
+}
+
+// normalizeTail upper-cases and trims an ICAO address or tail number.
+func normalizeTail(s string) string {
+	return strings.ToUpper(strings.TrimSpace(s))
+}
+
+// validateWatchlistEntry returns a client error message, or "" if valid.
+func validateWatchlistEntry(icao, registration string, category model.AircraftCategory, imageURL string) string {
+	if icao == "" && registration == "" {
+		return "icao or registration is required"
+	}
+	if icao != "" && !icaoPattern.MatchString(icao) {
+		return "icao must be a 6-digit hex address"
+	}
+	if !category.Valid() {
+		return "Invalid category: " + string(category)
+	}
+	if imageURL != "" {
+		u, err := url.Parse(imageURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return "image_url must be an http(s) URL"
+		}
+	}
+	return ""
+}
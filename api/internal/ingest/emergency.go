@@ -0,0 +1,103 @@
+package ingest
+
+import (
+	"context"
+	"time"
+
+	"chaseapp.tv/api/pkg/adsb"
+)
+
+// Emergency squawk codes.
+const (
+	SquawkHijack        = "7500"
+	SquawkRadioFailure  = "7600"
+	SquawkEmergency     = "7700"
+	emergencyStatusNone = "none"
+)
+
+// emergencyStatuses describes the readsb emergency values.
+var emergencyStatuses = map[string]string{
+	"general":   "General emergency",
+	"lifeguard": "Lifeguard/medical",
+	"minfuel":   "Minimum fuel",
+	"nordo":     "No communications",
+	"unlawful":  "Unlawful interference",
+	"downed":    "Aircraft downed",
+}
+
+var emergencySquawks = map[string]string{
+	SquawkHijack:       "Hijack",
+	SquawkRadioFailure: "Radio failure",
+	SquawkEmergency:    "General emergency",
+}
+
+// EmergencyReason describes the emergency an aircraft is declaring through
+// its squawk code or emergency status, or returns "" if there is none.
+func EmergencyReason(squawk, emergency string) string {
+	reason, ok := emergencyStatuses[emergency]
+	if !ok && emergency != "" && emergency != emergencyStatusNone {
+		reason = "Emergency (" + emergency + ")"
+	}
+	if desc, ok := emergencySquawks[squawk]; ok {
+		if reason == "" {
+			reason = desc
+		}
+		reason += " (squawk " + squawk + ")"
+	}
+	return reason
+}
+
+// EmergencyStore persists aircraft emergency episodes.
+type EmergencyStore interface {
+	ObserveEmergencies(ctx context.Context, now time.Time, declaring, ended []string) ([]string, error)
+}
+
+// Emergencies tracks aircraft emergency episodes so each is announced once.
+// An episode lasts while an aircraft keeps reporting, and ends when it
+// reports a normal squawk or, through the emergency worker, has not been
+// seen for a while. Reports without a squawk (most SBS messages) neither
+// start nor end an episode. Episodes are stored on the aircraft rows, so
+// replicas sharing the feed announce each one once between them.
+type Emergencies struct {
+	store EmergencyStore
+}
+
+// NewEmergencies creates a new Emergencies tracker.
+func NewEmergencies(store EmergencyStore) *Emergencies {
+	return &Emergencies{store: store}
+}
+
+// Observe records reports received at now and returns the reason for each
+// aircraft, by ICAO address, that has started an emergency. It is safe on a
+// nil *Emergencies, which detects nothing.
+func (e *Emergencies) Observe(ctx context.Context, now time.Time, reports []adsb.Report) (map[string]string, error) {
+	if e == nil {
+		return nil, nil
+	}
+
+	reasons := make(map[string]string)
+	var declaring, ended []string
+	for _, r := range reports {
+		reason := EmergencyReason(r.Squawk, r.Emergency)
+		switch {
+		case reason != "":
+			reasons[r.ICAO] = reason
+			declaring = append(declaring, r.ICAO)
+		case r.Squawk != "":
+			ended = append(ended, r.ICAO)
+		}
+	}
+	if len(declaring) == 0 && len(ended) == 0 {
+		return nil, nil
+	}
+
+	icaos, err := e.store.ObserveEmergencies(ctx, now, declaring, ended)
+	if err != nil {
+		return nil, err
+	}
+	started := make(map[string]string, len(icaos))
+	for _, icao := range icaos {
+		started[icao] = reasons[icao]
+	}
+	return started, nil
+}
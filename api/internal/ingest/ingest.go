@@ -0,0 +1,210 @@
+// Package ingest turns ADS-B feeder reports into aircraft rows and
+// aircraft.updated events.
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"chaseapp.tv/api/internal/model"
+	"chaseapp.tv/api/pkg/adsb"
+)
+
+// maxBatch caps the number of aircraft upserted per database round trip.
+const maxBatch = 500
+
+// Store persists aircraft observations.
+type Store interface {
+	UpsertBatch(ctx context.Context, inputs []model.UpsertAircraftInput) ([]model.Aircraft, error)
+}
+
+// Publisher announces updated aircraft and emergencies.
+type Publisher interface {
+	PublishAircraftUpdated(aircraft []model.Aircraft) error
+	PublishAircraftEmergency(aircraft model.Aircraft, reason string) error
+}
+
+// Result summarises one ingest call.
+type Result struct {
+	Received int `json:"received"`
+	Upserted int `json:"upserted"`
+	Skipped  int `json:"skipped"`
+}
+
+// Ingester upserts reports and publishes the resulting aircraft.
+type Ingester struct {
+	store       Store
+	publisher   Publisher
+	watchlist   *Watchlist
+	emergencies *Emergencies
+	logger      *slog.Logger
+}
+
+// NewIngester creates a new Ingester. publisher, watchlist and emergencies
+// may be nil.
+func NewIngester(store Store, publisher Publisher, watchlist *Watchlist, emergencies *Emergencies, logger *slog.Logger) *Ingester {
+	return &Ingester{
+		store:       store,
+		publisher:   publisher,
+		watchlist:   watchlist,
+		emergencies: emergencies,
+		logger:      logger,
+	}
+}
+
+// Ingest merges reports per aircraft, tags those on the watchlist, upserts
+// them with their position history and publishes aircraft.updated, plus
+// aircraft.emergency for each aircraft starting an emergency. A publish
+// failure is logged but does not fail the ingest, since the rows are
+// already stored.
+func (i *Ingester) Ingest(ctx context.Context, reports []adsb.Report) (Result, error) {
+	res := Result{Received: len(reports)}
+
+	var p Pending
+	for _, r := range reports {
+		if r.ICAO == "" {
+			res.Skipped++
+			continue
+		}
+		p.Add(r)
+	}
+
+	merged := p.Drain()
+	inputs := make([]model.UpsertAircraftInput, 0, len(merged))
+	for _, r := range merged {
+		in := toInput(r)
+		if i.watchlist != nil {
+			e, ok := i.watchlist.Match(r.ICAO, r.Registration, r.Callsign)
+			if ok {
+				tag(&in, e)
+			}
+			in.Watchlisted = &ok
+		}
+		inputs = append(inputs, in)
+	}
+
+	for start := 0; start < len(inputs); start += maxBatch {
+		end := min(start+maxBatch, len(inputs))
+		aircraft, err := i.store.UpsertBatch(ctx, inputs[start:end])
+		if err != nil {
+			return res, fmt.Errorf("failed to ingest aircraft: %w", err)
+		}
+		res.Upserted += len(aircraft)
+
+		if i.publisher == nil || len(aircraft) == 0 {
+			continue
+		}
+		if err := i.publisher.PublishAircraftUpdated(aircraft); err != nil {
+			i.logger.Warn("failed to publish aircraft update",
+				slog.Int("count", len(aircraft)),
+				slog.Any("error", err),
+			)
+		}
+		started, err := i.emergencies.Observe(ctx, time.Now(), merged[start:end])
+		if err != nil {
+			i.logger.Warn("failed to record aircraft emergencies", slog.Any("error", err))
+		}
+		i.announceEmergencies(aircraft, started)
+	}
+
+	return res, nil
+}
+
+// announceEmergencies publishes aircraft.emergency for the aircraft in
+// started, keyed by ICAO address.
+func (i *Ingester) announceEmergencies(aircraft []model.Aircraft, started map[string]string) {
+	if len(started) == 0 {
+		return
+	}
+	for _, a := range aircraft {
+		reason, ok := started[a.ICAO]
+		if !ok {
+			continue
+		}
+		i.logger.Info("aircraft emergency",
+			slog.String("icao", a.ICAO),
+			slog.String("callsign", a.Callsign),
+			slog.String("reason", reason),
+		)
+		if err := i.publisher.PublishAircraftEmergency(a, reason); err != nil {
+			i.logger.Warn("failed to publish aircraft emergency",
+				slog.String("icao", a.ICAO),
+				slog.Any("error", err),
+			)
+		}
+	}
+}
+
+// toInput maps a report onto an upsert. The ADS-B emitter category is kept
+// in metadata because model categories describe the operator's role.
+func toInput(r adsb.Report) model.UpsertAircraftInput {
+	in := model.UpsertAircraftInput{
+		ICAO:         r.ICAO,
+		Callsign:     r.Callsign,
+		Registration: r.Registration,
+		Latitude:     r.Lat,
+		Longitude:    r.Lng,
+		Altitude:     r.Altitude,
+		GroundSpeed:  r.GroundSpeed,
+		Track:        r.Track,
+		VerticalRate: r.VerticalRate,
+		AircraftType: r.AircraftType,
+		Squawk:       r.Squawk,
+		Emergency:    r.Emergency,
+		OnGround:     r.OnGround,
+		SeenAt:       r.SeenAt,
+	}
+	if r.Category != "" {
+		in.Metadata = map[string]interface{}{"adsb_category": r.Category}
+	}
+	return in
+}
+
+// Pending accumulates reports, merging those for the same aircraft, until
+// they are drained. The zero value is ready to use and safe for concurrent
+// use.
+type Pending struct {
+	mu      sync.Mutex
+	order   []string
+	reports map[string]*adsb.Report
+}
+
+// Add merges r into the pending report for its aircraft.
+func (p *Pending) Add(r adsb.Report) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.reports == nil {
+		p.reports = make(map[string]*adsb.Report)
+	}
+	if existing, ok := p.reports[r.ICAO]; ok {
+		existing.Merge(r)
+		return
+	}
+	p.reports[r.ICAO] = &r
+	p.order = append(p.order, r.ICAO)
+}
+
+// Len returns the number of aircraft pending.
+func (p *Pending) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.order)
+}
+
+// Drain returns the pending reports in arrival order and resets p.
+func (p *Pending) Drain() []adsb.Report {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	out := make([]adsb.Report, 0, len(p.order))
+	for _, icao := range p.order {
+		out = append(out, *p.reports[icao])
+	}
+	p.order = nil
+	p.reports = nil
+	return out
+}
@@ -0,0 +1,253 @@
+package ingest
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"net/netip"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"chaseapp.tv/api/internal/model"
+	"chaseapp.tv/api/pkg/adsb"
+)
+
+type fakeStore struct {
+	mu     sync.Mutex
+	inputs []model.UpsertAircraftInput
+}
+
+func (s *fakeStore) UpsertBatch(_ context.Context, inputs []model.UpsertAircraftInput) ([]model.Aircraft, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]model.Aircraft, 0, len(inputs))
+	for _, in := range inputs {
+		s.inputs = append(s.inputs, in)
+		out = append(out, model.Aircraft{ICAO: in.ICAO, Latitude: in.Latitude, Longitude: in.Longitude})
+	}
+	return out, nil
+}
+
+func (s *fakeStore) byICAO() map[string]model.UpsertAircraftInput {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m := map[string]model.UpsertAircraftInput{}
+	for _, in := range s.inputs {
+		m[in.ICAO] = in
+	}
+	return m
+}
+
+// fakeEmergencyStore keeps episode starts by ICAO address, as the aircraft
+// rows do.
+type fakeEmergencyStore struct {
+	mu      sync.Mutex
+	started map[string]time.Time
+}
+
+func (s *fakeEmergencyStore) ObserveEmergencies(_ context.Context, now time.Time, declaring, ended []string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started == nil {
+		s.started = map[string]time.Time{}
+	}
+	for _, icao := range ended {
+		delete(s.started, icao)
+	}
+	var started []string
+	for _, icao := range declaring {
+		if _, ok := s.started[icao]; !ok {
+			s.started[icao] = now
+			started = append(started, icao)
+		}
+	}
+	return started, nil
+}
+
+type fakePublisher struct {
+	batches     [][]model.Aircraft
+	emergencies []string
+}
+
+func (p *fakePublisher) PublishAircraftUpdated(aircraft []model.Aircraft) error {
+	p.batches = append(p.batches, aircraft)
+	return nil
+}
+
+func (p *fakePublisher) PublishAircraftEmergency(aircraft model.Aircraft, reason string) error {
+	p.emergencies = append(p.emergencies, aircraft.ICAO+": "+reason)
+	return nil
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestIngestAircraftJSON(t *testing.T) {
+	data, err := os.ReadFile("../../pkg/adsb/testdata/aircraft.json")
+	require.NoError(t, err)
+	reports, err := adsb.ParseAircraftJSON(data, time.Now())
+	require.NoError(t, err)
+
+	store := &fakeStore{}
+	pub := &fakePublisher{}
+	res, err := NewIngester(store, pub, nil, nil, testLogger()).Ingest(context.Background(), reports)
+	require.NoError(t, err)
+	require.Equal(t, Result{Received: 6, Upserted: 6}, res)
+	require.Len(t, pub.batches, 1)
+
+	heli := store.byICAO()["A4B2C1"]
+	require.Equal(t, "N411LA", heli.Callsign)
+	require.Equal(t, "A7", heli.Metadata["adsb_category"])
+	require.Empty(t, heli.Category, "emitter categories are not operator categories")
+	require.NotNil(t, heli.Latitude)
+	require.False(t, heli.SeenAt.IsZero())
+	onGround := store.byICAO()["A1F3E2"].OnGround
+	require.NotNil(t, onGround)
+	require.True(t, *onGround)
+}
+
+func TestSBSListener(t *testing.T) {
+	store := &fakeStore{}
+	ingester := NewIngester(store, nil, nil, nil, testLogger())
+	l := NewSBSListener(SBSConfig{
+		AllowedCIDRs:  []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")},
+		FlushInterval: 10 * time.Millisecond,
+	}, ingester, testLogger())
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- l.Serve(ctx, ln) }()
+
+	feed, err := os.ReadFile("../../pkg/adsb/testdata/basestation.sbs")
+	require.NoError(t, err)
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	require.NoError(t, err)
+	_, err = conn.Write(feed)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		heli, ok := store.byICAO()["A4B2C1"]
+		return ok && heli.Latitude != nil && heli.Squawk == "1200"
+	}, 2*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+	require.Len(t, store.byICAO(), 3)
+}
+
+func TestSBSListenerRejectsOutsideCIDR(t *testing.T) {
+	l := NewSBSListener(SBSConfig{
+		AllowedCIDRs: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")},
+	}, nil, testLogger())
+	require.False(t, l.allowed(&net.TCPAddr{IP: net.ParseIP("127.0.0.1")}))
+	require.True(t, l.allowed(&net.TCPAddr{IP: net.ParseIP("::ffff:10.1.2.3")}))
+}
+
+func TestIngestTagsWatchlistedAircraft(t *testing.T) {
+	data, err := os.ReadFile("../../pkg/adsb/testdata/aircraft.json")
+	require.NoError(t, err)
+	reports, err := adsb.ParseAircraftJSON(data, time.Now())
+	require.NoError(t, err)
+
+	ktla := model.WatchlistEntry{
+		ID:           uuid.New(),
+		Registration: "N411LA",
+		Group:        "KTLA",
+		Category:     model.AircraftCategoryMedia,
+		ImageURL:     "https://img.example.com/ktla.jpg",
+		Operator:     "KTLA 5 News",
+	}
+	lapd := model.WatchlistEntry{
+		ID:       uuid.New(),
+		ICAO:     "ad64f3",
+		Group:    "LAPD",
+		Category: model.AircraftCategoryLawEnforcement,
+	}
+	watchlist := NewWatchlist(nil, 0, testLogger())
+	watchlist.Set([]model.WatchlistEntry{ktla, lapd})
+
+	store := &fakeStore{}
+	_, err = NewIngester(store, nil, watchlist, nil, testLogger()).Ingest(context.Background(), reports)
+	require.NoError(t, err)
+
+	byICAO := store.byICAO()
+	heli := byICAO["A4B2C1"]
+	require.Equal(t, model.AircraftCategoryMedia, heli.Category, "matched by callsign as tail number")
+	require.Equal(t, "KTLA 5 News", heli.Operator)
+	require.Equal(t, "N411LA", heli.Registration)
+	require.Equal(t, "KTLA", heli.Metadata["watchlist_group"])
+	require.Equal(t, ktla.ImageURL, heli.Metadata["image_url"])
+	require.Equal(t, "A7", heli.Metadata["adsb_category"])
+
+	require.Equal(t, model.AircraftCategoryLawEnforcement, byICAO["AD64F3"].Category)
+	require.Empty(t, byICAO["A1F3E2"].Category)
+
+	// Every report says whether it matched, so stale tags can be cleared.
+	require.True(t, *heli.Watchlisted)
+	require.False(t, *byICAO["A1F3E2"].Watchlisted, "unmatched aircraft clear previous tags")
+
+	store = &fakeStore{}
+	_, err = NewIngester(store, nil, nil, nil, testLogger()).Ingest(context.Background(), reports)
+	require.NoError(t, err)
+	require.Nil(t, store.byICAO()["A1F3E2"].Watchlisted, "tags are kept without a watchlist")
+}
+
+func TestIngestAnnouncesEmergencyOnce(t *testing.T) {
+	data, err := os.ReadFile("../../pkg/adsb/testdata/aircraft.json")
+	require.NoError(t, err)
+	reports, err := adsb.ParseAircraftJSON(data, time.Now())
+	require.NoError(t, err)
+
+	pub := &fakePublisher{}
+	ingester := NewIngester(&fakeStore{}, pub, nil, NewEmergencies(&fakeEmergencyStore{}), testLogger())
+	ingest := func(reports ...adsb.Report) {
+		_, err := ingester.Ingest(context.Background(), reports)
+		require.NoError(t, err)
+	}
+
+	ingest(reports...)
+	require.Equal(t, []string{"AD64F3: General emergency (squawk 7700)"}, pub.emergencies)
+
+	// Still squawking, or reporting without a squawk: same episode.
+	ingest(reports...)
+	ingest(adsb.Report{ICAO: "AD64F3", Emergency: "none"})
+	require.Len(t, pub.emergencies, 1)
+
+	// A normal squawk ends the episode; the next emergency alerts again.
+	ingest(adsb.Report{ICAO: "AD64F3", Squawk: "1200", Emergency: "none"})
+	ingest(adsb.Report{ICAO: "AD64F3", Squawk: "7600"})
+	require.Equal(t, "AD64F3: Radio failure (squawk 7600)", pub.emergencies[1])
+}
+
+func TestEmergenciesSharedAcrossReplicas(t *testing.T) {
+	store := &fakeEmergencyStore{}
+	a, b := NewEmergencies(store), NewEmergencies(store)
+	ctx := context.Background()
+	now := time.Now()
+	squawk := []adsb.Report{{ICAO: "A1B2C3", Squawk: "7500"}}
+
+	started, err := a.Observe(ctx, now, squawk)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"A1B2C3": "Hijack (squawk 7500)"}, started)
+
+	// Another replica receiving the same emergency does not announce it.
+	started, err = b.Observe(ctx, now.Add(time.Second), squawk)
+	require.NoError(t, err)
+	require.Empty(t, started)
+
+	// A normal squawk seen by either replica ends the episode.
+	_, err = b.Observe(ctx, now.Add(time.Minute), []adsb.Report{{ICAO: "A1B2C3", Squawk: "1200"}})
+	require.NoError(t, err)
+	started, err = a.Observe(ctx, now.Add(2*time.Minute), squawk)
+	require.NoError(t, err)
+	require.Len(t, started, 1)
+}
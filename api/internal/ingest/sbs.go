@@ -0,0 +1,206 @@
+package ingest
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/netip"
+	"sync"
+	"time"
+
+	"chaseapp.tv/api/pkg/adsb"
+)
+
+// SBSConfig configures the SBS-1 listener.
+type SBSConfig struct {
+	Addr          string
+	AllowedCIDRs  []netip.Prefix // empty allows any feeder
+	FlushInterval time.Duration
+}
+
+// SBSListener accepts SBS-1 BaseStation feeds over TCP, the format dump1090
+// and readsb serve on port 30003, and ingests them in periodic batches.
+type SBSListener struct {
+	cfg      SBSConfig
+	ingester *Ingester
+	pending  Pending
+	logger   *slog.Logger
+
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+}
+
+// NewSBSListener creates a new SBSListener.
+func NewSBSListener(cfg SBSConfig, ingester *Ingester, logger *slog.Logger) *SBSListener {
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = time.Second
+	}
+	return &SBSListener{
+		cfg:      cfg,
+		ingester: ingester,
+		logger:   logger,
+		conns:    make(map[net.Conn]struct{}),
+	}
+}
+
+// Run listens until ctx is cancelled, then closes feeder connections and
+// flushes what they sent.
+func (l *SBSListener) Run(ctx context.Context) error {
+	ln, err := net.Listen("tcp", l.cfg.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for sbs feeds: %w", err)
+	}
+	return l.Serve(ctx, ln)
+}
+
+// Serve accepts feeder connections on ln until ctx is cancelled.
+func (l *SBSListener) Serve(ctx context.Context, ln net.Listener) error {
+	l.logger.Info("sbs listener started", slog.String("addr", ln.Addr().String()))
+
+	stop := make(chan struct{})
+	flushed := make(chan struct{})
+	go func() {
+		defer close(flushed)
+		l.flushLoop(ctx, stop)
+	}()
+
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+		l.closeConns()
+	}()
+
+	var conns sync.WaitGroup
+	var err error
+	for {
+		conn, acceptErr := ln.Accept()
+		if acceptErr != nil {
+			if ctx.Err() == nil && !errors.Is(acceptErr, net.ErrClosed) {
+				l.logger.Warn("sbs accept failed", slog.Any("error", acceptErr))
+				continue
+			}
+			if ctx.Err() == nil {
+				err = acceptErr
+			}
+			break
+		}
+		if !l.allowed(conn.RemoteAddr()) {
+			l.logger.Warn("sbs feeder rejected", slog.String("remote", conn.RemoteAddr().String()))
+			conn.Close()
+			continue
+		}
+
+		l.mu.Lock()
+		l.conns[conn] = struct{}{}
+		l.mu.Unlock()
+
+		conns.Add(1)
+		go func() {
+			defer conns.Done()
+			l.handle(conn)
+		}()
+	}
+
+	// Flush whatever the feeders sent before they were disconnected, with a
+	// fresh context so the final batch is not lost to cancellation.
+	l.closeConns()
+	conns.Wait()
+	close(stop)
+	<-flushed
+	flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	l.flush(flushCtx)
+
+	return err
+}
+
+// allowed reports whether addr is inside one of the configured CIDRs.
+func (l *SBSListener) allowed(addr net.Addr) bool {
+	if len(l.cfg.AllowedCIDRs) == 0 {
+		return true
+	}
+	tcp, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+	ip, ok := netip.AddrFromSlice(tcp.IP)
+	if !ok {
+		return false
+	}
+	ip = ip.Unmap()
+	for _, prefix := range l.cfg.AllowedCIDRs {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *SBSListener) handle(conn net.Conn) {
+	defer func() {
+		l.mu.Lock()
+		delete(l.conns, conn)
+		l.mu.Unlock()
+		conn.Close()
+	}()
+
+	remote := conn.RemoteAddr().String()
+	l.logger.Info("sbs feeder connected", slog.String("remote", remote))
+
+	var invalid int
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		r, err := adsb.ParseSBS(scanner.Text(), time.Now())
+		if errors.Is(err, adsb.ErrNotSBSMessage) {
+			continue
+		}
+		if err != nil {
+			invalid++
+			continue
+		}
+		l.pending.Add(r)
+	}
+
+	l.logger.Info("sbs feeder disconnected",
+		slog.String("remote", remote),
+		slog.Int("invalid_lines", invalid),
+	)
+}
+
+func (l *SBSListener) closeConns() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for conn := range l.conns {
+		conn.Close()
+	}
+}
+
+func (l *SBSListener) flushLoop(ctx context.Context, stop <-chan struct{}) {
+	ticker := time.NewTicker(l.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			l.flush(ctx)
+		}
+	}
+}
+
+func (l *SBSListener) flush(ctx context.Context) {
+	reports := l.pending.Drain()
+	if len(reports) == 0 {
+		return
+	}
+	if _, err := l.ingester.Ingest(ctx, reports); err != nil {
+		l.logger.Error("failed to ingest sbs batch",
+			slog.Int("aircraft", len(reports)),
+			slog.Any("error", err),
+		)
+	}
+}
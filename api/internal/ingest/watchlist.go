@@ -0,0 +1,138 @@
+package ingest
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"chaseapp.tv/api/internal/model"
+)
+
+// WatchlistSource loads watchlist entries.
+type WatchlistSource interface {
+	List(ctx context.Context, opts model.WatchlistListOptions) ([]model.WatchlistEntry, error)
+}
+
+// Watchlist is an in-memory index of the aircraft watchlist used to tag
+// reports during ingestion. It is reloaded periodically so edits made on
+// other instances are picked up.
+type Watchlist struct {
+	source  WatchlistSource
+	refresh time.Duration
+	logger  *slog.Logger
+
+	mu             sync.RWMutex
+	byICAO         map[string]model.WatchlistEntry
+	byRegistration map[string]model.WatchlistEntry
+}
+
+// NewWatchlist creates a new Watchlist. It is empty until Reload or Run.
+func NewWatchlist(source WatchlistSource, refresh time.Duration, logger *slog.Logger) *Watchlist {
+	if refresh <= 0 {
+		refresh = time.Minute
+	}
+	return &Watchlist{
+		source:  source,
+		refresh: refresh,
+		logger:  logger,
+	}
+}
+
+// Reload replaces the index with the current entries.
+func (w *Watchlist) Reload(ctx context.Context) error {
+	entries, err := w.source.List(ctx, model.WatchlistListOptions{})
+	if err != nil {
+		return err
+	}
+	w.Set(entries)
+	return nil
+}
+
+// Set replaces the index with entries.
+func (w *Watchlist) Set(entries []model.WatchlistEntry) {
+	byICAO := make(map[string]model.WatchlistEntry, len(entries))
+	byRegistration := make(map[string]model.WatchlistEntry, len(entries))
+	for _, e := range entries {
+		if e.ICAO != "" {
+			byICAO[strings.ToUpper(e.ICAO)] = e
+		}
+		if e.Registration != "" {
+			byRegistration[strings.ToUpper(e.Registration)] = e
+		}
+	}
+
+	w.mu.Lock()
+	w.byICAO = byICAO
+	w.byRegistration = byRegistration
+	w.mu.Unlock()
+}
+
+// Run reloads the index every refresh interval until ctx is cancelled.
+func (w *Watchlist) Run(ctx context.Context) {
+	if err := w.Reload(ctx); err != nil {
+		w.logger.Warn("failed to load aircraft watchlist", slog.Any("error", err))
+	}
+
+	ticker := time.NewTicker(w.refresh)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.Reload(ctx); err != nil {
+				w.logger.Warn("failed to reload aircraft watchlist", slog.Any("error", err))
+			}
+		}
+	}
+}
+
+// Match finds the entry for an aircraft by ICAO address, then by tail
+// number. Civil aircraft often fly with their tail number as callsign, so
+// the callsign is tried as a tail number last.
+func (w *Watchlist) Match(icao, registration, callsign string) (model.WatchlistEntry, bool) {
+	if w == nil {
+		return model.WatchlistEntry{}, false
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if e, ok := w.byICAO[strings.ToUpper(icao)]; ok && icao != "" {
+		return e, true
+	}
+	for _, tail := range []string{registration, callsign} {
+		tail = strings.ToUpper(strings.TrimSpace(tail))
+		if tail == "" {
+			continue
+		}
+		if e, ok := w.byRegistration[tail]; ok {
+			return e, true
+		}
+	}
+	return model.WatchlistEntry{}, false
+}
+
+// tag applies a watchlist entry to an upsert.
+func tag(in *model.UpsertAircraftInput, e model.WatchlistEntry) {
+	in.Category = e.Category
+	if e.Operator != "" {
+		in.Operator = e.Operator
+	}
+	if in.Registration == "" {
+		in.Registration = e.Registration
+	}
+	if in.Metadata == nil {
+		in.Metadata = map[string]interface{}{}
+	}
+	in.Metadata["watchlist_id"] = e.ID.String()
+	if e.Group != "" {
+		in.Metadata["watchlist_group"] = e.Group
+	}
+	if e.ImageURL != "" {
+		in.Metadata["image_url"] = e.ImageURL
+	}
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"chaseapp.tv/api/internal/auth"
+	"chaseapp.tv/api/internal/model"
+	"chaseapp.tv/api/internal/repository"
+)
+
+// APIKeyKey is the context key for the authenticated API key.
+const APIKeyKey contextKey = "api_key"
+
+// APIKeyStore authenticates API keys by hash, recording each use. Unknown,
+// revoked and expired keys return repository.ErrNotFound.
+type APIKeyStore interface {
+	Authenticate(ctx context.Context, keyHash string) (*model.APIKey, error)
+}
+
+// APIKeyFromContext returns the API key the request authenticated with.
+func APIKeyFromContext(ctx context.Context) (*model.APIKey, bool) {
+	key, ok := ctx.Value(APIKeyKey).(*model.APIKey)
+	return key, ok
+}
+
+// APIKeyAuth authenticates machine clients sending X-API-Key (or the legacy
+// X-ApiKey). A key does not carry a user identity; it is authorized by its
+// scopes through RequireAccess. Requests without a key pass through.
+func APIKeyAuth(store APIKeyStore, logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			raw := r.Header.Get("X-API-Key")
+			if raw == "" {
+				raw = r.Header.Get("X-ApiKey")
+			}
+			if raw == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key, err := store.Authenticate(r.Context(), auth.HashAPIKey(raw))
+			if err != nil {
+				if !errors.Is(err, repository.ErrNotFound) {
+					logger.Error("failed to authenticate api key", slog.Any("error", err))
+				}
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), APIKeyKey, key)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequireAccess admits API keys granted scope and users with any of roles.
+func RequireAccess(scope model.APIKeyScope, roles ...model.Role) func(http.Handler) http.Handler {
+	requireRole := RequireRole(roles...)
+	return func(next http.Handler) http.Handler {
+		byRole := requireRole(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if key, ok := APIKeyFromContext(r.Context()); ok {
+				if !key.HasScope(scope) {
+					http.Error(w, "Forbidden", http.StatusForbidden)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+			byRole.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"chaseapp.tv/api/internal/auth"
+	"chaseapp.tv/api/internal/model"
+	"chaseapp.tv/api/internal/repository"
+)
+
+type stubKeys map[string]*model.APIKey
+
+func (s stubKeys) Authenticate(_ context.Context, keyHash string) (*model.APIKey, error) {
+	key, ok := s[keyHash]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	key.RequestCount++
+	return key, nil
+}
+
+func TestRequireAccessWithAPIKey(t *testing.T) {
+	ingest := &model.APIKey{Name: "adsb-feeder", Scopes: []model.APIKeyScope{model.ScopeAircraftIngest}}
+	writer := &model.APIKey{Name: "chase-bot", Scopes: []model.APIKeyScope{model.ScopeChasesWrite}}
+	store := stubKeys{
+		auth.HashAPIKey("cak_ingest"): ingest,
+		auth.HashAPIKey("cak_writer"): writer,
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) })
+	h := APIKeyAuth(store, logger)(RequireAccess(model.ScopeChasesWrite, model.RoleAdmin)(ok))
+
+	status := func(key string) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/chases", nil)
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	require.Equal(t, http.StatusUnauthorized, status(""))
+	require.Equal(t, http.StatusUnauthorized, status("cak_unknown"))
+	require.Equal(t, http.StatusForbidden, status("cak_ingest"))
+	require.Equal(t, http.StatusNoContent, status("cak_writer"))
+	require.EqualValues(t, 1, writer.RequestCount)
+}
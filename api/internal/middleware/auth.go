@@ -2,7 +2,12 @@ package middleware
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"strings"
+
+	"chaseapp.tv/api/internal/auth"
 )
 
 // contextKey is a custom type for context keys to avoid collisions.
@@ -69,3 +74,74 @@ func RequireAuth(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// Authentication modes selected by AUTH_MODE.
+const (
+	AuthModeHeader = "header"
+	AuthModeJWT    = "jwt"
+	AuthModeBoth   = "both"
+)
+
+// Authenticate returns the authentication middleware for mode. In header
+// mode it is Auth. In jwt mode the identity comes only from a verified
+// bearer token and the Kong headers are ignored. In both mode a request
+// carrying either must carry both, and the token subject must match
+// X-User-ID. Requests with no credentials continue anonymously.
+func Authenticate(mode string, verifier *auth.Verifier, logger *slog.Logger) (func(http.Handler) http.Handler, error) {
+	switch mode {
+	case "", AuthModeHeader:
+		return Auth, nil
+	case AuthModeJWT, AuthModeBoth:
+		if verifier == nil {
+			return nil, fmt.Errorf("auth mode %q requires a jwt verifier", mode)
+		}
+	default:
+		return nil, fmt.Errorf("unknown auth mode %q", mode)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			headerID := r.Header.Get("X-User-ID")
+			token, hasToken := bearerToken(r)
+
+			if !hasToken {
+				if mode == AuthModeBoth && headerID != "" {
+					http.Error(w, "Unauthorized", http.StatusUnauthorized)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			claims, err := verifier.Verify(r.Context(), token)
+			if err != nil {
+				logger.Debug("rejected bearer token", slog.Any("error", err))
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+			if mode == AuthModeBoth && headerID != claims.Subject {
+				logger.Warn("identity header does not match token subject",
+					slog.String("header_user_id", headerID),
+					slog.String("token_subject", claims.Subject),
+				)
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), UserIDKey, claims.Subject)
+			if claims.Email != "" {
+				ctx = context.WithValue(ctx, UserEmailKey, claims.Email)
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}, nil
+}
+
+// bearerToken returns the token from an "Authorization: Bearer" header.
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
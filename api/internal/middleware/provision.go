@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"chaseapp.tv/api/internal/repository"
+)
+
+// UserProvisioner maps an authenticated subject to the internal user ID,
+// creating the account on first login.
+type UserProvisioner interface {
+	Provision(ctx context.Context, subject, email, provider string) (string, error)
+}
+
+// Provision replaces the authenticated subject in the request context with
+// the internal user ID, so handlers can rely on UserIDKey being a users.id.
+// The provider is taken from X-User-Provider when the gateway sends it.
+// When provisioning fails, reads continue anonymously; writes are refused,
+// with 403 for a deleted account.
+func Provision(p UserProvisioner, logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := UserFromContext(r.Context())
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			userID, err := p.Provision(r.Context(), user.ID, user.Email, r.Header.Get("X-User-Provider"))
+			if err != nil {
+				deleted := errors.Is(err, repository.ErrUserDeleted)
+				if deleted {
+					logger.Warn("sign-in to deleted account", slog.String("subject", user.ID))
+				} else {
+					logger.Error("failed to provision user", slog.Any("error", err), slog.String("subject", user.ID))
+				}
+
+				switch {
+				case r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions:
+					ctx := context.WithValue(r.Context(), UserIDKey, "")
+					next.ServeHTTP(w, r.WithContext(ctx))
+				case deleted:
+					http.Error(w, "Forbidden", http.StatusForbidden)
+				default:
+					http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				}
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), UserIDKey, userID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"chaseapp.tv/api/internal/repository"
+)
+
+type stubProvisioner map[string]string
+
+func (s stubProvisioner) Provision(_ context.Context, subject, _, _ string) (string, error) {
+	if id, ok := s[subject]; ok {
+		return id, nil
+	}
+	return "", errors.New("database unavailable")
+}
+
+type deletedProvisioner struct{}
+
+func (deletedProvisioner) Provision(context.Context, string, string, string) (string, error) {
+	return "", repository.ErrUserDeleted
+}
+
+func TestProvisionReplacesSubjectWithUserID(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	p := stubProvisioner{"google-oauth2|1234": "7f1c6a8e-2d0b-4a8e-9c55-5b1f0f0a7c21"}
+
+	var got string
+	h := Auth(Provision(p, logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, _ := UserFromContext(r.Context())
+		got = user.ID
+	})))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/me", nil)
+	req.Header.Set("X-User-ID", "google-oauth2|1234")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.Equal(t, "7f1c6a8e-2d0b-4a8e-9c55-5b1f0f0a7c21", got)
+}
+
+func TestProvisionFailureDegradesReads(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	for _, p := range []UserProvisioner{stubProvisioner{}, deletedProvisioner{}} {
+		var authenticated bool
+		h := Auth(Provision(p, logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, authenticated = UserFromContext(r.Context())
+		})))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/chases", nil)
+		req.Header.Set("X-User-ID", "google-oauth2|1234")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.False(t, authenticated)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/push/subscribe", nil)
+	req.Header.Set("X-User-ID", "google-oauth2|1234")
+	rec := httptest.NewRecorder()
+	Auth(Provision(deletedProvisioner{}, logger)(http.NotFoundHandler())).ServeHTTP(rec, req)
+	require.Equal(t, http.StatusForbidden, rec.Code)
+}
@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"chaseapp.tv/api/internal/model"
+)
+
+// RolesKey is the context key for the user's role loader.
+const RolesKey contextKey = "user_roles"
+
+// RoleResolver loads the roles granted to a user.
+type RoleResolver interface {
+	GetRoles(ctx context.Context, userID uuid.UUID) ([]model.Role, error)
+}
+
+// roleLoader resolves the request user's roles once, on first use, so
+// requests that never check a role do not hit the database.
+type roleLoader struct {
+	once  sync.Once
+	load  func() []model.Role
+	roles []model.Role
+}
+
+func (l *roleLoader) get() []model.Role {
+	l.once.Do(func() { l.roles = l.load() })
+	return l.roles
+}
+
+// Roles makes the authenticated user's roles available to RequireRole and
+// RolesFromContext. Users in adminIDs are always admins, which bootstraps
+// the first admin before any role has been granted.
+func Roles(resolver RoleResolver, adminIDs []string, logger *slog.Logger) func(http.Handler) http.Handler {
+	admins := make(map[string]bool, len(adminIDs))
+	for _, id := range adminIDs {
+		admins[id] = true
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := UserFromContext(r.Context())
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := r.Context()
+			loader := &roleLoader{load: func() []model.Role {
+				var roles []model.Role
+				if id, err := uuid.Parse(user.ID); err == nil {
+					roles, err = resolver.GetRoles(ctx, id)
+					if err != nil {
+						logger.Warn("failed to resolve user roles", slog.Any("error", err), slog.String("user_id", user.ID))
+					}
+				}
+				if admins[user.ID] && !slices.Contains(roles, model.RoleAdmin) {
+					roles = append(roles, model.RoleAdmin)
+				}
+				return roles
+			}}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(ctx, RolesKey, loader)))
+		})
+	}
+}
+
+// RolesFromContext returns the authenticated user's roles.
+func RolesFromContext(ctx context.Context) []model.Role {
+	loader, ok := ctx.Value(RolesKey).(*roleLoader)
+	if !ok {
+		return nil
+	}
+	return loader.get()
+}
+
+// HasRole reports whether the authenticated user has any of roles.
+func HasRole(ctx context.Context, roles ...model.Role) bool {
+	for _, role := range RolesFromContext(ctx) {
+		if slices.Contains(roles, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireRole ensures the authenticated user has at least one of roles.
+func RequireRole(roles ...model.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := UserFromContext(r.Context()); !ok {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+			if !HasRole(r.Context(), roles...) {
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"chaseapp.tv/api/internal/model"
+)
+
+type stubRoles map[uuid.UUID][]model.Role
+
+func (s stubRoles) GetRoles(_ context.Context, id uuid.UUID) ([]model.Role, error) {
+	return s[id], nil
+}
+
+func TestRequireRole(t *testing.T) {
+	moderator, user, bootstrap := uuid.New(), uuid.New(), uuid.New()
+	resolver := stubRoles{
+		moderator: {model.RoleUser, model.RoleModerator},
+		user:      {model.RoleUser},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) })
+	h := Auth(Roles(resolver, []string{bootstrap.String()}, logger)(
+		RequireRole(model.RoleAdmin, model.RoleModerator)(ok),
+	))
+
+	status := func(userID string) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/chases", nil)
+		if userID != "" {
+			req.Header.Set("X-User-ID", userID)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	require.Equal(t, http.StatusUnauthorized, status(""))
+	require.Equal(t, http.StatusForbidden, status(user.String()))
+	require.Equal(t, http.StatusNoContent, status(moderator.String()))
+	require.Equal(t, http.StatusNoContent, status(bootstrap.String()), "ADMIN_USER_IDS are admins")
+}
@@ -18,6 +18,16 @@ const (
 	AircraftCategoryGeneral        AircraftCategory = "general"
 )
 
+// Valid reports whether c is a known aircraft category.
+func (c AircraftCategory) Valid() bool {
+	switch c {
+	case AircraftCategoryMedia, AircraftCategoryLawEnforcement, AircraftCategoryMilitary,
+		AircraftCategoryMedical, AircraftCategoryFirefighting, AircraftCategoryGeneral:
+		return true
+	}
+	return false
+}
+
 // Aircraft represents an ADSB-tracked aircraft.
 type Aircraft struct {
 	ID uuid.UUID `json:"id"`
@@ -75,22 +85,24 @@ type AircraftHistory struct {
 
 // UpsertAircraftInput represents the input for creating or updating an aircraft.
 type UpsertAircraftInput struct {
-	ICAO         string  `json:"icao" validate:"required"`
-	Callsign     string  `json:"callsign,omitempty"`
-	Registration string  `json:"registration,omitempty"`
-	Latitude     *float64 `json:"latitude,omitempty"`
-	Longitude    *float64 `json:"longitude,omitempty"`
-	Altitude     *int     `json:"altitude,omitempty"`
-	GroundSpeed  *int     `json:"ground_speed,omitempty"`
-	Track        *int     `json:"track,omitempty"`
-	VerticalRate *int     `json:"vertical_rate,omitempty"`
-	AircraftType string   `json:"aircraft_type,omitempty"`
-	Category     AircraftCategory `json:"category,omitempty"`
-	Operator     string   `json:"operator,omitempty"`
-	OnGround     bool     `json:"on_ground"`
-	Squawk       string   `json:"squawk,omitempty"`
-	Emergency    string   `json:"emergency,omitempty"`
+	ICAO         string                 `json:"icao" validate:"required"`
+	Callsign     string                 `json:"callsign,omitempty"`
+	Registration string                 `json:"registration,omitempty"`
+	Latitude     *float64               `json:"latitude,omitempty"`
+	Longitude    *float64               `json:"longitude,omitempty"`
+	Altitude     *int                   `json:"altitude,omitempty"`
+	GroundSpeed  *int                   `json:"ground_speed,omitempty"`
+	Track        *int                   `json:"track,omitempty"`
+	VerticalRate *int                   `json:"vertical_rate,omitempty"`
+	AircraftType string                 `json:"aircraft_type,omitempty"`
+	Category     AircraftCategory       `json:"category,omitempty"`
+	Operator     string                 `json:"operator,omitempty"`
+	OnGround     *bool                  `json:"on_ground"`
+	Squawk       string                 `json:"squawk,omitempty"`
+	Emergency    string                 `json:"emergency,omitempty"`
 	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+	SeenAt       time.Time              `json:"seen_at,omitempty"` // Observation time; zero means now
+	Watchlisted  *bool                  `json:"watchlisted,omitempty"` // Watchlist match; false clears watchlist tags, nil keeps them
 }
 
 // AircraftListOptions represents options for listing aircraft.
@@ -100,6 +112,7 @@ type AircraftListOptions struct {
 	Category  AircraftCategory `json:"category,omitempty"`
 	ClusterID string           `json:"cluster_id,omitempty"`
 	OnGround  *bool            `json:"on_ground,omitempty"`
+	SeenAfter *time.Time       `json:"seen_after,omitempty"` // Only aircraft reported since
 	// Bounding box for geographic filtering
 	MinLat *float64 `json:"min_lat,omitempty"`
 	MaxLat *float64 `json:"max_lat,omitempty"`
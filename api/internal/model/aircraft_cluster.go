@@ -1,10 +1,26 @@
 package model
 
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
 // ClusterAircraftInput represents a clustering request payload.
 type ClusterAircraftInput struct {
 	Points        []ClusterPoint `json:"points"`
 	EpsilonMeters float64        `json:"eps_meters"` // Neighborhood radius in meters
 	MinPoints     int            `json:"min_points"` // Minimum points to form a cluster
+
+	// UseHistory clusters the points' recent positions from aircraft
+	// history (ST-DBSCAN) instead of their current positions, so aircraft
+	// must be near each other at the same time, and MinPoints counts other
+	// aircraft rather than positions. Points are matched to history by ICAO
+	// address.
+	UseHistory      bool `json:"use_history,omitempty"`
+	HistorySeconds  int  `json:"history_seconds,omitempty"` // How far back to look
+	EpsilonSeconds  int  `json:"eps_seconds,omitempty"`     // Temporal neighborhood
+	EpsilonAltitude int  `json:"eps_altitude,omitempty"`    // Vertical neighborhood in feet; 0 ignores altitude
 }
 
 // ClusterPoint represents an aircraft point to be clustered.
@@ -34,3 +50,17 @@ type ClusterResult struct {
 type ClusterResponse struct {
 	Clusters []ClusterResult `json:"clusters"`
 }
+
+// AircraftCluster is a cluster of aircraft followed across clustering runs
+// under a stable ID, the one stored in Aircraft.ClusterID.
+type AircraftCluster struct {
+	ID           string      `json:"id"`
+	CentroidLat  float64     `json:"centroid_lat"`
+	CentroidLng  float64     `json:"centroid_lng"`
+	Size         int         `json:"size"`
+	AircraftIDs  []uuid.UUID `json:"aircraft_ids"`
+	MediaPresent bool        `json:"media_present"`
+	FirstSeenAt  time.Time   `json:"first_seen_at"`
+	LastSeenAt   time.Time   `json:"last_seen_at"`
+	EndedAt      *time.Time  `json:"ended_at,omitempty"` // Merged into another cluster or dissolved
+}
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKeyScope grants a machine client access to a group of endpoints.
+type APIKeyScope string
+
+const (
+	ScopeChasesWrite    APIKeyScope = "chases:write"
+	ScopeAircraftIngest APIKeyScope = "aircraft:ingest"
+	ScopePushBroadcast  APIKeyScope = "push:broadcast"
+	ScopeChatTokens     APIKeyScope = "chat:tokens"
+)
+
+// Valid reports whether s is a known scope.
+func (s APIKeyScope) Valid() bool {
+	switch s {
+	case ScopeChasesWrite, ScopeAircraftIngest, ScopePushBroadcast, ScopeChatTokens:
+		return true
+	}
+	return false
+}
+
+// APIKey is a machine client credential. The key itself is only returned
+// when it is issued.
+type APIKey struct {
+	ID        uuid.UUID     `json:"id"`
+	Name      string        `json:"name"`
+	Prefix    string        `json:"prefix"`
+	Scopes    []APIKeyScope `json:"scopes"`
+	CreatedBy *uuid.UUID    `json:"created_by,omitempty"`
+	ExpiresAt *time.Time    `json:"expires_at,omitempty"`
+	RevokedAt *time.Time    `json:"revoked_at,omitempty"`
+
+	LastUsedAt   *time.Time `json:"last_used_at,omitempty"`
+	RequestCount int64      `json:"request_count"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// HasScope reports whether the key grants scope.
+func (k *APIKey) HasScope(scope APIKeyScope) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+// CreateAPIKeyInput represents the input for issuing an API key.
+type CreateAPIKeyInput struct {
+	Name      string        `json:"name" validate:"required"`
+	Scopes    []APIKeyScope `json:"scopes" validate:"required"`
+	ExpiresAt *time.Time    `json:"expires_at,omitempty"`
+}
+
+// IssuedAPIKey is returned once when a key is created.
+type IssuedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
@@ -42,6 +42,7 @@ type Chase struct {
 	State       string     `json:"state,omitempty"`
 	Country     string     `json:"country,omitempty"`
 	Live        bool       `json:"live"`
+	Draft       bool       `json:"draft,omitempty"` // Hidden until published by staff
 	StartedAt   *time.Time `json:"started_at,omitempty"`
 	EndedAt     *time.Time `json:"ended_at,omitempty"`
 
@@ -72,6 +73,7 @@ type CreateChaseInput struct {
 	State        string                 `json:"state,omitempty"`
 	Country      string                 `json:"country,omitempty"`
 	Live         bool                   `json:"live"`
+	Draft        bool                   `json:"draft,omitempty"`
 	ThumbnailURL string                 `json:"thumbnail_url,omitempty"`
 	Streams      []Stream               `json:"streams,omitempty"`
 	Source       string                 `json:"source,omitempty"`
@@ -87,6 +89,7 @@ type UpdateChaseInput struct {
 	City         *string                `json:"city,omitempty"`
 	State        *string                `json:"state,omitempty"`
 	Live         *bool                  `json:"live,omitempty"`
+	Draft        *bool                  `json:"draft,omitempty"` // false publishes a draft
 	ThumbnailURL *string                `json:"thumbnail_url,omitempty"`
 	Streams      []Stream               `json:"streams,omitempty"`
 	Metadata     map[string]interface{} `json:"metadata,omitempty"`
@@ -97,6 +100,7 @@ type ChaseListOptions struct {
 	Page      int       `json:"page"`
 	Limit     int       `json:"limit"`
 	Live      *bool     `json:"live,omitempty"`
+	Drafts    bool      `json:"drafts,omitempty"` // List drafts instead of published chases
 	ChaseType ChaseType `json:"chase_type,omitempty"`
 	City      string    `json:"city,omitempty"`
 	State     string    `json:"state,omitempty"`
@@ -0,0 +1,107 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ChatRole is a user's role in chat, which determines their token permissions.
+type ChatRole string
+
+const (
+	ChatRoleViewer    ChatRole = "viewer"
+	ChatRoleModerator ChatRole = "moderator"
+	ChatRoleAdmin     ChatRole = "admin"
+)
+
+// Valid reports whether r is a known chat role.
+func (r ChatRole) Valid() bool {
+	switch r {
+	case ChatRoleViewer, ChatRoleModerator, ChatRoleAdmin:
+		return true
+	}
+	return false
+}
+
+// Rank orders chat roles from least to most privileged.
+func (r ChatRole) Rank() int {
+	switch r {
+	case ChatRoleAdmin:
+		return 2
+	case ChatRoleModerator:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Chat permissions carried in chat tokens.
+const (
+	ChatPermRead     = "chat:read"
+	ChatPermWrite    = "chat:write"
+	ChatPermModerate = "chat:moderate" // delete messages, mute and ban
+	ChatPermAdmin    = "chat:admin"    // manage channels
+)
+
+// Permissions returns the chat permissions granted to r.
+func (r ChatRole) Permissions() []string {
+	switch r {
+	case ChatRoleAdmin:
+		return []string{ChatPermRead, ChatPermWrite, ChatPermModerate, ChatPermAdmin}
+	case ChatRoleModerator:
+		return []string{ChatPermRead, ChatPermWrite, ChatPermModerate}
+	default:
+		return []string{ChatPermRead, ChatPermWrite}
+	}
+}
+
+// ChatChannel is the chat room attached to a chase.
+type ChatChannel struct {
+	ID        uuid.UUID `json:"id"`
+	ChaseID   uuid.UUID `json:"chase_id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ChatSanction is a moderation action taken against a user.
+type ChatSanction string
+
+const (
+	ChatSanctionMute ChatSanction = "mute" // may read but not write
+	ChatSanctionBan  ChatSanction = "ban"  // may not join
+)
+
+// ChatModerationAction records a mute or ban. A nil ChannelID applies to
+// every channel.
+type ChatModerationAction struct {
+	ID          uuid.UUID    `json:"id"`
+	ChannelID   *uuid.UUID   `json:"channel_id,omitempty"`
+	UserID      uuid.UUID    `json:"user_id"`
+	Action      ChatSanction `json:"action"`
+	Reason      string       `json:"reason,omitempty"`
+	ModeratorID *uuid.UUID   `json:"moderator_id,omitempty"`
+	ExpiresAt   *time.Time   `json:"expires_at,omitempty"`
+	LiftedAt    *time.Time   `json:"lifted_at,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
+
+// CreateChatModerationInput represents the input for muting or banning a user.
+type CreateChatModerationInput struct {
+	UserID   uuid.UUID    `json:"user_id" validate:"required"`
+	Action   ChatSanction `json:"action" validate:"required,oneof=mute ban"`
+	Reason   string       `json:"reason,omitempty"`
+	Duration string       `json:"duration,omitempty"` // e.g. "10m"; empty is permanent
+	Global   bool         `json:"global,omitempty"`   // apply to every channel
+}
+
+// ChatToken is an issued chat JWT, tracked by jti so it can be revoked.
+type ChatToken struct {
+	ID            uuid.UUID  `json:"jti"`
+	UserID        uuid.UUID  `json:"user_id"`
+	ChannelID     *uuid.UUID `json:"channel_id,omitempty"`
+	IssuedAt      time.Time  `json:"issued_at"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	RevokedAt     *time.Time `json:"revoked_at,omitempty"`
+	RevokedReason string     `json:"revoked_reason,omitempty"`
+}
@@ -0,0 +1,73 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RecipientStatus is the delivery outcome for one user of a notification.
+type RecipientStatus string
+
+const (
+	RecipientDelivered RecipientStatus = "delivered" // at least one device accepted it
+	RecipientFailed    RecipientStatus = "failed"    // every device send failed
+	RecipientFiltered  RecipientStatus = "filtered"  // suppressed by notification preferences
+)
+
+// Notification is a record of a dispatched push notification and its
+// delivery receipts.
+type Notification struct {
+	ID       uuid.UUID  `json:"id"`
+	Title    string     `json:"title"`
+	Body     string     `json:"body,omitempty"`
+	ImageURL string     `json:"image_url,omitempty"`
+	ChaseID  *uuid.UUID `json:"chase_id,omitempty"`
+	Topic    string     `json:"topic"`
+	Private  bool       `json:"private,omitempty"` // addressed to chosen users or an area
+
+	TargetCount      int              `json:"target_count"`
+	FilteredCount    int              `json:"filtered_count"`
+	DeactivatedCount int              `json:"deactivated_count"`
+	Succeeded        map[Platform]int `json:"succeeded"`
+	Failed           map[Platform]int `json:"failed"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// InboxNotification is a notification as seen by one recipient.
+type InboxNotification struct {
+	Notification
+	Status RecipientStatus `json:"status"`
+	Read   bool            `json:"read"`
+	ReadAt *time.Time      `json:"read_at,omitempty"`
+}
+
+// NotificationListOptions represents options for listing notifications.
+type NotificationListOptions struct {
+	Page       int        `json:"page"`
+	Limit      int        `json:"limit"`
+	Topic      string     `json:"topic,omitempty"`
+	ChaseID    *uuid.UUID `json:"chase_id,omitempty"`
+	UnreadOnly bool       `json:"unread_only,omitempty"` // inbox only
+	Private    bool       `json:"private,omitempty"`     // include private notifications (staff only)
+}
+
+// NotificationListResult represents a paginated list of notifications.
+type NotificationListResult struct {
+	Notifications []Notification `json:"notifications"`
+	Total         int            `json:"total"`
+	Page          int            `json:"page"`
+	Limit         int            `json:"limit"`
+	TotalPages    int            `json:"total_pages"`
+}
+
+// InboxResult represents a paginated page of a user's inbox.
+type InboxResult struct {
+	Notifications []InboxNotification `json:"notifications"`
+	Unread        int                 `json:"unread"`
+	Total         int                 `json:"total"`
+	Page          int                 `json:"page"`
+	Limit         int                 `json:"limit"`
+	TotalPages    int                 `json:"total_pages"`
+}
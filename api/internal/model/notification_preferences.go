@@ -0,0 +1,47 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QuietHours suppresses notifications between two local wall-clock times.
+// Start after End wraps past midnight, e.g. 22:00 to 07:00.
+type QuietHours struct {
+	Start    string `json:"start"`    // HH:MM
+	End      string `json:"end"`      // HH:MM
+	Timezone string `json:"timezone"` // IANA zone, e.g. America/Los_Angeles
+}
+
+// NotificationPreferences holds a user's push notification filters. Empty
+// filters allow everything.
+type NotificationPreferences struct {
+	UserID uuid.UUID `json:"user_id"`
+
+	// Enabled mirrors users.notifications_enabled.
+	Enabled bool `json:"enabled"`
+
+	ChaseTypes []ChaseType `json:"chase_types"`
+
+	// Geographic filters; a chase matches if it is within RadiusKm of
+	// HomeLocation or in one of States.
+	HomeLocation *Location `json:"home_location,omitempty"`
+	RadiusKm     float64   `json:"radius_km,omitempty"`
+	States       []string  `json:"states"`
+
+	QuietHours *QuietHours `json:"quiet_hours,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// UpdateNotificationPreferencesInput replaces a user's notification preferences.
+type UpdateNotificationPreferencesInput struct {
+	Enabled      *bool       `json:"enabled,omitempty"`
+	ChaseTypes   []ChaseType `json:"chase_types,omitempty"`
+	HomeLocation *Location   `json:"home_location,omitempty"`
+	RadiusKm     float64     `json:"radius_km,omitempty" validate:"gte=0"`
+	States       []string    `json:"states,omitempty"`
+	QuietHours   *QuietHours `json:"quiet_hours,omitempty"`
+}
@@ -16,6 +16,17 @@ const (
 	PlatformSafari  Platform = "safari"
 )
 
+// Metadata keys holding a web push subscription's encryption keys. The
+// subscription endpoint is stored as the token itself.
+const (
+	WebPushKeyP256dh = "p256dh"
+	WebPushKeyAuth   = "auth"
+)
+
+// SafariAuthTokenHash is the metadata key holding the SHA-256 of the
+// authentication token a Safari device registered with.
+const SafariAuthTokenHash = "auth_token_sha256"
+
 // PushToken represents a device's push notification token.
 type PushToken struct {
 	ID     uuid.UUID  `json:"id"`
@@ -52,6 +63,9 @@ type CreatePushTokenInput struct {
 	DeviceName string   `json:"device_name,omitempty"`
 	AppVersion string   `json:"app_version,omitempty"`
 	Topics     []string `json:"topics,omitempty"`
+
+	// Metadata is merged into the stored token metadata, e.g. web push keys.
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
 }
 
 // UpdatePushTokenInput represents the input for updating a push token.
@@ -61,3 +75,16 @@ type UpdatePushTokenInput struct {
 	Topics     []string `json:"topics,omitempty"`
 	IsActive   *bool    `json:"is_active,omitempty"`
 }
+
+// PushTokenFilter selects active push tokens. Set criteria are combined;
+// a token must match all of them.
+type PushTokenFilter struct {
+	Platforms []Platform
+	Topic     string
+	UserIDs   []uuid.UUID
+
+	// Near and RadiusKm match tokens whose owner's home location, from
+	// their notification preferences, lies within the radius.
+	Near     *Location
+	RadiusKm float64
+}
@@ -14,8 +14,29 @@ const (
 	AuthProviderApple    AuthProvider = "apple"
 	AuthProviderFacebook AuthProvider = "facebook"
 	AuthProviderTwitter  AuthProvider = "twitter"
+	// AuthProviderOIDC is recorded when the identity provider is not known,
+	// e.g. for accounts provisioned from a verified JWT.
+	AuthProviderOIDC AuthProvider = "oidc"
 )
 
+// Role is an authorization role granted to a user.
+type Role string
+
+const (
+	RoleAdmin     Role = "admin"
+	RoleModerator Role = "moderator"
+	RoleUser      Role = "user"
+)
+
+// Valid reports whether r is a known role.
+func (r Role) Valid() bool {
+	switch r {
+	case RoleAdmin, RoleModerator, RoleUser:
+		return true
+	}
+	return false
+}
+
 // User represents a user account.
 type User struct {
 	ID                   uuid.UUID    `json:"id"`
@@ -25,6 +46,7 @@ type User struct {
 	PhotoURL             string       `json:"photo_url,omitempty"`
 	Provider             AuthProvider `json:"provider"`
 	NotificationsEnabled bool         `json:"notifications_enabled"`
+	Roles                []Role       `json:"roles"`
 	CreatedAt            time.Time    `json:"created_at"`
 	UpdatedAt            time.Time    `json:"updated_at"`
 	LastLoginAt          *time.Time   `json:"last_login_at,omitempty"`
@@ -37,7 +59,7 @@ type CreateUserInput struct {
 	Email       string       `json:"email,omitempty" validate:"omitempty,email"`
 	DisplayName string       `json:"display_name,omitempty"`
 	PhotoURL    string       `json:"photo_url,omitempty"`
-	Provider    AuthProvider `json:"provider" validate:"required,oneof=google apple facebook twitter"`
+	Provider    AuthProvider `json:"provider" validate:"required,oneof=google apple facebook twitter oidc"`
 }
 
 // UpdateUserInput represents the input for updating a user.
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WatchlistEntry identifies a known aircraft, such as a news or police
+// helicopter, by ICAO address and/or tail number. Ingested aircraft that
+// match an entry take its category and operator.
+type WatchlistEntry struct {
+	ID           uuid.UUID        `json:"id"`
+	ICAO         string           `json:"icao,omitempty"`         // 24-bit hex address, upper case
+	Registration string           `json:"registration,omitempty"` // Tail number, upper case
+	Group        string           `json:"group,omitempty"`        // e.g. station or agency: "KTLA", "LAPD"
+	Category     AircraftCategory `json:"category"`
+	ImageURL     string           `json:"image_url,omitempty"`
+	Operator     string           `json:"operator,omitempty"`
+	CreatedAt    time.Time        `json:"created_at"`
+	UpdatedAt    time.Time        `json:"updated_at"`
+}
+
+// CreateWatchlistEntryInput represents the input for adding a watchlist entry.
+type CreateWatchlistEntryInput struct {
+	ICAO         string           `json:"icao,omitempty"`
+	Registration string           `json:"registration,omitempty"`
+	Group        string           `json:"group,omitempty"`
+	Category     AircraftCategory `json:"category" validate:"required"`
+	ImageURL     string           `json:"image_url,omitempty"`
+	Operator     string           `json:"operator,omitempty"`
+}
+
+// UpdateWatchlistEntryInput represents the input for updating a watchlist entry.
+type UpdateWatchlistEntryInput struct {
+	ICAO         *string           `json:"icao,omitempty"`
+	Registration *string           `json:"registration,omitempty"`
+	Group        *string           `json:"group,omitempty"`
+	Category     *AircraftCategory `json:"category,omitempty"`
+	ImageURL     *string           `json:"image_url,omitempty"`
+	Operator     *string           `json:"operator,omitempty"`
+}
+
+// WatchlistListOptions represents options for listing watchlist entries.
+type WatchlistListOptions struct {
+	Category AircraftCategory `json:"category,omitempty"`
+	Group    string           `json:"group,omitempty"`
+}
@@ -6,8 +6,8 @@ import (
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,46 +15,68 @@ import (
 	"chaseapp.tv/api/internal/config"
 )
 
-const apnsURL = "https://api.push.apple.com/3/device/"
+const (
+	apnsProductionURL = "https://api.push.apple.com"
+	apnsSandboxURL    = "https://api.sandbox.push.apple.com"
+)
 
-// APNsClient sends notifications to Apple Push Notification service.
+// APNsClient sends notifications to Apple Push Notification service using
+// token-based (.p8) provider authentication.
 type APNsClient struct {
 	httpClient *http.Client
+	baseURL    string
+	tokens     *apnsTokenSource
 	cfg        config.PushConfig
 }
 
-// NewAPNsClient creates a new APNs client using a P8 key converted to PKCS#12.
-// NOTE: This is a simplified placeholder that relies on APNsKeyPath being a PKCS#12/P8 client cert.
+// NewAPNsClient creates a new APNs client that signs ES256 provider tokens
+// with the .p8 key at APNsKeyPath.
 func NewAPNsClient(cfg config.PushConfig) (*APNsClient, error) {
 	if cfg.APNsKeyPath == "" || cfg.APNsKeyID == "" || cfg.APNsTeamID == "" || cfg.APNsBundleID == "" {
 		return nil, fmt.Errorf("apns configuration missing")
 	}
 
-	// Load client certificate (for sandbox/dev you may use JWT; here we expect a TLS client cert).
-	cert, err := tls.LoadX509KeyPair(cfg.APNsKeyPath, cfg.APNsKeyPath)
+	tokens, err := newAPNsTokenSource(cfg.APNsKeyPath, cfg.APNsKeyID, cfg.APNsTeamID)
 	if err != nil {
-		return nil, fmt.Errorf("load apns key pair: %w", err)
+		return nil, err
 	}
 
-	tlsConfig := &tls.Config{
-		Certificates: []tls.Certificate{cert},
-		MinVersion:   tls.VersionTLS12,
+	baseURL := apnsProductionURL
+	if cfg.APNsSandbox {
+		baseURL = apnsSandboxURL
 	}
 
 	return &APNsClient{
 		httpClient: &http.Client{
-			Timeout:   10 * time.Second,
-			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+			Timeout: 10 * time.Second,
+			Transport: &http.Transport{
+				ForceAttemptHTTP2: true, // APNs only speaks HTTP/2
+				TLSClientConfig:   &tls.Config{MinVersion: tls.VersionTLS12},
+			},
 		},
-		cfg: cfg,
+		baseURL: baseURL,
+		tokens:  tokens,
+		cfg:     cfg,
 	}, nil
 }
 
+// APNs push types.
+const (
+	APNsPushTypeAlert      = "alert"
+	APNsPushTypeBackground = "background"
+)
+
 // APNsMessage is the simplified payload for APNs.
 type APNsMessage struct {
-	Title string `json:"title"`
-	Body  string `json:"body"`
-	Topic string `json:"-"`
+	Title   string   `json:"title"`
+	Body    string   `json:"body"`
+	Topic   string   `json:"-"`
+	URLArgs []string `json:"-"` // Safari urlFormatString arguments
+
+	PushType   string         `json:"-"` // apns-push-type, defaults to alert
+	Priority   int            `json:"-"` // apns-priority, 10 immediate or 5 power-considerate
+	CollapseID string         `json:"-"` // apns-collapse-id, replaces earlier notifications with the same ID
+	Data       map[string]any `json:"-"` // custom top-level payload keys, e.g. chase_id
 }
 
 // Send pushes a notification to a specific device token.
@@ -63,22 +85,42 @@ func (c *APNsClient) Send(ctx context.Context, deviceToken string, msg APNsMessa
 		return fmt.Errorf("device token is required")
 	}
 
-	payload := map[string]any{
-		"aps": map[string]any{
-			"alert": map[string]string{
-				"title": msg.Title,
-				"body":  msg.Body,
-			},
-			"sound": "default",
-		},
+	pushType := msg.PushType
+	if pushType == "" {
+		pushType = APNsPushTypeAlert
+	}
+
+	aps := map[string]any{}
+	if pushType == APNsPushTypeBackground {
+		aps["content-available"] = 1
+	} else {
+		aps["alert"] = map[string]string{
+			"title": msg.Title,
+			"body":  msg.Body,
+		}
+		aps["sound"] = "default"
 	}
+	if len(msg.URLArgs) > 0 {
+		aps["url-args"] = msg.URLArgs
+	}
+
+	payload := make(map[string]any, len(msg.Data)+1)
+	for k, v := range msg.Data {
+		payload[k] = v
+	}
+	payload["aps"] = aps
 
 	body, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("marshal payload: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apnsURL+deviceToken, io.NopCloser(bytes.NewReader(body)))
+	bearer, err := c.tokens.Token(time.Now())
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/3/device/"+deviceToken, bytes.NewReader(body))
 	if err != nil {
 		return fmt.Errorf("build request: %w", err)
 	}
@@ -88,8 +130,16 @@ func (c *APNsClient) Send(ctx context.Context, deviceToken string, msg APNsMessa
 		topic = c.cfg.APNsBundleID
 	}
 
+	req.Header.Set("authorization", "bearer "+bearer)
 	req.Header.Set("apns-topic", topic)
 	req.Header.Set("apns-id", uuid.New().String())
+	req.Header.Set("apns-push-type", pushType)
+	if msg.Priority != 0 {
+		req.Header.Set("apns-priority", strconv.Itoa(msg.Priority))
+	}
+	if msg.CollapseID != "" {
+		req.Header.Set("apns-collapse-id", msg.CollapseID)
+	}
 	req.Header.Set("content-type", "application/json")
 
 	resp, err := c.httpClient.Do(req)
@@ -99,7 +149,11 @@ func (c *APNsClient) Send(ctx context.Context, deviceToken string, msg APNsMessa
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 300 {
-		return fmt.Errorf("apns returned status %d", resp.StatusCode)
+		perr := apnsError(resp)
+		if perr.Reason == "ExpiredProviderToken" {
+			c.tokens.Invalidate()
+		}
+		return perr
 	}
 
 	return nil
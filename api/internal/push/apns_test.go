@@ -0,0 +1,131 @@
+package push
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"chaseapp.tv/api/internal/config"
+)
+
+func writeTestP8(t *testing.T) (*ecdsa.PrivateKey, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "AuthKey_TEST.p8")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+	return key, path
+}
+
+func verifyES256(t *testing.T, pub *ecdsa.PublicKey, token string) map[string]any {
+	t.Helper()
+	parts := strings.Split(token, ".")
+	require.Len(t, parts, 3)
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	require.NoError(t, err)
+	require.Len(t, sig, 64)
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	require.True(t, ecdsa.Verify(pub, digest[:], new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])))
+
+	var header map[string]any
+	raw, _ := base64.RawURLEncoding.DecodeString(parts[0])
+	require.NoError(t, json.Unmarshal(raw, &header))
+	require.Equal(t, "ES256", header["alg"])
+
+	var claims map[string]any
+	raw, _ = base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(t, json.Unmarshal(raw, &claims))
+	claims["kid"] = header["kid"]
+	return claims
+}
+
+func TestAPNsSendSignsProviderToken(t *testing.T) {
+	key, path := writeTestP8(t)
+	client, err := NewAPNsClient(config.PushConfig{
+		APNsKeyPath:  path,
+		APNsKeyID:    "KEY123",
+		APNsTeamID:   "TEAM456",
+		APNsBundleID: "tv.chaseapp.ios",
+		APNsSandbox:  true,
+	})
+	require.NoError(t, err)
+
+	var reqs []*http.Request
+	var payload map[string]any
+	client.httpClient = &http.Client{Transport: stubTransport(func(r *http.Request) (*http.Response, error) {
+		reqs = append(reqs, r)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		return stubResponse(http.StatusOK, "", nil), nil
+	})}
+
+	msg := APNsMessage{
+		Title:      "Pursuit",
+		Body:       "Live now",
+		Priority:   10,
+		CollapseID: "chase-1",
+		Data:       map[string]any{"chase_id": "chase-1"},
+	}
+	require.NoError(t, client.Send(context.Background(), "devtoken", msg))
+	require.NoError(t, client.Send(context.Background(), "devtoken", msg))
+	require.Len(t, reqs, 2)
+
+	req := reqs[0]
+	require.Equal(t, "api.sandbox.push.apple.com", req.URL.Host)
+	require.Equal(t, "/3/device/devtoken", req.URL.Path)
+	require.Equal(t, "tv.chaseapp.ios", req.Header.Get("apns-topic"))
+	require.Equal(t, "alert", req.Header.Get("apns-push-type"))
+	require.Equal(t, "10", req.Header.Get("apns-priority"))
+	require.Equal(t, "chase-1", req.Header.Get("apns-collapse-id"))
+	require.Equal(t, "chase-1", payload["chase_id"])
+	require.Contains(t, payload, "aps")
+
+	bearer := strings.TrimPrefix(req.Header.Get("authorization"), "bearer ")
+	claims := verifyES256(t, &key.PublicKey, bearer)
+	require.Equal(t, "TEAM456", claims["iss"])
+	require.Equal(t, "KEY123", claims["kid"])
+
+	// The provider token is cached between requests.
+	require.Equal(t, req.Header.Get("authorization"), reqs[1].Header.Get("authorization"))
+}
+
+func TestAPNsTokenRotation(t *testing.T) {
+	_, path := writeTestP8(t)
+	src, err := newAPNsTokenSource(path, "KEY123", "TEAM456")
+	require.NoError(t, err)
+
+	start := time.Unix(1_700_000_000, 0)
+	first, err := src.Token(start)
+	require.NoError(t, err)
+
+	same, err := src.Token(start.Add(30 * time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, first, same)
+
+	// Invalidation is deferred until Apple's minimum refresh interval.
+	src.Invalidate()
+	same, err = src.Token(start.Add(10 * time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, first, same)
+
+	rotated, err := src.Token(start.Add(41 * time.Minute))
+	require.NoError(t, err)
+	require.NotEqual(t, first, rotated)
+}
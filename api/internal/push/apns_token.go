@@ -0,0 +1,98 @@
+package push
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// apnsTokenRefresh is when a cached provider token is re-signed. Apple
+	// rejects tokens older than an hour and throttles refreshes more frequent
+	// than every 20 minutes.
+	apnsTokenRefresh = 40 * time.Minute
+	// apnsTokenMinAge prevents forced refreshes from tripping
+	// TooManyProviderTokenUpdates.
+	apnsTokenMinAge = 20 * time.Minute
+)
+
+// apnsTokenSource signs and caches APNs ES256 provider tokens.
+type apnsTokenSource struct {
+	key    *ecdsa.PrivateKey
+	keyID  string
+	teamID string
+
+	mu       sync.Mutex
+	token    string
+	issuedAt time.Time
+	stale    bool
+}
+
+// newAPNsTokenSource loads a PKCS#8 .p8 signing key from disk.
+func newAPNsTokenSource(keyPath, keyID, teamID string) (*apnsTokenSource, error) {
+	raw, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("read apns key: %w", err)
+	}
+	key, err := parseAPNsKey(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &apnsTokenSource{key: key, keyID: keyID, teamID: teamID}, nil
+}
+
+func parseAPNsKey(raw []byte) (*ecdsa.PrivateKey, error) {
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, errors.New("apns key is not PEM encoded")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse apns key: %w", err)
+	}
+	key, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("apns key must be an ECDSA P-256 key")
+	}
+	return key, nil
+}
+
+// Token returns a cached provider token, signing a new one when it is older
+// than apnsTokenRefresh or has been invalidated.
+func (s *apnsTokenSource) Token(now time.Time) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	age := now.Sub(s.issuedAt)
+	if s.token != "" && age < apnsTokenRefresh && !(s.stale && age >= apnsTokenMinAge) {
+		return s.token, nil
+	}
+
+	token, err := s.sign(now)
+	if err != nil {
+		return "", err
+	}
+	s.token = token
+	s.issuedAt = now
+	s.stale = false
+	return token, nil
+}
+
+// Invalidate marks the cached token for refresh once Apple allows it.
+func (s *apnsTokenSource) Invalidate() {
+	s.mu.Lock()
+	s.stale = true
+	s.mu.Unlock()
+}
+
+func (s *apnsTokenSource) sign(now time.Time) (string, error) {
+	return signES256(s.key,
+		map[string]string{"alg": "ES256", "kid": s.keyID},
+		map[string]any{"iss": s.teamID, "iat": now.Unix()},
+	)
+}
@@ -0,0 +1,108 @@
+package push
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+
+	"chaseapp.tv/api/internal/model"
+)
+
+// BroadcastTopic is the history topic recorded for broadcasts that are not
+// addressed to a subscription topic.
+const BroadcastTopic = "broadcast"
+
+// BroadcastTarget selects the recipients of a manual broadcast. Set criteria
+// are combined; a device must match all of them.
+type BroadcastTarget struct {
+	Topic     string
+	Platforms []model.Platform
+	UserIDs   []uuid.UUID
+	Area      *BroadcastArea
+}
+
+// BroadcastArea targets users whose home location is within RadiusKm of Center.
+type BroadcastArea struct {
+	Center   model.Location
+	RadiusKm float64
+}
+
+// BroadcastPreview is the resolved audience of a broadcast, returned for dry runs.
+type BroadcastPreview struct {
+	Recipients map[model.Platform]int `json:"recipients"`
+	Total      int                    `json:"total"`
+	Users      int                    `json:"users"`
+	Filtered   int                    `json:"filtered"` // skipped by user preferences
+}
+
+// Preview resolves the recipients of a broadcast without sending it.
+func (d *Dispatcher) Preview(ctx context.Context, n Notification, target BroadcastTarget) (*BroadcastPreview, error) {
+	targets, filtered, err := d.resolve(ctx, n, target)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &BroadcastPreview{
+		Recipients: map[model.Platform]int{},
+		Total:      len(targets),
+		Filtered:   len(filtered),
+	}
+	users := map[uuid.UUID]bool{}
+	for _, t := range targets {
+		preview.Recipients[t.Platform]++
+		if t.UserID != nil {
+			users[*t.UserID] = true
+		}
+	}
+	preview.Users = len(users)
+	if d.publishesNtfy(target) {
+		preview.Recipients[PlatformNtfy]++
+		preview.Total++
+	}
+	return preview, nil
+}
+
+// resolve returns the tokens target selects, split into those the owners'
+// preferences allow and those they filter out.
+func (d *Dispatcher) resolve(ctx context.Context, n Notification, target BroadcastTarget) (targets, filtered []model.PushToken, err error) {
+	var platforms []model.Platform
+	for _, platform := range tokenPlatforms {
+		if !d.supports(platform) {
+			continue
+		}
+		if len(target.Platforms) > 0 && !slices.Contains(target.Platforms, platform) {
+			continue
+		}
+		platforms = append(platforms, platform)
+	}
+	if len(platforms) == 0 {
+		return nil, nil, nil
+	}
+
+	filter := model.PushTokenFilter{
+		Platforms: platforms,
+		Topic:     target.Topic,
+		UserIDs:   target.UserIDs,
+	}
+	if target.Area != nil {
+		filter.Near = &target.Area.Center
+		filter.RadiusKm = target.Area.RadiusKm
+	}
+
+	tokens, err := d.tokens.Find(ctx, filter)
+	if err != nil {
+		return nil, nil, fmt.Errorf("resolve tokens: %w", err)
+	}
+	return d.filterByPreferences(ctx, tokens, n)
+}
+
+// publishesNtfy reports whether a broadcast to target also goes to the ntfy
+// topic. ntfy has no per-user addressing, so only whole-topic broadcasts do.
+func (d *Dispatcher) publishesNtfy(target BroadcastTarget) bool {
+	if d.ntfy == nil || target.Topic == "" || len(target.UserIDs) > 0 || target.Area != nil {
+		return false
+	}
+	return len(target.Platforms) == 0 || slices.Contains(target.Platforms, PlatformNtfy)
+}
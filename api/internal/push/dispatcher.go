@@ -0,0 +1,513 @@
+package push
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"chaseapp.tv/api/internal/config"
+	"chaseapp.tv/api/internal/model"
+	"chaseapp.tv/api/internal/repository"
+)
+
+// PlatformNtfy is the result key used for topic-wide ntfy publishes, which are
+// not backed by individual device tokens.
+const PlatformNtfy model.Platform = "ntfy"
+
+// maxRetryWait caps a single backoff, including provider Retry-After hints.
+const maxRetryWait = 30 * time.Second
+
+// tokenPlatforms lists the token platforms the dispatcher resolves per topic.
+var tokenPlatforms = []model.Platform{
+	model.PlatformIOS,
+	model.PlatformAndroid,
+	model.PlatformSafari,
+	model.PlatformWeb,
+}
+
+// Notification is a provider-agnostic push message. ChaseType, Location and
+// State describe the chase for per-user preference filtering.
+type Notification struct {
+	Title    string
+	Body     string
+	Topic    string
+	ChaseID  string
+	ImageURL string
+
+	ChaseType model.ChaseType
+	Location  *model.Location
+	State     string
+}
+
+// DeliveryResult summarises a dispatch across platforms.
+type DeliveryResult struct {
+	Targeted    int                    `json:"targeted"`
+	Succeeded   map[model.Platform]int `json:"succeeded"`
+	Failed      map[model.Platform]int `json:"failed"`
+	Deactivated int                    `json:"deactivated"`
+	Filtered    int                    `json:"filtered"` // skipped by user preferences
+
+	// NotificationID identifies the history record, when one was stored.
+	NotificationID *uuid.UUID `json:"notification_id,omitempty"`
+
+	// recipients is the per-user outcome recorded in the user's inbox.
+	recipients map[uuid.UUID]model.RecipientStatus
+}
+
+func newDeliveryResult() *DeliveryResult {
+	return &DeliveryResult{
+		Succeeded:  map[model.Platform]int{},
+		Failed:     map[model.Platform]int{},
+		recipients: map[uuid.UUID]model.RecipientStatus{},
+	}
+}
+
+// recordRecipient merges a token outcome into its owner's status; a user is
+// delivered if any of their devices accepted the notification.
+func (r *DeliveryResult) recordRecipient(userID *uuid.UUID, status model.RecipientStatus) {
+	if userID == nil {
+		return
+	}
+	switch r.recipients[*userID] {
+	case model.RecipientDelivered:
+		return
+	case model.RecipientFailed:
+		if status == model.RecipientFiltered {
+			return
+		}
+	}
+	r.recipients[*userID] = status
+}
+
+// TopicForChase maps a chase type to the subscription topic clients register for.
+func TopicForChase(chaseType model.ChaseType) string {
+	switch chaseType {
+	case model.ChaseTypeRocket:
+		return "rockets"
+	case model.ChaseTypeWeather:
+		return "weather"
+	case model.ChaseTypeAircraft:
+		return "aircraft"
+	default:
+		return "chases"
+	}
+}
+
+// ChaseNotification builds the notification announcing a chase.
+func ChaseNotification(chase *model.Chase) Notification {
+	return Notification{
+		Title:    chase.Title,
+		Body:     chase.Description,
+		Topic:    TopicForChase(chase.ChaseType),
+		ChaseID:  chase.ID.String(),
+		ImageURL: chase.ThumbnailURL,
+
+		ChaseType: chase.ChaseType,
+		Location:  chase.Location,
+		State:     chase.State,
+	}
+}
+
+// Dispatcher resolves subscribed tokens and delivers notifications through
+// the configured providers.
+type Dispatcher struct {
+	tokens  *repository.PushTokenRepository
+	prefs   *repository.NotificationPreferencesRepository
+	history *repository.NotificationRepository
+	apns    *APNsClient
+	fcm     *FCMClient
+	webpush *WebPushClient
+	ntfy    *NtfyClient
+	cfg     config.PushConfig
+	logger  *slog.Logger
+}
+
+// NewDispatcher creates a Dispatcher. Providers that are not configured are
+// skipped rather than failing startup.
+func NewDispatcher(cfg config.PushConfig, tokens *repository.PushTokenRepository, prefs *repository.NotificationPreferencesRepository, history *repository.NotificationRepository, logger *slog.Logger) *Dispatcher {
+	d := &Dispatcher{
+		tokens:  tokens,
+		prefs:   prefs,
+		history: history,
+		cfg:     cfg,
+		logger:  logger,
+	}
+
+	if c, err := NewAPNsClient(cfg); err != nil {
+		logger.Warn("apns delivery disabled", slog.Any("error", err))
+	} else {
+		d.apns = c
+	}
+	if c, err := NewFCMClient(cfg); err != nil {
+		logger.Warn("fcm delivery disabled", slog.Any("error", err))
+	} else {
+		d.fcm = c
+	}
+	if c, err := NewWebPushClient(cfg); err != nil {
+		logger.Warn("web push delivery disabled", slog.Any("error", err))
+	} else {
+		d.webpush = c
+	}
+	if c, err := NewNtfyClient(cfg); err != nil {
+		logger.Warn("ntfy delivery disabled", slog.Any("error", err))
+	} else {
+		d.ntfy = c
+	}
+
+	return d
+}
+
+// DispatchTopic delivers n to every active token subscribed to n.Topic whose
+// owner's preferences allow it, and publishes it to the matching ntfy topic.
+// An error is only returned when recipients could not be resolved;
+// individual send failures are counted.
+func (d *Dispatcher) DispatchTopic(ctx context.Context, n Notification) (*DeliveryResult, error) {
+	if n.Topic == "" {
+		return nil, errors.New("notification topic is required")
+	}
+	return d.Broadcast(ctx, n, BroadcastTarget{Topic: n.Topic})
+}
+
+// Broadcast delivers n to the devices selected by target and records it in
+// the notification history. n.Topic defaults to target.Topic, or
+// BroadcastTopic when the target is not a topic.
+func (d *Dispatcher) Broadcast(ctx context.Context, n Notification, target BroadcastTarget) (*DeliveryResult, error) {
+	n = broadcastNotification(n, target)
+	targets, filtered, err := d.resolve(ctx, n, target)
+	if err != nil {
+		return nil, err
+	}
+	return d.deliverBroadcast(ctx, uuid.New(), n, target, targets, filtered), nil
+}
+
+// StartBroadcast resolves the recipients as Broadcast does, then delivers in
+// the background, detached from ctx so that a client disconnect or write
+// timeout cannot stop a large broadcast partway. It returns the ID the
+// dispatch is recorded under once delivery finishes.
+func (d *Dispatcher) StartBroadcast(ctx context.Context, n Notification, target BroadcastTarget) (uuid.UUID, error) {
+	n = broadcastNotification(n, target)
+	targets, filtered, err := d.resolve(ctx, n, target)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	id := uuid.New()
+	go func() {
+		result := d.deliverBroadcast(context.WithoutCancel(ctx), id, n, target, targets, filtered)
+		d.logger.Info("broadcast delivered",
+			slog.String("notification_id", id.String()),
+			slog.String("topic", n.Topic),
+			slog.Int("targeted", result.Targeted),
+			slog.Any("succeeded", result.Succeeded),
+			slog.Any("failed", result.Failed),
+		)
+	}()
+	return id, nil
+}
+
+// broadcastNotification applies the topic defaults of Broadcast.
+func broadcastNotification(n Notification, target BroadcastTarget) Notification {
+	if n.Topic == "" {
+		n.Topic = target.Topic
+	}
+	if n.Topic == "" {
+		n.Topic = BroadcastTopic
+	}
+	return n
+}
+
+// deliverBroadcast sends a resolved broadcast and records it under id.
+func (d *Dispatcher) deliverBroadcast(ctx context.Context, id uuid.UUID, n Notification, target BroadcastTarget, targets, filtered []model.PushToken) *DeliveryResult {
+	result := d.Deliver(ctx, targets, n)
+	result.Filtered = len(filtered)
+	for _, t := range filtered {
+		result.recordRecipient(t.UserID, model.RecipientFiltered)
+	}
+
+	if d.publishesNtfy(target) {
+		result.Targeted++
+		if err := d.ntfy.Publish(ctx, target.Topic, n.Title, n.Body); err != nil {
+			d.logger.Warn("ntfy publish failed", slog.Any("error", err), slog.String("topic", target.Topic))
+			result.Failed[PlatformNtfy]++
+		} else {
+			result.Succeeded[PlatformNtfy]++
+		}
+	}
+
+	d.record(ctx, id, n, target, result)
+	return result
+}
+
+// record stores the dispatch in the notification history. Failures are only
+// logged: the notification has already been sent. The record is written
+// even if ctx has been cancelled since.
+func (d *Dispatcher) record(ctx context.Context, id uuid.UUID, n Notification, target BroadcastTarget, result *DeliveryResult) {
+	if d.history == nil {
+		return
+	}
+
+	entry := &model.Notification{
+		ID:               id,
+		Title:            n.Title,
+		Body:             n.Body,
+		ImageURL:         n.ImageURL,
+		Topic:            n.Topic,
+		Private:          len(target.UserIDs) > 0 || target.Area != nil,
+		TargetCount:      result.Targeted,
+		FilteredCount:    result.Filtered,
+		DeactivatedCount: result.Deactivated,
+		Succeeded:        result.Succeeded,
+		Failed:           result.Failed,
+	}
+	if id, err := uuid.Parse(n.ChaseID); err == nil {
+		entry.ChaseID = &id
+	}
+
+	if err := d.history.Create(context.WithoutCancel(ctx), entry, result.recipients); err != nil {
+		d.logger.Warn("failed to record notification history", slog.Any("error", err), slog.String("topic", n.Topic))
+		return
+	}
+	result.NotificationID = &entry.ID
+}
+
+// Deliver sends n to each token, bounded by the configured concurrency.
+// Transient failures are retried; tokens the provider reports as permanently
+// invalid are deactivated.
+func (d *Dispatcher) Deliver(ctx context.Context, tokens []model.PushToken, n Notification) *DeliveryResult {
+	result := newDeliveryResult()
+	result.Targeted = len(tokens)
+
+	limit := d.cfg.DeliveryConcurrency
+	if limit <= 0 {
+		limit = 1
+	}
+	sem := make(chan struct{}, limit)
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, token := range tokens {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(token model.PushToken) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			err := d.sendWithRetry(ctx, token, n)
+			deactivate := err != nil && IsPermanent(err)
+
+			mu.Lock()
+			if err != nil {
+				result.Failed[token.Platform]++
+				result.recordRecipient(token.UserID, model.RecipientFailed)
+			} else {
+				result.Succeeded[token.Platform]++
+				result.recordRecipient(token.UserID, model.RecipientDelivered)
+			}
+			if deactivate {
+				result.Deactivated++
+			}
+			mu.Unlock()
+
+			if deactivate {
+				d.logger.Info("deactivating invalid push token",
+					slog.Any("reason", err),
+					slog.String("token_id", token.ID.String()),
+					slog.String("platform", string(token.Platform)),
+				)
+				if err := d.tokens.Deactivate(ctx, token.ID); err != nil {
+					d.logger.Warn("failed to deactivate push token", slog.Any("error", err), slog.String("token_id", token.ID.String()))
+				}
+				return
+			}
+			if err != nil {
+				d.logger.Warn("push delivery failed",
+					slog.Any("error", err),
+					slog.String("token_id", token.ID.String()),
+					slog.String("platform", string(token.Platform)),
+				)
+				return
+			}
+			if err := d.tokens.UpdateLastUsed(ctx, token.ID); err != nil {
+				d.logger.Warn("failed to record token use", slog.Any("error", err), slog.String("token_id", token.ID.String()))
+			}
+		}(token)
+	}
+	wg.Wait()
+
+	return result
+}
+
+// supports reports whether a provider is configured for the platform.
+func (d *Dispatcher) supports(platform model.Platform) bool {
+	switch platform {
+	case model.PlatformIOS:
+		return d.apns != nil
+	case model.PlatformSafari:
+		return d.apns != nil && d.cfg.SafariPushID != ""
+	case model.PlatformAndroid:
+		return d.fcm != nil
+	case model.PlatformWeb:
+		return d.webpush != nil
+	default:
+		return false
+	}
+}
+
+// sendWithRetry retries transient provider and transport failures with
+// exponential backoff, honouring Retry-After when the provider sends one.
+func (d *Dispatcher) sendWithRetry(ctx context.Context, token model.PushToken, n Notification) error {
+	for attempt := 0; ; attempt++ {
+		err := d.send(ctx, token, n)
+		if err == nil || attempt >= d.cfg.DeliveryMaxRetries || !retryable(err) {
+			return err
+		}
+
+		wait := backoff(d.cfg.DeliveryRetryBackoff, attempt)
+		var perr *ProviderError
+		if errors.As(err, &perr) && perr.RetryAfter > wait {
+			wait = min(perr.RetryAfter, maxRetryWait)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// retryable reports whether err is worth another attempt.
+func retryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var perr *ProviderError
+	if errors.As(err, &perr) {
+		return perr.Temporary()
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// backoff returns the jittered exponential delay before retry attempt+1.
+func backoff(base time.Duration, attempt int) time.Duration {
+	if base <= 0 {
+		base = 500 * time.Millisecond
+	}
+	wait := base << attempt
+	if wait <= 0 || wait > maxRetryWait {
+		wait = maxRetryWait
+	}
+	return wait/2 + rand.N(wait/2+1)
+}
+
+func (d *Dispatcher) send(ctx context.Context, token model.PushToken, n Notification) error {
+	if !d.supports(token.Platform) {
+		return fmt.Errorf("no provider configured for platform %s", token.Platform)
+	}
+
+	switch token.Platform {
+	case model.PlatformIOS:
+		return d.apns.Send(ctx, token.Token, APNsMessage{
+			Title:      n.Title,
+			Body:       n.Body,
+			Priority:   10,
+			CollapseID: n.ChaseID,
+			Data:       n.apnsData(),
+		})
+	case model.PlatformSafari:
+		return d.apns.Send(ctx, token.Token, APNsMessage{
+			Title:    n.Title,
+			Body:     n.Body,
+			Topic:    d.cfg.SafariPushID,
+			URLArgs:  []string{n.ChaseID},
+			Priority: 10,
+		})
+	case model.PlatformAndroid:
+		return d.fcm.Send(ctx, FCMMessage{
+			Token:       token.Token,
+			Title:       n.Title,
+			Body:        n.Body,
+			ImageURL:    n.ImageURL,
+			Data:        n.data(),
+			CollapseKey: n.ChaseID,
+			Android:     &FCMAndroidConfig{Priority: "high"},
+		})
+	case model.PlatformWeb:
+		sub, err := webPushSubscription(token)
+		if err != nil {
+			return err
+		}
+		payload, err := json.Marshal(n.webPayload())
+		if err != nil {
+			return fmt.Errorf("marshal web push payload: %w", err)
+		}
+		return d.webpush.Send(ctx, sub, payload, WebPushOptions{
+			Urgency: WebPushUrgencyHigh,
+			Topic:   strings.ReplaceAll(n.ChaseID, "-", ""),
+		})
+	}
+	return fmt.Errorf("unsupported platform %s", token.Platform)
+}
+
+// webPushSubscription rebuilds a browser subscription from a web token, whose
+// encryption keys are kept in the token metadata. Tokens registered before
+// endpoints were restricted to known push services are deactivated.
+func webPushSubscription(token model.PushToken) (WebPushSubscription, error) {
+	if err := ValidateWebPushEndpoint(token.Token); err != nil {
+		return WebPushSubscription{}, &ProviderError{Provider: "webpush", StatusCode: http.StatusGone, Reason: "endpoint not allowed"}
+	}
+	p256dh, _ := token.Metadata[model.WebPushKeyP256dh].(string)
+	auth, _ := token.Metadata[model.WebPushKeyAuth].(string)
+	if p256dh == "" || auth == "" {
+		return WebPushSubscription{}, &ProviderError{Provider: "webpush", StatusCode: http.StatusGone, Reason: "subscription keys missing"}
+	}
+	return WebPushSubscription{Endpoint: token.Token, P256dh: p256dh, Auth: auth}, nil
+}
+
+// data returns the key/value payload attached to data-capable providers.
+func (n Notification) data() map[string]string {
+	data := map[string]string{"topic": n.Topic}
+	if n.ChaseID != "" {
+		data["chase_id"] = n.ChaseID
+	}
+	if n.ImageURL != "" {
+		data["image"] = n.ImageURL
+	}
+	return data
+}
+
+// webPayload returns the JSON document handed to the service worker's push event.
+func (n Notification) webPayload() map[string]any {
+	payload := map[string]any{
+		"title": n.Title,
+		"body":  n.Body,
+	}
+	for k, v := range n.data() {
+		payload[k] = v
+	}
+	return payload
+}
+
+// apnsData returns the custom payload keys sent alongside the aps dictionary.
+func (n Notification) apnsData() map[string]any {
+	data := make(map[string]any)
+	for k, v := range n.data() {
+		data[k] = v
+	}
+	return data
+}
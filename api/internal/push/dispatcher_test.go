@@ -0,0 +1,53 @@
+package push
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"chaseapp.tv/api/internal/config"
+	"chaseapp.tv/api/internal/model"
+)
+
+func TestChaseNotificationTopic(t *testing.T) {
+	chase := &model.Chase{
+		ID:           uuid.New(),
+		Title:        "Falcon 9",
+		Description:  "Starlink launch",
+		ChaseType:    model.ChaseTypeRocket,
+		ThumbnailURL: "https://chaseapp.tv/img.png",
+	}
+
+	n := ChaseNotification(chase)
+	require.Equal(t, "rockets", n.Topic)
+	require.Equal(t, chase.ID.String(), n.ChaseID)
+	require.Equal(t, map[string]string{
+		"topic":    "rockets",
+		"chase_id": chase.ID.String(),
+		"image":    chase.ThumbnailURL,
+	}, n.data())
+
+	require.Equal(t, "chases", TopicForChase(model.ChaseTypeChase))
+	require.Equal(t, "chases", TopicForChase(""))
+}
+
+func TestDeliverCountsUnconfiguredPlatformsAsFailed(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	d := NewDispatcher(config.PushConfig{DeliveryConcurrency: 2}, nil, nil, nil, logger)
+
+	tokens := []model.PushToken{
+		{ID: uuid.New(), Token: "a", Platform: model.PlatformIOS},
+		{ID: uuid.New(), Token: "b", Platform: model.PlatformAndroid},
+		{ID: uuid.New(), Token: "c", Platform: model.PlatformAndroid},
+	}
+
+	result := d.Deliver(context.Background(), tokens, Notification{Title: "t", Topic: "chases"})
+	require.Equal(t, 3, result.Targeted)
+	require.Equal(t, 1, result.Failed[model.PlatformIOS])
+	require.Equal(t, 2, result.Failed[model.PlatformAndroid])
+	require.Empty(t, result.Succeeded)
+}
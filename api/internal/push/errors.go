@@ -0,0 +1,144 @@
+package push
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ProviderError is returned when a push provider rejects a request. It keeps
+// the provider's reason so callers can tell dead tokens from outages.
+type ProviderError struct {
+	Provider   string
+	StatusCode int
+	Reason     string
+	Field      string // Request field the provider rejected, if it named one
+	RetryAfter time.Duration
+}
+
+func (e *ProviderError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("%s returned status %d", e.Provider, e.StatusCode)
+	}
+	return fmt.Sprintf("%s returned status %d: %s", e.Provider, e.StatusCode, e.Reason)
+}
+
+// Permanent reports whether the device token will never be accepted again
+// and should be deactivated.
+func (e *ProviderError) Permanent() bool {
+	switch e.Provider {
+	case "apns":
+		switch e.Reason {
+		case "Unregistered", "BadDeviceToken", "DeviceTokenNotForTopic", "ExpiredToken":
+			return true
+		}
+		return e.StatusCode == http.StatusGone
+	case "fcm":
+		switch e.Reason {
+		case "UNREGISTERED", "SENDER_ID_MISMATCH":
+			return true
+		case "INVALID_ARGUMENT":
+			// Also returned for a bad payload, which says nothing about
+			// the token.
+			return e.Field == "message.token"
+		}
+	case "webpush":
+		// Push services answer 404/410 once a subscription has expired or
+		// the user revoked permission.
+		return e.StatusCode == http.StatusNotFound || e.StatusCode == http.StatusGone
+	}
+	return false
+}
+
+// Temporary reports whether the same request may succeed if retried.
+func (e *ProviderError) Temporary() bool {
+	if e.Permanent() {
+		return false
+	}
+	if e.Provider == "apns" && e.Reason == "ExpiredProviderToken" {
+		return true // the client re-signs its provider token
+	}
+	switch e.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError,
+		http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// IsPermanent reports whether err marks the target token as permanently invalid.
+func IsPermanent(err error) bool {
+	var perr *ProviderError
+	return errors.As(err, &perr) && perr.Permanent()
+}
+
+// apnsError decodes an APNs error response.
+func apnsError(resp *http.Response) *ProviderError {
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&body)
+	return &ProviderError{
+		Provider:   "apns",
+		StatusCode: resp.StatusCode,
+		Reason:     body.Reason,
+		RetryAfter: retryAfter(resp),
+	}
+}
+
+// fcmError decodes an FCM HTTP v1 error response, preferring the FCM-specific
+// error code from details over the generic RPC status, and noting the first
+// field a BadRequest detail names.
+func fcmError(resp *http.Response) *ProviderError {
+	var body struct {
+		Error struct {
+			Status  string `json:"status"`
+			Details []struct {
+				Type            string `json:"@type"`
+				ErrorCode       string `json:"errorCode"`
+				FieldViolations []struct {
+					Field string `json:"field"`
+				} `json:"fieldViolations"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&body)
+
+	reason, field := body.Error.Status, ""
+	for _, d := range body.Error.Details {
+		if d.ErrorCode != "" && reason == body.Error.Status {
+			reason = d.ErrorCode
+		}
+		if len(d.FieldViolations) > 0 && field == "" {
+			field = d.FieldViolations[0].Field
+		}
+	}
+	return &ProviderError{
+		Provider:   "fcm",
+		StatusCode: resp.StatusCode,
+		Reason:     reason,
+		Field:      field,
+		RetryAfter: retryAfter(resp),
+	}
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date.
+func retryAfter(resp *http.Response) time.Duration {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
@@ -0,0 +1,127 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"chaseapp.tv/api/internal/config"
+	"chaseapp.tv/api/internal/model"
+)
+
+type stubTransport func(*http.Request) (*http.Response, error)
+
+func (s stubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return s(req)
+}
+
+func stubResponse(status int, body string, header http.Header) *http.Response {
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		StatusCode: status,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Header:     header,
+	}
+}
+
+func TestAPNsErrorClassification(t *testing.T) {
+	err := apnsError(stubResponse(http.StatusGone, `{"reason":"Unregistered","timestamp":1700000000000}`, nil))
+	require.Equal(t, "Unregistered", err.Reason)
+	require.True(t, err.Permanent())
+	require.False(t, err.Temporary())
+
+	err = apnsError(stubResponse(http.StatusServiceUnavailable, `{"reason":"ServiceUnavailable"}`, http.Header{"Retry-After": {"7"}}))
+	require.False(t, err.Permanent())
+	require.True(t, err.Temporary())
+	require.Equal(t, 7*time.Second, err.RetryAfter)
+
+	err = apnsError(stubResponse(http.StatusForbidden, `{"reason":"InvalidProviderToken"}`, nil))
+	require.False(t, err.Permanent())
+	require.False(t, err.Temporary())
+}
+
+func TestFCMErrorPrefersFCMErrorCode(t *testing.T) {
+	body := `{"error":{"code":404,"status":"NOT_FOUND","details":[
+		{"@type":"type.googleapis.com/google.firebase.fcm.v1.FcmError","errorCode":"UNREGISTERED"}]}}`
+	err := fcmError(stubResponse(http.StatusNotFound, body, nil))
+	require.Equal(t, "UNREGISTERED", err.Reason)
+	require.True(t, IsPermanent(err))
+
+	err = fcmError(stubResponse(http.StatusServiceUnavailable, `{"error":{"status":"UNAVAILABLE"}}`, nil))
+	require.Equal(t, "UNAVAILABLE", err.Reason)
+	require.True(t, err.Temporary())
+}
+
+func TestFCMInvalidArgument(t *testing.T) {
+	token := `{"error":{"code":400,"status":"INVALID_ARGUMENT","details":[
+		{"@type":"type.googleapis.com/google.firebase.fcm.v1.FcmError","errorCode":"INVALID_ARGUMENT"},
+		{"@type":"type.googleapis.com/google.rpc.BadRequest","fieldViolations":[{"field":"message.token","description":"The registration token is not a valid FCM registration token"}]}]}}`
+	err := fcmError(stubResponse(http.StatusBadRequest, token, nil))
+	require.Equal(t, "message.token", err.Field)
+	require.True(t, IsPermanent(err))
+
+	// A bad payload is the message's fault; the token stays active and the
+	// send is not retried.
+	payload := `{"error":{"code":400,"status":"INVALID_ARGUMENT","details":[
+		{"@type":"type.googleapis.com/google.firebase.fcm.v1.FcmError","errorCode":"INVALID_ARGUMENT"},
+		{"@type":"type.googleapis.com/google.rpc.BadRequest","fieldViolations":[{"field":"message.android.notification.image","description":"Invalid image URL"}]}]}}`
+	err = fcmError(stubResponse(http.StatusBadRequest, payload, nil))
+	require.Equal(t, "INVALID_ARGUMENT", err.Reason)
+	require.False(t, IsPermanent(err))
+	require.False(t, err.Temporary())
+
+	err = fcmError(stubResponse(http.StatusBadRequest, `{"error":{"status":"INVALID_ARGUMENT","details":[{"errorCode":"INVALID_ARGUMENT"}]}}`, nil))
+	require.False(t, IsPermanent(err))
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	calls := 0
+	d := NewDispatcher(config.PushConfig{DeliveryMaxRetries: 2, DeliveryRetryBackoff: time.Millisecond}, nil, nil, nil, logger)
+	d.fcm = newTestFCMClient(stubTransport(func(*http.Request) (*http.Response, error) {
+		calls++
+		return stubResponse(http.StatusBadRequest, payload, nil), nil
+	}))
+	tokens := []model.PushToken{{Token: "tok", Platform: model.PlatformAndroid}}
+	result := d.Deliver(context.Background(), tokens, Notification{Title: "t", Topic: "chases"})
+	require.Equal(t, 1, result.Failed[model.PlatformAndroid])
+	require.Zero(t, result.Deactivated)
+	require.Equal(t, 1, calls)
+}
+
+func TestSendWithRetryRetriesTransientFailures(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	cfg := config.PushConfig{DeliveryMaxRetries: 2, DeliveryRetryBackoff: time.Millisecond}
+
+	calls := 0
+	d := NewDispatcher(cfg, nil, nil, nil, logger)
+	d.fcm = newTestFCMClient(stubTransport(func(*http.Request) (*http.Response, error) {
+		calls++
+		if calls < 3 {
+			return stubResponse(http.StatusServiceUnavailable, `{"error":{"status":"UNAVAILABLE"}}`, nil), nil
+		}
+		return stubResponse(http.StatusOK, `{"name":"projects/p/messages/1"}`, nil), nil
+	}))
+
+	token := model.PushToken{Token: "tok", Platform: model.PlatformAndroid}
+	require.NoError(t, d.sendWithRetry(context.Background(), token, Notification{Title: "t", Topic: "chases"}))
+	require.Equal(t, 3, calls)
+
+	calls = 0
+	d.fcm.client.Transport = stubTransport(func(*http.Request) (*http.Response, error) {
+		calls++
+		return stubResponse(http.StatusNotFound, `{"error":{"status":"NOT_FOUND","details":[{"errorCode":"UNREGISTERED"}]}}`, nil), nil
+	})
+	err := d.sendWithRetry(context.Background(), token, Notification{Title: "t", Topic: "chases"})
+	var perr *ProviderError
+	require.True(t, errors.As(err, &perr))
+	require.True(t, perr.Permanent())
+	require.Equal(t, 1, calls)
+}
@@ -6,77 +6,156 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"chaseapp.tv/api/internal/config"
 )
 
-const fcmEndpoint = "https://fcm.googleapis.com/v1/projects/%s/messages:send"
+const fcmBaseURL = "https://fcm.googleapis.com"
 
-// FCMClient sends push notifications via FCM HTTP v1 API.
+// FCMClient sends push notifications via FCM HTTP v1 API, authenticating
+// with OAuth2 access tokens minted from a service account.
 type FCMClient struct {
-	client *http.Client
-	cfg    config.PushConfig
+	client    *http.Client
+	baseURL   string
+	projectID string
+	tokens    *oauthTokenSource
+	cfg       config.PushConfig
 }
 
-// NewFCMClient creates a new FCM client.
+// NewFCMClient creates a new FCM client from the service-account JSON at
+// FCMKeyPath. FCMProjectID defaults to the service account's project.
 func NewFCMClient(cfg config.PushConfig) (*FCMClient, error) {
-	if cfg.FCMProjectID == "" || cfg.FCMKeyPath == "" {
+	if cfg.FCMKeyPath == "" {
 		return nil, fmt.Errorf("fcm configuration missing")
 	}
 
-	// TODO: load service account and mint OAuth2 token (placeholder for now).
+	account, key, err := loadServiceAccount(cfg.FCMKeyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	projectID := cfg.FCMProjectID
+	if projectID == "" {
+		projectID = account.ProjectID
+	}
+	if projectID == "" {
+		return nil, fmt.Errorf("fcm project id missing")
+	}
+
+	baseURL := cfg.FCMBaseURL
+	if baseURL == "" {
+		baseURL = fcmBaseURL
+	}
 
+	client := &http.Client{Timeout: 10 * time.Second}
 	return &FCMClient{
-		client: &http.Client{Timeout: 10 * time.Second},
-		cfg:    cfg,
+		client:    client,
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		projectID: projectID,
+		tokens:    newOAuthTokenSource(account, key, cfg.FCMTokenURL, client),
+		cfg:       cfg,
 	}, nil
 }
 
-// FCMMessage represents a simplified FCM message.
+// FCMMessage represents an FCM HTTP v1 message. TTL and CollapseKey are
+// applied to every platform block unless the override already sets them.
 type FCMMessage struct {
-	Token string
-	Title string
-	Body  string
-	Topic string
-	Data  map[string]string
+	Token     string
+	Topic     string
+	Condition string
+
+	Title    string
+	Body     string
+	ImageURL string
+	Data     map[string]string
+
+	TTL         time.Duration
+	CollapseKey string
+
+	Android *FCMAndroidConfig
+	APNS    *FCMAPNSConfig
+	Webpush *FCMWebpushConfig
 }
 
-// Send pushes a notification to a token or topic.
-func (c *FCMClient) Send(ctx context.Context, msg FCMMessage) error {
-	target := map[string]any{}
-	if msg.Token != "" {
-		target["token"] = msg.Token
-	} else if msg.Topic != "" {
-		target["topic"] = msg.Topic
-	} else {
-		return fmt.Errorf("fcm requires token or topic")
-	}
+// FCMAndroidConfig is the android override block of a v1 message.
+type FCMAndroidConfig struct {
+	CollapseKey  string                  `json:"collapse_key,omitempty"`
+	Priority     string                  `json:"priority,omitempty"` // "normal" or "high"
+	TTL          string                  `json:"ttl,omitempty"`      // duration in seconds, e.g. "3600s"
+	Data         map[string]string       `json:"data,omitempty"`
+	Notification *FCMAndroidNotification `json:"notification,omitempty"`
+}
 
-	notification := map[string]string{
-		"title": msg.Title,
-		"body":  msg.Body,
-	}
+// FCMAndroidNotification customises how Android renders the notification.
+type FCMAndroidNotification struct {
+	Title       string `json:"title,omitempty"`
+	Body        string `json:"body,omitempty"`
+	Icon        string `json:"icon,omitempty"`
+	Color       string `json:"color,omitempty"`
+	Sound       string `json:"sound,omitempty"`
+	Tag         string `json:"tag,omitempty"`
+	ClickAction string `json:"click_action,omitempty"`
+	ChannelID   string `json:"channel_id,omitempty"`
+	Image       string `json:"image,omitempty"`
+}
 
-	payload := map[string]any{
-		"message": map[string]any{
-			"notification": notification,
-			"data":         msg.Data,
-		},
-	}
-	for k, v := range target {
-		payload["message"].(map[string]any)[k] = v
+// FCMAPNSConfig is the apns override block of a v1 message.
+type FCMAPNSConfig struct {
+	Headers map[string]string `json:"headers,omitempty"`
+	Payload map[string]any    `json:"payload,omitempty"`
+}
+
+// FCMWebpushConfig is the webpush override block of a v1 message.
+type FCMWebpushConfig struct {
+	Headers      map[string]string     `json:"headers,omitempty"`
+	Data         map[string]string     `json:"data,omitempty"`
+	Notification map[string]any        `json:"notification,omitempty"`
+	FCMOptions   *FCMWebpushFCMOptions `json:"fcm_options,omitempty"`
+}
+
+// FCMWebpushFCMOptions holds FCM options for web push.
+type FCMWebpushFCMOptions struct {
+	Link string `json:"link,omitempty"`
+}
+
+type fcmNotification struct {
+	Title string `json:"title,omitempty"`
+	Body  string `json:"body,omitempty"`
+	Image string `json:"image,omitempty"`
+}
+
+type fcmWireMessage struct {
+	Token        string            `json:"token,omitempty"`
+	Topic        string            `json:"topic,omitempty"`
+	Condition    string            `json:"condition,omitempty"`
+	Notification *fcmNotification  `json:"notification,omitempty"`
+	Data         map[string]string `json:"data,omitempty"`
+	Android      *FCMAndroidConfig `json:"android,omitempty"`
+	APNS         *FCMAPNSConfig    `json:"apns,omitempty"`
+	Webpush      *FCMWebpushConfig `json:"webpush,omitempty"`
+}
+
+// Send pushes a notification to a token, topic or condition.
+func (c *FCMClient) Send(ctx context.Context, msg FCMMessage) error {
+	wire, err := msg.wire(time.Now())
+	if err != nil {
+		return err
 	}
 
-	body, err := json.Marshal(payload)
+	body, err := json.Marshal(map[string]any{"message": wire})
 	if err != nil {
 		return fmt.Errorf("marshal fcm payload: %w", err)
 	}
 
-	// Placeholder bearer token. In production, mint from service account JWT.
-	bearer := "REPLACE_WITH_OAUTH_TOKEN"
+	bearer, err := c.tokens.Token(ctx)
+	if err != nil {
+		return fmt.Errorf("fcm access token: %w", err)
+	}
 
-	url := fmt.Sprintf(fcmEndpoint, c.cfg.FCMProjectID)
+	url := fmt.Sprintf("%s/v1/projects/%s/messages:send", c.baseURL, c.projectID)
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
 	if err != nil {
 		return fmt.Errorf("build request: %w", err)
@@ -91,7 +170,87 @@ func (c *FCMClient) Send(ctx context.Context, msg FCMMessage) error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 300 {
-		return fmt.Errorf("fcm returned status %d", resp.StatusCode)
+		perr := fcmError(resp)
+		if resp.StatusCode == http.StatusUnauthorized {
+			c.tokens.Invalidate()
+		}
+		return perr
 	}
 	return nil
 }
+
+// wire converts msg into the v1 JSON shape, folding TTL and CollapseKey into
+// the per-platform blocks.
+func (msg FCMMessage) wire(now time.Time) (*fcmWireMessage, error) {
+	w := &fcmWireMessage{
+		Token:     msg.Token,
+		Topic:     msg.Topic,
+		Condition: msg.Condition,
+		Data:      msg.Data,
+		Android:   msg.Android,
+		APNS:      msg.APNS,
+		Webpush:   msg.Webpush,
+	}
+	if w.Token == "" && w.Topic == "" && w.Condition == "" {
+		return nil, fmt.Errorf("fcm requires token, topic or condition")
+	}
+	if msg.Title != "" || msg.Body != "" || msg.ImageURL != "" {
+		w.Notification = &fcmNotification{Title: msg.Title, Body: msg.Body, Image: msg.ImageURL}
+	}
+
+	if msg.TTL <= 0 && msg.CollapseKey == "" {
+		return w, nil
+	}
+
+	// Copy overrides before filling defaults so callers can reuse them.
+	android := FCMAndroidConfig{}
+	if w.Android != nil {
+		android = *w.Android
+	}
+	apns := FCMAPNSConfig{}
+	if w.APNS != nil {
+		apns = *w.APNS
+	}
+	apns.Headers = cloneHeaders(apns.Headers)
+
+	if msg.TTL > 0 {
+		if android.TTL == "" {
+			android.TTL = strconv.FormatInt(int64(msg.TTL/time.Second), 10) + "s"
+		}
+		setDefault(apns.Headers, "apns-expiration", strconv.FormatInt(now.Add(msg.TTL).Unix(), 10))
+	}
+	if msg.CollapseKey != "" {
+		if android.CollapseKey == "" {
+			android.CollapseKey = msg.CollapseKey
+		}
+		setDefault(apns.Headers, "apns-collapse-id", msg.CollapseKey)
+	}
+	w.Android = &android
+	w.APNS = &apns
+
+	if msg.TTL > 0 {
+		webpush := FCMWebpushConfig{}
+		if w.Webpush != nil {
+			webpush = *w.Webpush
+		}
+		webpush.Headers = cloneHeaders(webpush.Headers)
+		setDefault(webpush.Headers, "TTL", strconv.FormatInt(int64(msg.TTL/time.Second), 10))
+		w.Webpush = &webpush
+	}
+
+	return w, nil
+}
+
+func cloneHeaders(h map[string]string) map[string]string {
+	out := make(map[string]string, len(h)+2)
+	for k, v := range h {
+		out[k] = v
+	}
+	return out
+}
+
+func setDefault(h map[string]string, key, value string) {
+	if _, ok := h[key]; !ok {
+		h[key] = value
+	}
+}
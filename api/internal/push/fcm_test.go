@@ -0,0 +1,131 @@
+package push
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"chaseapp.tv/api/internal/config"
+)
+
+// newTestFCMClient returns a client with a pre-minted access token.
+func newTestFCMClient(transport http.RoundTripper) *FCMClient {
+	return &FCMClient{
+		client:    &http.Client{Transport: transport},
+		baseURL:   fcmBaseURL,
+		projectID: "test-project",
+		tokens:    &oauthTokenSource{token: "test-token", expiresAt: time.Now().Add(time.Hour)},
+	}
+}
+
+func writeTestServiceAccount(t *testing.T, tokenURI string) (*rsa.PrivateKey, string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	raw, err := json.Marshal(serviceAccount{
+		Type:         "service_account",
+		ProjectID:    "chaseapp-test",
+		PrivateKeyID: "key-1",
+		PrivateKey:   string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		ClientEmail:  "push@chaseapp-test.iam.gserviceaccount.com",
+		TokenURI:     tokenURI,
+	})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "service-account.json")
+	require.NoError(t, os.WriteFile(path, raw, 0o600))
+	return key, path
+}
+
+func TestFCMSendMintsAndCachesAccessToken(t *testing.T) {
+	var key *rsa.PrivateKey
+	mints, sends := 0, 0
+	var message map[string]any
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			mints++
+			require.NoError(t, r.ParseForm())
+			require.Equal(t, "urn:ietf:params:oauth:grant-type:jwt-bearer", r.PostForm.Get("grant_type"))
+
+			parts := strings.Split(r.PostForm.Get("assertion"), ".")
+			require.Len(t, parts, 3)
+			sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+			require.NoError(t, err)
+			digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+			require.NoError(t, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], sig))
+
+			var claims map[string]any
+			raw, _ := base64.RawURLEncoding.DecodeString(parts[1])
+			require.NoError(t, json.Unmarshal(raw, &claims))
+			require.Equal(t, "push@chaseapp-test.iam.gserviceaccount.com", claims["iss"])
+			require.Equal(t, fcmScope, claims["scope"])
+
+			_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "ya29.test", "expires_in": 3600, "token_type": "Bearer"})
+		case "/v1/projects/chaseapp-test/messages:send":
+			sends++
+			require.Equal(t, "Bearer ya29.test", r.Header.Get("Authorization"))
+			var body map[string]map[string]any
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			message = body["message"]
+			_, _ = w.Write([]byte(`{"name":"projects/chaseapp-test/messages/1"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	key, path := writeTestServiceAccount(t, "https://oauth2.googleapis.com/token")
+	client, err := NewFCMClient(config.PushConfig{
+		FCMKeyPath:  path,
+		FCMTokenURL: srv.URL + "/token",
+		FCMBaseURL:  srv.URL,
+	})
+	require.NoError(t, err)
+
+	msg := FCMMessage{
+		Token:       "device",
+		Title:       "Pursuit",
+		Body:        "Live now",
+		Data:        map[string]string{"chase_id": "chase-1"},
+		TTL:         time.Hour,
+		CollapseKey: "chase-1",
+		Android:     &FCMAndroidConfig{Priority: "high"},
+	}
+	require.NoError(t, client.Send(context.Background(), msg))
+	require.NoError(t, client.Send(context.Background(), msg))
+	require.Equal(t, 1, mints)
+	require.Equal(t, 2, sends)
+
+	require.Equal(t, "device", message["token"])
+	android := message["android"].(map[string]any)
+	require.Equal(t, "high", android["priority"])
+	require.Equal(t, "3600s", android["ttl"])
+	require.Equal(t, "chase-1", android["collapse_key"])
+	apns := message["apns"].(map[string]any)["headers"].(map[string]any)
+	require.Equal(t, "chase-1", apns["apns-collapse-id"])
+	require.Contains(t, apns, "apns-expiration")
+	require.Equal(t, "3600", message["webpush"].(map[string]any)["headers"].(map[string]any)["TTL"])
+
+	// Tokens are re-minted once they are inside the refresh window.
+	client.tokens.expiresAt = time.Now().Add(time.Minute)
+	require.NoError(t, client.Send(context.Background(), msg))
+	require.Equal(t, 2, mints)
+}
@@ -0,0 +1,198 @@
+package push
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	googleTokenURL = "https://oauth2.googleapis.com/token"
+	fcmScope       = "https://www.googleapis.com/auth/firebase.messaging"
+
+	// fcmTokenLeeway refreshes access tokens this long before they expire.
+	fcmTokenLeeway = 5 * time.Minute
+)
+
+// serviceAccount is the subset of a Google service-account key file we use.
+type serviceAccount struct {
+	Type         string `json:"type"`
+	ProjectID    string `json:"project_id"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	ClientEmail  string `json:"client_email"`
+	TokenURI     string `json:"token_uri"`
+}
+
+// loadServiceAccount reads and validates a service-account JSON key.
+func loadServiceAccount(path string) (*serviceAccount, *rsa.PrivateKey, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read service account: %w", err)
+	}
+	var sa serviceAccount
+	if err := json.Unmarshal(raw, &sa); err != nil {
+		return nil, nil, fmt.Errorf("parse service account: %w", err)
+	}
+	if sa.ClientEmail == "" || sa.PrivateKey == "" {
+		return nil, nil, errors.New("service account is missing client_email or private_key")
+	}
+
+	block, _ := pem.Decode([]byte(sa.PrivateKey))
+	if block == nil {
+		return nil, nil, errors.New("service account private key is not PEM encoded")
+	}
+	var parsed any
+	if parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes); err != nil {
+		if parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+			return nil, nil, fmt.Errorf("parse service account key: %w", err)
+		}
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, nil, errors.New("service account key must be RSA")
+	}
+	return &sa, key, nil
+}
+
+// oauthTokenSource mints and caches OAuth2 access tokens using the
+// JWT bearer grant (RFC 7523) for a service account.
+type oauthTokenSource struct {
+	account  *serviceAccount
+	key      *rsa.PrivateKey
+	tokenURL string
+	client   *http.Client
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+func newOAuthTokenSource(account *serviceAccount, key *rsa.PrivateKey, tokenURL string, client *http.Client) *oauthTokenSource {
+	if tokenURL == "" {
+		tokenURL = account.TokenURI
+	}
+	if tokenURL == "" {
+		tokenURL = googleTokenURL
+	}
+	return &oauthTokenSource{
+		account:  account,
+		key:      key,
+		tokenURL: tokenURL,
+		client:   client,
+	}
+}
+
+// Token returns a cached access token, minting a new one shortly before expiry.
+func (s *oauthTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if s.token != "" && now.Add(fcmTokenLeeway).Before(s.expiresAt) {
+		return s.token, nil
+	}
+
+	assertion, err := s.assertion(now)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("build token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("token request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return "", fmt.Errorf("token endpoint returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var payload struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+		TokenType   string `json:"token_type"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return "", fmt.Errorf("decode token response: %w", err)
+	}
+	if payload.AccessToken == "" {
+		return "", errors.New("token endpoint returned no access_token")
+	}
+	if payload.ExpiresIn <= 0 {
+		payload.ExpiresIn = 3600
+	}
+
+	s.token = payload.AccessToken
+	s.expiresAt = now.Add(time.Duration(payload.ExpiresIn) * time.Second)
+	return s.token, nil
+}
+
+// Invalidate drops the cached access token.
+func (s *oauthTokenSource) Invalidate() {
+	s.mu.Lock()
+	s.token = ""
+	s.mu.Unlock()
+}
+
+// assertion builds the RS256-signed JWT exchanged for an access token.
+func (s *oauthTokenSource) assertion(now time.Time) (string, error) {
+	header := map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+	}
+	if s.account.PrivateKeyID != "" {
+		header["kid"] = s.account.PrivateKeyID
+	}
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return "", fmt.Errorf("marshal header: %w", err)
+	}
+	claimsJSON, err := json.Marshal(map[string]any{
+		"iss":   s.account.ClientEmail,
+		"scope": fcmScope,
+		"aud":   s.tokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("marshal claims: %w", err)
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(headerJSON) + "." +
+		base64.RawURLEncoding.EncodeToString(claimsJSON)
+
+	digest := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("sign assertion: %w", err)
+	}
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
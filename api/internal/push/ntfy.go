@@ -60,7 +60,7 @@ func (c *NtfyClient) Publish(ctx context.Context, topic, title, message string)
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 300 {
-		return fmt.Errorf("ntfy returned status %d", resp.StatusCode)
+		return &ProviderError{Provider: "ntfy", StatusCode: resp.StatusCode, RetryAfter: retryAfter(resp)}
 	}
 	return nil
 }
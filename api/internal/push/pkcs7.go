@@ -0,0 +1,165 @@
+package push
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+)
+
+var (
+	oidData            = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidSignedData      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidContentType     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidMessageDigest   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	oidSigningTime     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 5}
+	oidSHA256          = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidRSAEncryption   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+	oidECDSAWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}
+)
+
+type pkcs7AlgorithmIdentifier struct {
+	Algorithm  asn1.ObjectIdentifier
+	Parameters asn1.RawValue `asn1:"optional"`
+}
+
+type pkcs7ContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"optional"` // [0] EXPLICIT
+}
+
+type pkcs7IssuerAndSerial struct {
+	Issuer       asn1.RawValue
+	SerialNumber *big.Int
+}
+
+type pkcs7SignerInfo struct {
+	Version                   int
+	IssuerAndSerialNumber     pkcs7IssuerAndSerial
+	DigestAlgorithm           pkcs7AlgorithmIdentifier
+	AuthenticatedAttributes   asn1.RawValue
+	DigestEncryptionAlgorithm pkcs7AlgorithmIdentifier
+	EncryptedDigest           []byte
+}
+
+type pkcs7SignedData struct {
+	Version          int
+	DigestAlgorithms []pkcs7AlgorithmIdentifier `asn1:"set"`
+	ContentInfo      pkcs7ContentInfo
+	Certificates     asn1.RawValue
+	SignerInfos      []pkcs7SignerInfo `asn1:"set"`
+}
+
+type pkcs7Attribute struct {
+	Type  asn1.ObjectIdentifier
+	Value asn1.RawValue
+}
+
+// signPKCS7Detached returns a DER-encoded, detached PKCS#7 SignedData
+// signature over content, as Safari expects for push package manifests.
+// The signer certificate is embedded along with any intermediates.
+func signPKCS7Detached(content []byte, cert *x509.Certificate, key crypto.Signer, intermediates []*x509.Certificate, now time.Time) ([]byte, error) {
+	var sigAlg pkcs7AlgorithmIdentifier
+	switch key.Public().(type) {
+	case *rsa.PublicKey:
+		sigAlg = pkcs7AlgorithmIdentifier{Algorithm: oidRSAEncryption, Parameters: asn1.NullRawValue}
+	case *ecdsa.PublicKey:
+		sigAlg = pkcs7AlgorithmIdentifier{Algorithm: oidECDSAWithSHA256}
+	default:
+		return nil, errors.New("unsupported signing key type")
+	}
+
+	digest := sha256.Sum256(content)
+	attrs, err := pkcs7Attributes(
+		attributeValue(oidContentType, oidData),
+		attributeValue(oidSigningTime, now.UTC()),
+		attributeValue(oidMessageDigest, digest[:]),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// The signature covers the attributes re-tagged as a universal SET.
+	signedAttrs, err := asn1.Marshal(asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: attrs})
+	if err != nil {
+		return nil, fmt.Errorf("marshal signed attributes: %w", err)
+	}
+	attrsDigest := sha256.Sum256(signedAttrs)
+	signature, err := key.Sign(rand.Reader, attrsDigest[:], crypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("sign attributes: %w", err)
+	}
+
+	var certs []byte
+	for _, c := range append([]*x509.Certificate{cert}, intermediates...) {
+		certs = append(certs, c.Raw...)
+	}
+
+	sha256Alg := pkcs7AlgorithmIdentifier{Algorithm: oidSHA256, Parameters: asn1.NullRawValue}
+	signedData := pkcs7SignedData{
+		Version:          1,
+		DigestAlgorithms: []pkcs7AlgorithmIdentifier{sha256Alg},
+		ContentInfo:      pkcs7ContentInfo{ContentType: oidData},
+		Certificates:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: certs},
+		SignerInfos: []pkcs7SignerInfo{{
+			Version: 1,
+			IssuerAndSerialNumber: pkcs7IssuerAndSerial{
+				Issuer:       asn1.RawValue{FullBytes: cert.RawIssuer},
+				SerialNumber: cert.SerialNumber,
+			},
+			DigestAlgorithm:           sha256Alg,
+			AuthenticatedAttributes:   asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: attrs},
+			DigestEncryptionAlgorithm: sigAlg,
+			EncryptedDigest:           signature,
+		}},
+	}
+
+	inner, err := asn1.Marshal(signedData)
+	if err != nil {
+		return nil, fmt.Errorf("marshal signed data: %w", err)
+	}
+	return asn1.Marshal(pkcs7ContentInfo{
+		ContentType: oidSignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: inner},
+	})
+}
+
+type pkcs7AttributeValue struct {
+	oid   asn1.ObjectIdentifier
+	value any
+}
+
+func attributeValue(oid asn1.ObjectIdentifier, value any) pkcs7AttributeValue {
+	return pkcs7AttributeValue{oid: oid, value: value}
+}
+
+// pkcs7Attributes encodes attributes in DER SET OF order and returns the
+// concatenated contents without the outer SET header.
+func pkcs7Attributes(values ...pkcs7AttributeValue) ([]byte, error) {
+	encoded := make([][]byte, 0, len(values))
+	for _, v := range values {
+		inner, err := asn1.Marshal(v.value)
+		if err != nil {
+			return nil, fmt.Errorf("marshal attribute %s: %w", v.oid, err)
+		}
+		attr, err := asn1.Marshal(pkcs7Attribute{
+			Type:  v.oid,
+			Value: asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: inner},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("marshal attribute %s: %w", v.oid, err)
+		}
+		encoded = append(encoded, attr)
+	}
+	sort.Slice(encoded, func(i, j int) bool { return bytes.Compare(encoded[i], encoded[j]) < 0 })
+	return bytes.Join(encoded, nil), nil
+}
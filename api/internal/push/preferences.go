@@ -0,0 +1,131 @@
+package push
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"chaseapp.tv/api/internal/model"
+)
+
+// filterByPreferences splits tokens into those whose owner's notification
+// preferences allow n and those they reject. Anonymous tokens are always kept.
+func (d *Dispatcher) filterByPreferences(ctx context.Context, tokens []model.PushToken, n Notification) (kept, filtered []model.PushToken, err error) {
+	if d.prefs == nil {
+		return tokens, nil, nil
+	}
+
+	var userIDs []uuid.UUID
+	seen := map[uuid.UUID]bool{}
+	for _, t := range tokens {
+		if t.UserID != nil && !seen[*t.UserID] {
+			seen[*t.UserID] = true
+			userIDs = append(userIDs, *t.UserID)
+		}
+	}
+	if len(userIDs) == 0 {
+		return tokens, nil, nil
+	}
+
+	prefs, err := d.prefs.GetByUserIDs(ctx, userIDs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load notification preferences: %w", err)
+	}
+
+	now := time.Now()
+	for _, t := range tokens {
+		if t.UserID != nil {
+			if p, ok := prefs[*t.UserID]; ok && !preferencesAllow(p, n, now) {
+				filtered = append(filtered, t)
+				continue
+			}
+		}
+		kept = append(kept, t)
+	}
+	return kept, filtered, nil
+}
+
+// preferencesAllow reports whether p permits delivering n at now. Filters
+// only apply when the notification carries the matching attribute, so
+// broadcasts without a chase type or location are not dropped by them.
+func preferencesAllow(p *model.NotificationPreferences, n Notification, now time.Time) bool {
+	if !p.Enabled {
+		return false
+	}
+	if n.ChaseType != "" && len(p.ChaseTypes) > 0 && !slices.Contains(p.ChaseTypes, n.ChaseType) {
+		return false
+	}
+	if !matchesArea(p, n) {
+		return false
+	}
+	return !inQuietHours(p.QuietHours, now)
+}
+
+// matchesArea applies the home radius and state filters; a chase passes if
+// it satisfies either one.
+func matchesArea(p *model.NotificationPreferences, n Notification) bool {
+	radius := p.HomeLocation != nil && p.RadiusKm > 0
+	if !radius && len(p.States) == 0 {
+		return true
+	}
+	if n.Location == nil && n.State == "" {
+		return true
+	}
+
+	if radius && n.Location != nil &&
+		haversineKm(p.HomeLocation.Lat, p.HomeLocation.Lng, n.Location.Lat, n.Location.Lng) <= p.RadiusKm {
+		return true
+	}
+	if n.State != "" && slices.ContainsFunc(p.States, func(s string) bool { return strings.EqualFold(s, n.State) }) {
+		return true
+	}
+	return false
+}
+
+// inQuietHours reports whether now falls inside q in the user's timezone.
+func inQuietHours(q *model.QuietHours, now time.Time) bool {
+	if q == nil {
+		return false
+	}
+	start, okStart := parseClock(q.Start)
+	end, okEnd := parseClock(q.End)
+	if !okStart || !okEnd || start == end {
+		return false
+	}
+
+	loc, err := time.LoadLocation(q.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+
+	if start < end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end // wraps past midnight
+}
+
+// parseClock parses HH:MM into minutes past midnight.
+func parseClock(s string) (int, bool) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+// haversineKm calculates the great-circle distance between two points.
+func haversineKm(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadiusKm = 6371.0
+	dLat := (lat2 - lat1) * math.Pi / 180
+	dLon := (lon2 - lon1) * math.Pi / 180
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*math.Pi/180)*math.Cos(lat2*math.Pi/180)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return earthRadiusKm * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
@@ -0,0 +1,49 @@
+package push
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"chaseapp.tv/api/internal/model"
+)
+
+func TestPreferencesAllow(t *testing.T) {
+	la := &model.Location{Lat: 34.05, Lng: -118.24}
+	sf := &model.Location{Lat: 37.77, Lng: -122.42}
+	noon := time.Date(2024, 6, 1, 19, 0, 0, 0, time.UTC) // 12:00 in Los Angeles
+
+	tests := []struct {
+		name  string
+		prefs model.NotificationPreferences
+		n     Notification
+		now   time.Time
+		want  bool
+	}{
+		{"defaults", model.NotificationPreferences{Enabled: true}, Notification{ChaseType: model.ChaseTypeChase}, noon, true},
+		{"disabled", model.NotificationPreferences{}, Notification{}, noon, false},
+		{"type excluded", model.NotificationPreferences{Enabled: true, ChaseTypes: []model.ChaseType{model.ChaseTypeRocket}},
+			Notification{ChaseType: model.ChaseTypeChase}, noon, false},
+		{"within radius", model.NotificationPreferences{Enabled: true, HomeLocation: la, RadiusKm: 50},
+			Notification{Location: &model.Location{Lat: 34.1, Lng: -118.3}}, noon, true},
+		{"outside radius", model.NotificationPreferences{Enabled: true, HomeLocation: la, RadiusKm: 50},
+			Notification{Location: sf, State: "CA"}, noon, false},
+		{"state matches", model.NotificationPreferences{Enabled: true, HomeLocation: la, RadiusKm: 50, States: []string{"CA"}},
+			Notification{Location: sf, State: "ca"}, noon, true},
+		{"unknown location passes", model.NotificationPreferences{Enabled: true, States: []string{"TX"}},
+			Notification{}, noon, true},
+		{"quiet hours wrap midnight", model.NotificationPreferences{Enabled: true,
+			QuietHours: &model.QuietHours{Start: "22:00", End: "07:00", Timezone: "America/Los_Angeles"}},
+			Notification{}, time.Date(2024, 6, 1, 6, 30, 0, 0, time.UTC), false}, // 23:30 local
+		{"outside quiet hours", model.NotificationPreferences{Enabled: true,
+			QuietHours: &model.QuietHours{Start: "22:00", End: "07:00", Timezone: "America/Los_Angeles"}},
+			Notification{}, noon, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, preferencesAllow(&tt.prefs, tt.n, tt.now))
+		})
+	}
+}
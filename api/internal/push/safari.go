@@ -3,45 +3,123 @@ package push
 import (
 	"archive/zip"
 	"bytes"
-	"crypto/sha1" //nolint:gosec // Safari manifest requires SHA1
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"fmt"
+	"os"
 	"path"
+	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
+	"golang.org/x/crypto/pkcs12"
+
 	"chaseapp.tv/api/internal/config"
 )
 
-var iconPNG = []byte{ // 1x1 transparent PNG
-	0x89, 0x50, 0x4e, 0x47, 0x0d, 0x0a, 0x1a, 0x0a,
-	0x00, 0x00, 0x00, 0x0d, 0x49, 0x48, 0x44, 0x52,
-	0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01,
-	0x08, 0x06, 0x00, 0x00, 0x00, 0x1f, 0x15, 0xc4,
-	0x89, 0x00, 0x00, 0x00, 0x0a, 0x49, 0x44, 0x41,
-	0x54, 0x78, 0x9c, 0x63, 0x00, 0x01, 0x00, 0x00,
-	0x05, 0x00, 0x01, 0x0d, 0x0a, 0x2d, 0xb4, 0x00,
-	0x00, 0x00, 0x00, 0x49, 0x45, 0x4e, 0x44, 0xae,
-	0x42, 0x60, 0x82,
+// safariIconNames are the files Safari requires in icon.iconset.
+var safariIconNames = []string{
+	"icon_16x16.png",
+	"icon_16x16@2x.png",
+	"icon_32x32.png",
+	"icon_32x32@2x.png",
+	"icon_128x128.png",
+	"icon_128x128@2x.png",
+}
+
+// safariUserInfoTTL is how long a userInfo token can be exchanged for a
+// push package.
+const safariUserInfoTTL = 10 * time.Minute
+
+var (
+	// ErrInvalidSafariAuthToken is returned for authentication tokens that
+	// were not minted by this server.
+	ErrInvalidSafariAuthToken = errors.New("invalid safari authentication token")
+	// ErrInvalidSafariUserInfo is returned for userInfo tokens that were not
+	// minted by this server or have expired.
+	ErrInvalidSafariUserInfo = errors.New("invalid safari user info token")
+)
+
+// SafariPackager builds signed Safari push packages and mints the
+// authenticationToken Safari presents to the web service callbacks.
+type SafariPackager struct {
+	cfg           config.PushConfig
+	cert          *x509.Certificate
+	key           crypto.Signer
+	intermediates []*x509.Certificate
+	icons         map[string][]byte
 }
 
-// BuildSafariPackage creates a Safari push package ZIP in memory.
-func BuildSafariPackage(cfg config.PushConfig) ([]byte, error) {
-	if cfg.SafariPushID == "" || cfg.SafariWebURL == "" {
+// NewSafariPackager loads the website push certificate and icon set.
+func NewSafariPackager(cfg config.PushConfig) (*SafariPackager, error) {
+	if cfg.SafariPushID == "" || cfg.SafariWebURL == "" || cfg.SafariCertPath == "" || cfg.SafariAuthSecret == "" {
 		return nil, errors.New("safari push configuration missing")
 	}
 
-	files := map[string][]byte{}
+	cert, key, err := loadSafariCertificate(cfg.SafariCertPath, cfg.SafariCertPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	var intermediates []*x509.Certificate
+	if cfg.SafariIntermediatePath != "" {
+		raw, err := os.ReadFile(cfg.SafariIntermediatePath)
+		if err != nil {
+			return nil, fmt.Errorf("read safari intermediate certificate: %w", err)
+		}
+		if intermediates, err = parseCertificates(raw); err != nil {
+			return nil, fmt.Errorf("parse safari intermediate certificate: %w", err)
+		}
+	}
+
+	icons := make(map[string][]byte, len(safariIconNames))
+	for _, name := range safariIconNames {
+		raw, err := os.ReadFile(filepath.Join(cfg.SafariIconDir, name))
+		if err != nil {
+			return nil, fmt.Errorf("read safari icon: %w", err)
+		}
+		icons[path.Join("icon.iconset", name)] = raw
+	}
+
+	return &SafariPackager{
+		cfg:           cfg,
+		cert:          cert,
+		key:           key,
+		intermediates: intermediates,
+		icons:         icons,
+	}, nil
+}
+
+// Build creates a signed Safari push package ZIP in memory.
+func (p *SafariPackager) Build(authToken string) ([]byte, error) {
+	files := make(map[string][]byte, len(p.icons)+3)
+	for name, content := range p.icons {
+		files[name] = content
+	}
+
+	websiteURL := p.cfg.SafariWebsiteURL
+	if websiteURL == "" {
+		websiteURL = p.cfg.SafariWebURL
+	}
 
 	website := map[string]any{
 		"websiteName":         "ChaseApp",
-		"websitePushID":       cfg.SafariPushID,
-		"allowedDomains":      []string{cfg.SafariWebURL},
-		"urlFormatString":     cfg.SafariWebURL + "/%@",
-		"authenticationToken": "auth-token-placeholder",
-		"webServiceURL":       cfg.SafariWebURL,
-		"date":                time.Now().UTC().Format(time.RFC3339),
+		"websitePushID":       p.cfg.SafariPushID,
+		"allowedDomains":      []string{websiteURL},
+		"urlFormatString":     websiteURL + "/chase/%@",
+		"authenticationToken": authToken,
+		"webServiceURL":       p.cfg.SafariWebURL,
 	}
 	websiteJSON, err := json.Marshal(website)
 	if err != nil {
@@ -49,27 +127,17 @@ func BuildSafariPackage(cfg config.PushConfig) ([]byte, error) {
 	}
 	files["website.json"] = websiteJSON
 
-	// Minimal iconset
-	iconNames := []string{
-		"icon_16x16.png",
-		"icon_16x16@2x.png",
-		"icon_32x32.png",
-		"icon_32x32@2x.png",
-		"icon_128x128.png",
-		"icon_128x128@2x.png",
-	}
-	for _, name := range iconNames {
-		files[path.Join("icon.iconset", name)] = iconPNG
-	}
-
 	manifest, err := buildManifest(files)
 	if err != nil {
 		return nil, err
 	}
 	files["manifest.json"] = manifest
 
-	// Signature placeholder (would be PKCS7 in production)
-	files["signature"] = []byte{}
+	signature, err := signPKCS7Detached(manifest, p.cert, p.key, p.intermediates, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("sign manifest: %w", err)
+	}
+	files["signature"] = signature
 
 	buf := &bytes.Buffer{}
 	zipWriter := zip.NewWriter(buf)
@@ -89,14 +157,185 @@ func BuildSafariPackage(cfg config.PushConfig) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// AuthToken mints an authenticationToken bound to userID, or to an anonymous
+// subscriber when userID is nil. Tokens are an HMAC over the user ID and a
+// nonce, so they can be verified without storing them.
+func (p *SafariPackager) AuthToken(userID *uuid.UUID) (string, error) {
+	payload := make([]byte, 24)
+	if userID != nil {
+		copy(payload, userID[:])
+	}
+	if _, err := rand.Read(payload[16:]); err != nil {
+		return "", fmt.Errorf("generate nonce: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(p.authMAC(payload)), nil
+}
+
+// ParseAuthToken verifies an authenticationToken and returns the user it was
+// minted for, or nil for anonymous subscribers.
+func (p *SafariPackager) ParseAuthToken(token string) (*uuid.UUID, error) {
+	encPayload, encMAC, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidSafariAuthToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encPayload)
+	if err != nil || len(payload) != 24 {
+		return nil, ErrInvalidSafariAuthToken
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(encMAC)
+	if err != nil || !hmac.Equal(mac, p.authMAC(payload)) {
+		return nil, ErrInvalidSafariAuthToken
+	}
+
+	userID, _ := uuid.FromBytes(payload[:16])
+	if userID == uuid.Nil {
+		return nil, nil
+	}
+	return &userID, nil
+}
+
+// UserInfoToken mints the token a signed-in page passes as userInfo to
+// window.safari.pushNotification.requestPermission. Safari requests the push
+// package without the page's credentials, so the token is how that request
+// identifies the user. It expires after safariUserInfoTTL.
+func (p *SafariPackager) UserInfoToken(userID uuid.UUID, now time.Time) string {
+	payload := make([]byte, 24)
+	copy(payload, userID[:])
+	binary.BigEndian.PutUint64(payload[16:], uint64(now.Add(safariUserInfoTTL).Unix()))
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(p.userInfoMAC(payload))
+}
+
+// ParseUserInfoToken verifies a userInfo token and returns the user it was
+// minted for.
+func (p *SafariPackager) ParseUserInfoToken(token string, now time.Time) (uuid.UUID, error) {
+	encPayload, encMAC, ok := strings.Cut(token, ".")
+	if !ok {
+		return uuid.Nil, ErrInvalidSafariUserInfo
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encPayload)
+	if err != nil || len(payload) != 24 {
+		return uuid.Nil, ErrInvalidSafariUserInfo
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(encMAC)
+	if err != nil || !hmac.Equal(mac, p.userInfoMAC(payload)) {
+		return uuid.Nil, ErrInvalidSafariUserInfo
+	}
+	if now.Unix() > int64(binary.BigEndian.Uint64(payload[16:])) {
+		return uuid.Nil, ErrInvalidSafariUserInfo
+	}
+
+	userID, _ := uuid.FromBytes(payload[:16])
+	if userID == uuid.Nil {
+		return uuid.Nil, ErrInvalidSafariUserInfo
+	}
+	return userID, nil
+}
+
+func (p *SafariPackager) authMAC(payload []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(p.cfg.SafariAuthSecret))
+	mac.Write(payload)
+	return mac.Sum(nil)[:16]
+}
+
+// userInfoMAC is keyed separately from authMAC so that authentication and
+// userInfo tokens, which have the same shape, can't stand in for each other.
+func (p *SafariPackager) userInfoMAC(payload []byte) []byte {
+	mac := hmac.New(sha256.New, []byte("user-info:"+p.cfg.SafariAuthSecret))
+	mac.Write(payload)
+	return mac.Sum(nil)[:16]
+}
+
+// loadSafariCertificate reads the website push certificate and key from a
+// PKCS#12 bundle, or from a PEM file holding both.
+func loadSafariCertificate(certPath, password string) (*x509.Certificate, crypto.Signer, error) {
+	raw, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read safari certificate: %w", err)
+	}
+
+	var (
+		cert   *x509.Certificate
+		parsed any
+	)
+	if bytes.Contains(raw, []byte("-----BEGIN")) {
+		certs, err := parseCertificates(raw)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parse safari certificate: %w", err)
+		}
+		cert = certs[0]
+		if parsed, err = parsePEMPrivateKey(raw); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		if parsed, cert, err = pkcs12.Decode(raw, password); err != nil {
+			return nil, nil, fmt.Errorf("decode safari p12: %w", err)
+		}
+	}
+
+	key, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, nil, errors.New("safari certificate key cannot sign")
+	}
+	return cert, key, nil
+}
+
+func parseCertificates(raw []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, raw = pem.Decode(raw)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no PEM certificate found")
+	}
+	return certs, nil
+}
+
+func parsePEMPrivateKey(raw []byte) (any, error) {
+	for {
+		var block *pem.Block
+		block, raw = pem.Decode(raw)
+		if block == nil {
+			return nil, errors.New("no PEM private key found in safari certificate")
+		}
+		switch block.Type {
+		case "PRIVATE KEY":
+			return x509.ParsePKCS8PrivateKey(block.Bytes)
+		case "RSA PRIVATE KEY":
+			return x509.ParsePKCS1PrivateKey(block.Bytes)
+		case "EC PRIVATE KEY":
+			return x509.ParseECPrivateKey(block.Bytes)
+		}
+	}
+}
+
+// manifestEntry is a file hash in a version 2 push package manifest.
+type manifestEntry struct {
+	HashType  string `json:"hashType"`
+	HashValue string `json:"hashValue"`
+}
+
 func buildManifest(files map[string][]byte) ([]byte, error) {
-	manifest := make(map[string]string)
+	manifest := make(map[string]manifestEntry)
 	for name, content := range files {
 		if name == "manifest.json" || name == "signature" {
 			continue
 		}
-		h := sha1.Sum(content)
-		manifest[name] = hex.EncodeToString(h[:])
+		h := sha512.Sum512(content)
+		manifest[name] = manifestEntry{HashType: "sha512", HashValue: hex.EncodeToString(h[:])}
 	}
 	return json.Marshal(manifest)
 }
@@ -0,0 +1,166 @@
+package push
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"chaseapp.tv/api/internal/config"
+)
+
+func newTestSafariPackager(t *testing.T) (*SafariPackager, *x509.Certificate) {
+	t.Helper()
+	dir := t.TempDir()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "Website Push ID: web.tv.chaseapp"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	certPEM = append(certPEM, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})...)
+	certPath := filepath.Join(dir, "website_push.pem")
+	require.NoError(t, os.WriteFile(certPath, certPEM, 0o600))
+
+	iconDir := filepath.Join(dir, "icon.iconset")
+	require.NoError(t, os.Mkdir(iconDir, 0o755))
+	for _, name := range safariIconNames {
+		require.NoError(t, os.WriteFile(filepath.Join(iconDir, name), []byte("png:"+name), 0o600))
+	}
+
+	p, err := NewSafariPackager(config.PushConfig{
+		SafariPushID:     "web.tv.chaseapp",
+		SafariWebURL:     "https://api.chaseapp.tv/api/v1/push/safari",
+		SafariWebsiteURL: "https://chaseapp.tv",
+		SafariCertPath:   certPath,
+		SafariIconDir:    iconDir,
+		SafariAuthSecret: "test-secret",
+	})
+	require.NoError(t, err)
+	return p, cert
+}
+
+func TestSafariPackageIsSigned(t *testing.T) {
+	p, cert := newTestSafariPackager(t)
+
+	userID := uuid.New()
+	authToken, err := p.AuthToken(&userID)
+	require.NoError(t, err)
+
+	raw, err := p.Build(authToken)
+	require.NoError(t, err)
+
+	zr, err := zip.NewReader(bytes.NewReader(raw), int64(len(raw)))
+	require.NoError(t, err)
+	files := map[string][]byte{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		files[f.Name], _ = io.ReadAll(rc)
+		rc.Close()
+	}
+
+	var website map[string]any
+	require.NoError(t, json.Unmarshal(files["website.json"], &website))
+	require.Equal(t, authToken, website["authenticationToken"])
+	require.Equal(t, "https://chaseapp.tv/chase/%@", website["urlFormatString"])
+	require.Equal(t, "https://api.chaseapp.tv/api/v1/push/safari", website["webServiceURL"])
+
+	var manifest map[string]manifestEntry
+	require.NoError(t, json.Unmarshal(files["manifest.json"], &manifest))
+	require.Len(t, manifest, len(safariIconNames)+1)
+	for name, entry := range manifest {
+		h := sha512.Sum512(files[name])
+		require.Equal(t, manifestEntry{HashType: "sha512", HashValue: hex.EncodeToString(h[:])}, entry, name)
+	}
+
+	// Verify the detached PKCS#7 signature over the manifest.
+	var ci pkcs7ContentInfo
+	_, err = asn1.Unmarshal(files["signature"], &ci)
+	require.NoError(t, err)
+	require.True(t, ci.ContentType.Equal(oidSignedData))
+	var sd pkcs7SignedData
+	_, err = asn1.Unmarshal(ci.Content.Bytes, &sd)
+	require.NoError(t, err)
+	require.Equal(t, cert.Raw, sd.Certificates.Bytes)
+	require.Len(t, sd.SignerInfos, 1)
+
+	si := sd.SignerInfos[0]
+	require.Equal(t, cert.SerialNumber, si.IssuerAndSerialNumber.SerialNumber)
+	signed, err := asn1.Marshal(asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: si.AuthenticatedAttributes.Bytes})
+	require.NoError(t, err)
+	digest := sha256.Sum256(signed)
+	require.NoError(t, rsa.VerifyPKCS1v15(cert.PublicKey.(*rsa.PublicKey), crypto.SHA256, digest[:], si.EncryptedDigest))
+
+	manifestDigest := sha256.Sum256(files["manifest.json"])
+	require.True(t, bytes.Contains(si.AuthenticatedAttributes.Bytes, manifestDigest[:]))
+}
+
+func TestSafariAuthToken(t *testing.T) {
+	p, _ := newTestSafariPackager(t)
+
+	userID := uuid.New()
+	token, err := p.AuthToken(&userID)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(token), 16)
+
+	got, err := p.ParseAuthToken(token)
+	require.NoError(t, err)
+	require.Equal(t, userID, *got)
+
+	anon, err := p.AuthToken(nil)
+	require.NoError(t, err)
+	got, err = p.ParseAuthToken(anon)
+	require.NoError(t, err)
+	require.Nil(t, got)
+
+	_, err = p.ParseAuthToken(token[:len(token)-2] + "AA")
+	require.ErrorIs(t, err, ErrInvalidSafariAuthToken)
+}
+
+func TestSafariUserInfoToken(t *testing.T) {
+	p, _ := newTestSafariPackager(t)
+	now := time.Now()
+
+	userID := uuid.New()
+	token := p.UserInfoToken(userID, now)
+	got, err := p.ParseUserInfoToken(token, now.Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, userID, got)
+
+	_, err = p.ParseUserInfoToken(token, now.Add(safariUserInfoTTL+time.Second))
+	require.ErrorIs(t, err, ErrInvalidSafariUserInfo)
+
+	// Authentication tokens are not accepted as userInfo.
+	authToken, err := p.AuthToken(&userID)
+	require.NoError(t, err)
+	_, err = p.ParseUserInfoToken(authToken, now)
+	require.ErrorIs(t, err, ErrInvalidSafariUserInfo)
+}
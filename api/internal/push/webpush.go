@@ -0,0 +1,344 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"chaseapp.tv/api/internal/config"
+)
+
+const (
+	// webPushRecordSize is the aes128gcm record size; payloads are sent as a
+	// single record so the plaintext must fit in it.
+	webPushRecordSize = 4096
+	// webPushMaxPayload leaves room for the padding delimiter and GCM tag.
+	webPushMaxPayload = webPushRecordSize - 16 - 1
+	// vapidTokenTTL is how long a VAPID JWT is valid. RFC 8292 caps it at 24h.
+	vapidTokenTTL = 12 * time.Hour
+)
+
+// Web Push urgency values (RFC 8030 section 5.3).
+const (
+	WebPushUrgencyVeryLow = "very-low"
+	WebPushUrgencyLow     = "low"
+	WebPushUrgencyNormal  = "normal"
+	WebPushUrgencyHigh    = "high"
+)
+
+// webPushHosts are the push services browsers issue subscription endpoints
+// on. Entries starting with a dot match subdomains.
+var webPushHosts = []string{
+	"fcm.googleapis.com",         // Chrome, Edge, Opera
+	"android.googleapis.com",     // legacy Chrome endpoints
+	".push.services.mozilla.com", // Firefox autopush
+	".push.apple.com",            // Safari 16+
+	".notify.windows.com",        // Windows Push Notification Services
+}
+
+// ErrInvalidWebPushEndpoint is returned for subscription endpoints that are
+// not https URLs on a known push service.
+var ErrInvalidWebPushEndpoint = errors.New("invalid web push endpoint")
+
+// ValidateWebPushEndpoint checks that a subscription endpoint is an https URL
+// on a known push service. Endpoints come from clients and are posted to from
+// inside the cluster, so anything else is refused.
+func ValidateWebPushEndpoint(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme != "https" || u.User != nil {
+		return ErrInvalidWebPushEndpoint
+	}
+	if port := u.Port(); port != "" && port != "443" {
+		return ErrInvalidWebPushEndpoint
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, allowed := range webPushHosts {
+		if host == allowed || (strings.HasPrefix(allowed, ".") && strings.HasSuffix(host, allowed)) {
+			return nil
+		}
+	}
+	return ErrInvalidWebPushEndpoint
+}
+
+// publicAddressOnly refuses connections to loopback, private, link-local and
+// other non-public addresses, in case a push service host resolves to one.
+func publicAddressOnly(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	ip = ip.Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return fmt.Errorf("web push endpoint resolves to non-public address %s", ip)
+	}
+	return nil
+}
+
+// WebPushClient delivers browser push messages using RFC 8291 message
+// encryption and RFC 8292 VAPID authentication.
+type WebPushClient struct {
+	httpClient *http.Client
+	key        *ecdsa.PrivateKey
+	publicKey  string
+	subject    string
+}
+
+// NewWebPushClient creates a Web Push client from the VAPID key pair in cfg.
+func NewWebPushClient(cfg config.PushConfig) (*WebPushClient, error) {
+	if cfg.VAPIDPrivateKey == "" || cfg.VAPIDSubject == "" {
+		return nil, fmt.Errorf("web push configuration missing")
+	}
+
+	key, err := parseVAPIDKey(cfg.VAPIDPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := encodeVAPIDPublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   publicAddressOnly,
+	}).DialContext
+
+	return &WebPushClient{
+		httpClient: &http.Client{Timeout: 10 * time.Second, Transport: transport},
+		key:        key,
+		publicKey:  pub,
+		subject:    cfg.VAPIDSubject,
+	}, nil
+}
+
+// VAPIDPublicKey derives the base64url application server key browsers pass
+// to PushManager.subscribe from a VAPID private key.
+func VAPIDPublicKey(privateKey string) (string, error) {
+	key, err := parseVAPIDKey(privateKey)
+	if err != nil {
+		return "", err
+	}
+	return encodeVAPIDPublicKey(key)
+}
+
+func encodeVAPIDPublicKey(key *ecdsa.PrivateKey) (string, error) {
+	pub, err := key.PublicKey.Bytes()
+	if err != nil {
+		return "", fmt.Errorf("encode vapid public key: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(pub), nil
+}
+
+// parseVAPIDKey decodes a raw base64url P-256 private scalar, the format
+// produced by `web-push generate-vapid-keys`.
+func parseVAPIDKey(encoded string) (*ecdsa.PrivateKey, error) {
+	raw, err := decodeBase64(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("decode vapid private key: %w", err)
+	}
+	key, err := ecdsa.ParseRawPrivateKey(elliptic.P256(), raw)
+	if err != nil {
+		return nil, fmt.Errorf("parse vapid private key: %w", err)
+	}
+	return key, nil
+}
+
+// PublicKey returns the base64url-encoded uncompressed VAPID public key.
+func (c *WebPushClient) PublicKey() string {
+	return c.publicKey
+}
+
+// WebPushSubscription is a browser PushSubscription.
+type WebPushSubscription struct {
+	Endpoint string
+	P256dh   string // base64url user agent public key
+	Auth     string // base64url authentication secret
+}
+
+// WebPushOptions controls delivery of a single message.
+type WebPushOptions struct {
+	TTL     time.Duration
+	Urgency string
+	Topic   string // replaces pending messages with the same topic; max 32 base64url chars
+}
+
+// Send encrypts payload for the subscription and posts it to the push service.
+func (c *WebPushClient) Send(ctx context.Context, sub WebPushSubscription, payload []byte, opts WebPushOptions) error {
+	if err := ValidateWebPushEndpoint(sub.Endpoint); err != nil {
+		return err
+	}
+	endpoint, err := url.Parse(sub.Endpoint)
+	if err != nil {
+		return ErrInvalidWebPushEndpoint
+	}
+
+	body, err := encryptWebPush(sub, payload)
+	if err != nil {
+		return err
+	}
+
+	audience := endpoint.Scheme + "://" + endpoint.Host
+	jwt, err := signES256(c.key,
+		map[string]string{"typ": "JWT", "alg": "ES256"},
+		map[string]any{
+			"aud": audience,
+			"exp": time.Now().Add(vapidTokenTTL).Unix(),
+			"sub": c.subject,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+
+	ttl := opts.TTL
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	req.Header.Set("Authorization", "vapid t="+jwt+", k="+c.publicKey)
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", strconv.FormatInt(int64(ttl/time.Second), 10))
+	if opts.Urgency != "" {
+		req.Header.Set("Urgency", opts.Urgency)
+	}
+	if opts.Topic != "" {
+		req.Header.Set("Topic", opts.Topic)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("web push request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return &ProviderError{
+			Provider:   "webpush",
+			StatusCode: resp.StatusCode,
+			Reason:     http.StatusText(resp.StatusCode),
+			RetryAfter: retryAfter(resp),
+		}
+	}
+	return nil
+}
+
+// encryptWebPush encrypts payload as a single aes128gcm record (RFC 8188)
+// keyed per RFC 8291.
+func encryptWebPush(sub WebPushSubscription, payload []byte) ([]byte, error) {
+	if len(payload) > webPushMaxPayload {
+		return nil, fmt.Errorf("web push payload exceeds %d bytes", webPushMaxPayload)
+	}
+
+	uaPublicRaw, err := decodeBase64(sub.P256dh)
+	if err != nil {
+		return nil, fmt.Errorf("decode p256dh: %w", err)
+	}
+	authSecret, err := decodeBase64(sub.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("decode auth: %w", err)
+	}
+	if len(authSecret) != 16 {
+		return nil, errors.New("web push auth secret must be 16 bytes")
+	}
+
+	curve := ecdh.P256()
+	uaPublic, err := curve.NewPublicKey(uaPublicRaw)
+	if err != nil {
+		return nil, fmt.Errorf("parse p256dh: %w", err)
+	}
+	asPrivate, err := curve.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generate ephemeral key: %w", err)
+	}
+	asPublicRaw := asPrivate.PublicKey().Bytes()
+
+	sharedSecret, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, fmt.Errorf("ecdh: %w", err)
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("generate salt: %w", err)
+	}
+
+	// IKM = HKDF(auth_secret, ecdh_secret, "WebPush: info" || 0x00 || ua_public || as_public, 32)
+	keyInfo := append([]byte("WebPush: info\x00"), uaPublicRaw...)
+	keyInfo = append(keyInfo, asPublicRaw...)
+	ikm := hkdfExpand(hkdfExtract(authSecret, sharedSecret), keyInfo, 32)
+
+	prk := hkdfExtract(salt, ikm)
+	cek := hkdfExpand(prk, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	nonce := hkdfExpand(prk, []byte("Content-Encoding: nonce\x00"), 12)
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("create gcm: %w", err)
+	}
+
+	// A single, final record: payload followed by the 0x02 delimiter.
+	plaintext := append(append([]byte{}, payload...), 0x02)
+
+	header := make([]byte, 0, 16+4+1+len(asPublicRaw))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, webPushRecordSize)
+	header = append(header, byte(len(asPublicRaw)))
+	header = append(header, asPublicRaw...)
+
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
+
+func hkdfExtract(salt, ikm []byte) []byte {
+	mac := hmac.New(sha256.New, salt)
+	mac.Write(ikm)
+	return mac.Sum(nil)
+}
+
+// hkdfExpand implements HKDF-Expand for outputs no longer than one hash block.
+func hkdfExpand(prk, info []byte, length int) []byte {
+	mac := hmac.New(sha256.New, prk)
+	mac.Write(info)
+	mac.Write([]byte{0x01})
+	return mac.Sum(nil)[:length]
+}
+
+// decodeBase64 accepts the padded or unpadded base64url keys browsers emit.
+func decodeBase64(s string) ([]byte, error) {
+	s = strings.TrimRight(s, "=")
+	s = strings.NewReplacer("+", "-", "/", "_").Replace(s)
+	return base64.RawURLEncoding.DecodeString(s)
+}
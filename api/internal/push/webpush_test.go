@@ -0,0 +1,142 @@
+package push
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"chaseapp.tv/api/internal/config"
+)
+
+// decryptWebPush performs the user agent side of RFC 8291.
+func decryptWebPush(t *testing.T, ua *ecdh.PrivateKey, authSecret, body []byte) []byte {
+	t.Helper()
+	require.Greater(t, len(body), 21)
+	salt := body[:16]
+	require.Equal(t, uint32(webPushRecordSize), binary.BigEndian.Uint32(body[16:20]))
+	idLen := int(body[20])
+	asPublicRaw := body[21 : 21+idLen]
+	ciphertext := body[21+idLen:]
+
+	asPublic, err := ecdh.P256().NewPublicKey(asPublicRaw)
+	require.NoError(t, err)
+	shared, err := ua.ECDH(asPublic)
+	require.NoError(t, err)
+
+	keyInfo := append([]byte("WebPush: info\x00"), ua.PublicKey().Bytes()...)
+	keyInfo = append(keyInfo, asPublicRaw...)
+	ikm := hkdfExpand(hkdfExtract(authSecret, shared), keyInfo, 32)
+	prk := hkdfExtract(salt, ikm)
+
+	block, err := aes.NewCipher(hkdfExpand(prk, []byte("Content-Encoding: aes128gcm\x00"), 16))
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	plaintext, err := gcm.Open(nil, hkdfExpand(prk, []byte("Content-Encoding: nonce\x00"), 12), ciphertext, nil)
+	require.NoError(t, err)
+
+	require.Equal(t, byte(0x02), plaintext[len(plaintext)-1])
+	return plaintext[:len(plaintext)-1]
+}
+
+func TestWebPushSendEncryptsAndSignsVAPID(t *testing.T) {
+	vapid, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	raw, err := vapid.Bytes()
+	require.NoError(t, err)
+
+	client, err := NewWebPushClient(config.PushConfig{
+		VAPIDPrivateKey: base64.RawURLEncoding.EncodeToString(raw),
+		VAPIDSubject:    "mailto:ops@chaseapp.tv",
+	})
+	require.NoError(t, err)
+
+	pub, err := VAPIDPublicKey(base64.RawURLEncoding.EncodeToString(raw))
+	require.NoError(t, err)
+	require.Equal(t, pub, client.PublicKey())
+
+	ua, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	authSecret := make([]byte, 16)
+	_, _ = rand.Read(authSecret)
+
+	var req *http.Request
+	var body []byte
+	client.httpClient = &http.Client{Transport: stubTransport(func(r *http.Request) (*http.Response, error) {
+		req = r
+		body, _ = io.ReadAll(r.Body)
+		return stubResponse(http.StatusCreated, "", nil), nil
+	})}
+
+	sub := WebPushSubscription{
+		Endpoint: "https://fcm.googleapis.com/fcm/send/abc123",
+		P256dh:   base64.URLEncoding.EncodeToString(ua.PublicKey().Bytes()), // padded, as some browsers send
+		Auth:     base64.RawURLEncoding.EncodeToString(authSecret),
+	}
+	payload := []byte(`{"title":"Pursuit","chase_id":"chase-1"}`)
+	require.NoError(t, client.Send(context.Background(), sub, payload, WebPushOptions{TTL: time.Hour, Urgency: WebPushUrgencyHigh}))
+
+	require.Equal(t, "aes128gcm", req.Header.Get("Content-Encoding"))
+	require.Equal(t, "3600", req.Header.Get("TTL"))
+	require.Equal(t, "high", req.Header.Get("Urgency"))
+	require.Equal(t, payload, decryptWebPush(t, ua, authSecret, body))
+
+	auth := req.Header.Get("Authorization")
+	require.True(t, strings.HasPrefix(auth, "vapid t="))
+	jwt, key, ok := strings.Cut(strings.TrimPrefix(auth, "vapid t="), ", k=")
+	require.True(t, ok)
+	require.Equal(t, client.PublicKey(), key)
+
+	claims := verifyES256(t, &vapid.PublicKey, jwt)
+	require.Equal(t, "https://fcm.googleapis.com", claims["aud"])
+	require.Equal(t, "mailto:ops@chaseapp.tv", claims["sub"])
+}
+
+func TestWebPushGoneIsPermanent(t *testing.T) {
+	err := &ProviderError{Provider: "webpush", StatusCode: http.StatusGone}
+	require.True(t, err.Permanent())
+	err = &ProviderError{Provider: "webpush", StatusCode: http.StatusTooManyRequests}
+	require.False(t, err.Permanent())
+	require.True(t, err.Temporary())
+}
+
+func TestValidateWebPushEndpoint(t *testing.T) {
+	for _, endpoint := range []string{
+		"https://fcm.googleapis.com/fcm/send/abc123",
+		"https://updates.push.services.mozilla.com/wpush/v2/abc",
+		"https://web.push.apple.com/QGf6",
+		"https://wns2-by3p.notify.windows.com/w/?token=abc",
+	} {
+		require.NoError(t, ValidateWebPushEndpoint(endpoint), endpoint)
+	}
+	for _, endpoint := range []string{
+		"http://fcm.googleapis.com/fcm/send/abc123",
+		"https://fcm.googleapis.com:8443/fcm/send/abc123",
+		"https://evil.example/fcm.googleapis.com",
+		"https://fcm.googleapis.com.evil.example/send",
+		"https://127.0.0.1/push",
+		"https://metadata.google.internal/computeMetadata/v1/",
+	} {
+		require.ErrorIs(t, ValidateWebPushEndpoint(endpoint), ErrInvalidWebPushEndpoint, endpoint)
+	}
+}
+
+func TestPublicAddressOnly(t *testing.T) {
+	require.NoError(t, publicAddressOnly("tcp", "142.250.72.10:443", nil))
+	for _, address := range []string{"127.0.0.1:443", "10.0.0.5:443", "169.254.169.254:443", "[::1]:443", "[::ffff:192.168.1.1]:443"} {
+		require.Error(t, publicAddressOnly("tcp", address, nil), address)
+	}
+}
@@ -0,0 +1,122 @@
+package realtime
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// chaseListenerBuffer is how many events a listener may fall behind before
+// it is dropped.
+const chaseListenerBuffer = 64
+
+// ChaseStreamEvent is a chase event with its sequence in the chases stream.
+type ChaseStreamEvent struct {
+	Seq uint64
+	ChaseEvent
+}
+
+// ChaseBroker fans chase events from a single JetStream consumer out to any
+// number of in-process listeners, such as SSE clients. Listeners that fall
+// behind are dropped rather than blocking the others; they can reconnect
+// and replay from their last sequence.
+type ChaseBroker struct {
+	js        *JetStream
+	logger    *slog.Logger
+	mu        sync.Mutex
+	listeners map[chan ChaseStreamEvent]struct{}
+	closed    bool
+}
+
+// NewChaseBroker creates a new ChaseBroker.
+func NewChaseBroker(js *JetStream, logger *slog.Logger) *ChaseBroker {
+	return &ChaseBroker{
+		js:        js,
+		logger:    logger,
+		listeners: make(map[chan ChaseStreamEvent]struct{}),
+	}
+}
+
+// Start relays chase events and blocks until context cancellation, then
+// closes the broker.
+func (b *ChaseBroker) Start(ctx context.Context) error {
+	defer b.Close()
+	if b.js == nil {
+		return nil
+	}
+
+	sub, err := b.js.SubscribeChasesOrdered(func(seq uint64, evt ChaseEvent) {
+		b.publish(ChaseStreamEvent{Seq: seq, ChaseEvent: evt})
+	})
+	if err != nil {
+		return err
+	}
+	defer sub.Unsubscribe()
+
+	<-ctx.Done()
+	return nil
+}
+
+// Subscribe registers a listener. The channel is closed when the listener
+// is dropped or the broker stops; cancel unregisters it.
+func (b *ChaseBroker) Subscribe() (<-chan ChaseStreamEvent, func()) {
+	ch := make(chan ChaseStreamEvent, chaseListenerBuffer)
+
+	b.mu.Lock()
+	if b.closed {
+		close(ch)
+	} else {
+		b.listeners[ch] = struct{}{}
+	}
+	b.mu.Unlock()
+
+	return ch, func() { b.remove(ch) }
+}
+
+// Replay delivers events stored after sequence after; see
+// JetStream.ReplayChases.
+func (b *ChaseBroker) Replay(ctx context.Context, after uint64, fn func(ChaseStreamEvent) error) (uint64, error) {
+	if b.js == nil {
+		return after, nil
+	}
+	return b.js.ReplayChases(ctx, after, func(seq uint64, evt ChaseEvent) error {
+		return fn(ChaseStreamEvent{Seq: seq, ChaseEvent: evt})
+	})
+}
+
+func (b *ChaseBroker) publish(evt ChaseStreamEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.listeners {
+		select {
+		case ch <- evt:
+		default:
+			delete(b.listeners, ch)
+			close(ch)
+			b.logger.Warn("dropped slow chase event listener", slog.Uint64("seq", evt.Seq))
+		}
+	}
+}
+
+func (b *ChaseBroker) remove(ch chan ChaseStreamEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.listeners[ch]; ok {
+		delete(b.listeners, ch)
+		close(ch)
+	}
+}
+
+// Close closes every listener and rejects new ones.
+func (b *ChaseBroker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for ch := range b.listeners {
+		delete(b.listeners, ch)
+		close(ch)
+	}
+}
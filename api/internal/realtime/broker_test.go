@@ -0,0 +1,101 @@
+package realtime
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats-server/v2/test"
+	"github.com/stretchr/testify/require"
+
+	"chaseapp.tv/api/internal/config"
+	"chaseapp.tv/api/internal/model"
+)
+
+func TestChaseBrokerRelayAndReplay(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Skipf("skipping NATS integration test: %v", r)
+		}
+	}()
+
+	srv := test.RunServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      -1,
+		NoLog:     true,
+		NoSigs:    true,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+	})
+	t.Cleanup(srv.Shutdown)
+
+	cfg := config.NATSConfig{URL: srv.ClientURL(), ClientID: "chaseapp-test", MaxReconnects: 1, ReconnectWait: time.Second}
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	js, err := NewJetStream(cfg, logger)
+	require.NoError(t, err)
+	t.Cleanup(js.Close)
+	require.NoError(t, js.EnsureStreams(context.Background()))
+
+	publisher, err := NewPublisher(cfg, logger)
+	require.NoError(t, err)
+	t.Cleanup(publisher.Close)
+	publisher.UseJetStream(js)
+
+	chase := func(title string) *model.Chase {
+		return &model.Chase{ID: uuid.New(), Title: title, ChaseType: model.ChaseTypeChase}
+	}
+	require.NoError(t, publisher.PublishChase(SubjectChaseCreated, chase("first")))
+	require.NoError(t, publisher.PublishChase(SubjectChaseUpdated, chase("second")))
+
+	broker := NewChaseBroker(js, logger)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go broker.Start(ctx)
+
+	events, unsubscribe := broker.Subscribe()
+	defer unsubscribe()
+
+	// Replay everything after the first event.
+	var replayed []string
+	last, err := broker.Replay(ctx, 1, func(evt ChaseStreamEvent) error {
+		replayed = append(replayed, evt.Chase.Title)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), last)
+	require.Equal(t, []string{"second"}, replayed)
+
+	// Live events are relayed with their stream sequence.
+	require.Eventually(t, func() bool {
+		require.NoError(t, publisher.PublishChase(SubjectChaseLive, chase("third")))
+		select {
+		case evt := <-events:
+			require.Equal(t, SubjectChaseLive, evt.Event)
+			require.Greater(t, evt.Seq, uint64(2))
+			return true
+		case <-time.After(200 * time.Millisecond):
+			return false
+		}
+	}, 3*time.Second, 10*time.Millisecond)
+}
+
+func TestChaseBrokerDropsSlowListener(t *testing.T) {
+	broker := NewChaseBroker(nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	events, unsubscribe := broker.Subscribe()
+	defer unsubscribe()
+
+	for i := 0; i <= chaseListenerBuffer; i++ {
+		broker.publish(ChaseStreamEvent{Seq: uint64(i + 1)})
+	}
+
+	received := 0
+	for range events {
+		received++
+	}
+	require.Equal(t, chaseListenerBuffer, received)
+}
@@ -2,6 +2,7 @@ package realtime
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"time"
@@ -49,7 +50,7 @@ func (j *JetStream) EnsureStreams(ctx context.Context) error {
 		Name     string
 		Subjects []string
 	}{
-		{Name: "chases", Subjects: []string{"chases.*"}},
+		{Name: chaseStream, Subjects: []string{"chases.*"}},
 		{Name: "users", Subjects: []string{"users.*"}},
 		{Name: "aircraft", Subjects: []string{"aircraft.*"}},
 	}
@@ -76,6 +77,129 @@ func (j *JetStream) Subscribe(subject, durable string, handler nats.MsgHandler)
 	return j.js.Subscribe(subject, handler, nats.Durable(durable), nats.ManualAck())
 }
 
+// QueueSubscribe creates a durable queue consumer with manual ack so that
+// replicas share delivery instead of each receiving every message.
+func (j *JetStream) QueueSubscribe(subject, queue string, handler nats.MsgHandler, opts ...nats.SubOpt) (*nats.Subscription, error) {
+	opts = append([]nats.SubOpt{nats.Durable(queue), nats.ManualAck()}, opts...)
+	return j.js.QueueSubscribe(subject, queue, handler, opts...)
+}
+
+// ConsumeChases attaches a durable queue consumer to chase events. New
+// consumers start at new messages so a fresh deployment does not replay the
+// stream; a handler error naks the message for redelivery.
+func (j *JetStream) ConsumeChases(queue string, handler func(evt ChaseEvent) error) (*nats.Subscription, error) {
+	return j.QueueSubscribe("chases.*", queue, func(msg *nats.Msg) {
+		var evt ChaseEvent
+		if err := json.Unmarshal(msg.Data, &evt); err != nil {
+			j.log.Warn("failed to unmarshal chase event", slog.Any("error", err))
+			_ = msg.Term()
+			return
+		}
+		if err := handler(evt); err != nil {
+			_ = msg.Nak()
+			return
+		}
+		_ = msg.Ack()
+	}, nats.DeliverNew(), nats.AckWait(2*time.Minute))
+}
+
+// ConsumeAircraftEmergencies attaches a durable queue consumer to
+// aircraft.emergency events, with the same delivery semantics as
+// ConsumeChases.
+func (j *JetStream) ConsumeAircraftEmergencies(queue string, handler func(evt EmergencyEvent) error) (*nats.Subscription, error) {
+	return j.QueueSubscribe(SubjectAircraftEmergency, queue, func(msg *nats.Msg) {
+		var evt EmergencyEvent
+		if err := json.Unmarshal(msg.Data, &evt); err != nil {
+			j.log.Warn("failed to unmarshal emergency event", slog.Any("error", err))
+			_ = msg.Term()
+			return
+		}
+		if err := handler(evt); err != nil {
+			_ = msg.Nak()
+			return
+		}
+		_ = msg.Ack()
+	}, nats.DeliverNew(), nats.AckWait(2*time.Minute))
+}
+
+// chaseStream is the JetStream stream holding chase events.
+const chaseStream = "chases"
+
+// SubscribeChasesOrdered delivers new chase events, with their stream
+// sequence, through an ephemeral ordered consumer.
+func (j *JetStream) SubscribeChasesOrdered(handler func(seq uint64, evt ChaseEvent)) (*nats.Subscription, error) {
+	return j.js.Subscribe("chases.*", func(msg *nats.Msg) {
+		seq, evt, ok := j.decodeChase(msg)
+		if ok {
+			handler(seq, evt)
+		}
+	}, nats.OrderedConsumer(), nats.DeliverNew())
+}
+
+// ReplayChases delivers chase events stored after sequence after, up to the
+// stream's last sequence when called, and returns the last sequence
+// delivered. Events that have aged out of the stream are skipped.
+func (j *JetStream) ReplayChases(ctx context.Context, after uint64, handler func(seq uint64, evt ChaseEvent) error) (uint64, error) {
+	info, err := j.js.StreamInfo(chaseStream, nats.Context(ctx))
+	if err != nil {
+		return after, fmt.Errorf("chase stream info: %w", err)
+	}
+	last := info.State.LastSeq
+	if after >= last || info.State.Msgs == 0 {
+		return after, nil
+	}
+
+	sub, err := j.js.SubscribeSync("chases.*", nats.OrderedConsumer(), nats.StartSequence(max(after+1, info.State.FirstSeq)))
+	if err != nil {
+		return after, fmt.Errorf("replay chase stream: %w", err)
+	}
+	defer sub.Unsubscribe()
+
+	delivered := after
+	for delivered < last {
+		msg, err := nextMsg(ctx, sub)
+		if err != nil {
+			return delivered, err
+		}
+		seq, evt, ok := j.decodeChase(msg)
+		if seq == 0 || seq > last {
+			break
+		}
+		delivered = seq
+		if !ok {
+			continue
+		}
+		if err := handler(seq, evt); err != nil {
+			return delivered, err
+		}
+	}
+	return delivered, nil
+}
+
+// replayIdleTimeout bounds the wait for the next replayed message, in case
+// the last message was removed from the stream during the replay.
+const replayIdleTimeout = 5 * time.Second
+
+func nextMsg(ctx context.Context, sub *nats.Subscription) (*nats.Msg, error) {
+	ctx, cancel := context.WithTimeout(ctx, replayIdleTimeout)
+	defer cancel()
+	return sub.NextMsgWithContext(ctx)
+}
+
+func (j *JetStream) decodeChase(msg *nats.Msg) (uint64, ChaseEvent, bool) {
+	var evt ChaseEvent
+	meta, err := msg.Metadata()
+	if err != nil {
+		j.log.Warn("chase event without jetstream metadata", slog.Any("error", err))
+		return 0, evt, false
+	}
+	if err := json.Unmarshal(msg.Data, &evt); err != nil {
+		j.log.Warn("failed to unmarshal chase event", slog.Any("error", err))
+		return meta.Sequence.Stream, evt, false
+	}
+	return meta.Sequence.Stream, evt, true
+}
+
 // Publish publishes to JetStream.
 func (j *JetStream) Publish(subject string, data []byte) error {
 	_, err := j.js.Publish(subject, data)
@@ -15,12 +15,16 @@ import (
 
 const (
 	// NATS subjects for chase lifecycle events.
-	SubjectChaseCreated = "chases.created"
-	SubjectChaseUpdated = "chases.updated"
-	SubjectChaseEnded   = "chases.ended"
-	SubjectChaseLive    = "chases.live"
-	SubjectChaseDeleted = "chases.deleted"
-	SubjectAircraftUpdated = "aircraft.updated"
+	SubjectChaseCreated      = "chases.created"
+	SubjectChaseUpdated      = "chases.updated"
+	SubjectChaseEnded        = "chases.ended"
+	SubjectChaseLive         = "chases.live"
+	SubjectChaseDeleted      = "chases.deleted"
+	SubjectAircraftUpdated   = "aircraft.updated"
+	SubjectAircraftBoF       = "aircraft.bof"
+	SubjectAircraftEmergency = "aircraft.emergency"
+	SubjectAircraftCluster   = "aircraft.cluster"
+	SubjectUserCreated       = "users.created"
 )
 
 // Publisher wraps a NATS connection for publishing events.
@@ -37,6 +41,42 @@ type ChaseEvent struct {
 	OccurredAt time.Time    `json:"occurred_at"`
 }
 
+// AircraftEvent is the aircraft.updated payload: a batch of aircraft whose
+// positions changed.
+type AircraftEvent struct {
+	Aircraft   []model.Aircraft `json:"aircraft"`
+	OccurredAt time.Time        `json:"occurred_at"`
+}
+
+// BoFEvent is the aircraft.bof payload: a "Birds of a Feather" cluster of
+// media and law enforcement aircraft circling one spot, and the draft chase
+// created for moderators to review.
+type BoFEvent struct {
+	Chase      *model.Chase        `json:"chase"`
+	Cluster    model.ClusterResult `json:"cluster"`
+	OccurredAt time.Time           `json:"occurred_at"`
+}
+
+// EmergencyEvent is the aircraft.emergency payload, sent once when an
+// aircraft starts squawking or declaring an emergency.
+type EmergencyEvent struct {
+	Aircraft   model.Aircraft `json:"aircraft"`
+	Reason     string         `json:"reason"`
+	OccurredAt time.Time      `json:"occurred_at"`
+}
+
+// ClusterEvent is the aircraft.cluster payload: a tracked cluster formed,
+// split, merged or dissolved. For a split, Related lists the clusters that
+// split off; for a merge, the clusters absorbed. Cluster is the cluster's
+// current state, or nil once dissolved.
+type ClusterEvent struct {
+	Event      string                 `json:"event"`
+	ClusterID  string                 `json:"cluster_id"`
+	Related    []string               `json:"related,omitempty"`
+	Cluster    *model.AircraftCluster `json:"cluster,omitempty"`
+	OccurredAt time.Time              `json:"occurred_at"`
+}
+
 // NewPublisher creates a NATS connection for publishing events.
 func NewPublisher(cfg config.NATSConfig, logger *slog.Logger) (*Publisher, error) {
 	opts := []nats.Option{
@@ -56,6 +96,12 @@ func NewPublisher(cfg config.NATSConfig, logger *slog.Logger) (*Publisher, error
 	}, nil
 }
 
+// UseJetStream routes subsequent publishes through JetStream so they are
+// persisted in the configured streams.
+func (p *Publisher) UseJetStream(js *JetStream) {
+	p.js = js
+}
+
 // PublishChase publishes a chase event to the given subject.
 func (p *Publisher) PublishChase(subject string, chase *model.Chase) error {
 	if p == nil || p.conn == nil {
@@ -81,6 +127,127 @@ func (p *Publisher) PublishChase(subject string, chase *model.Chase) error {
 	return p.conn.Publish(subject, payload)
 }
 
+// PublishAircraftUpdated publishes a batch of updated aircraft.
+func (p *Publisher) PublishAircraftUpdated(aircraft []model.Aircraft) error {
+	if p == nil || p.conn == nil {
+		return fmt.Errorf("publisher not initialized")
+	}
+
+	payload, err := json.Marshal(AircraftEvent{
+		Aircraft:   aircraft,
+		OccurredAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("marshal aircraft event: %w", err)
+	}
+
+	if p.js != nil {
+		if err := p.js.Publish(SubjectAircraftUpdated, payload); err != nil {
+			return fmt.Errorf("publish aircraft event js: %w", err)
+		}
+		return nil
+	}
+
+	return p.conn.Publish(SubjectAircraftUpdated, payload)
+}
+
+// PublishBoF announces a detected Birds of a Feather cluster.
+func (p *Publisher) PublishBoF(chase *model.Chase, cluster model.ClusterResult) error {
+	if p == nil || p.conn == nil {
+		return fmt.Errorf("publisher not initialized")
+	}
+
+	payload, err := json.Marshal(BoFEvent{
+		Chase:      chase,
+		Cluster:    cluster,
+		OccurredAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("marshal bof event: %w", err)
+	}
+
+	if p.js != nil {
+		if err := p.js.Publish(SubjectAircraftBoF, payload); err != nil {
+			return fmt.Errorf("publish bof event js: %w", err)
+		}
+		return nil
+	}
+
+	return p.conn.Publish(SubjectAircraftBoF, payload)
+}
+
+// PublishAircraftEmergency announces the start of an aircraft emergency.
+func (p *Publisher) PublishAircraftEmergency(aircraft model.Aircraft, reason string) error {
+	if p == nil || p.conn == nil {
+		return fmt.Errorf("publisher not initialized")
+	}
+
+	payload, err := json.Marshal(EmergencyEvent{
+		Aircraft:   aircraft,
+		Reason:     reason,
+		OccurredAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("marshal emergency event: %w", err)
+	}
+
+	if p.js != nil {
+		if err := p.js.Publish(SubjectAircraftEmergency, payload); err != nil {
+			return fmt.Errorf("publish emergency event js: %w", err)
+		}
+		return nil
+	}
+
+	return p.conn.Publish(SubjectAircraftEmergency, payload)
+}
+
+// PublishClusterEvent announces a change in tracked aircraft clusters.
+func (p *Publisher) PublishClusterEvent(evt ClusterEvent) error {
+	if p == nil || p.conn == nil {
+		return fmt.Errorf("publisher not initialized")
+	}
+
+	evt.OccurredAt = time.Now().UTC()
+	payload, err := json.Marshal(evt)
+	if err != nil {
+		return fmt.Errorf("marshal cluster event: %w", err)
+	}
+
+	if p.js != nil {
+		if err := p.js.Publish(SubjectAircraftCluster, payload); err != nil {
+			return fmt.Errorf("publish cluster event js: %w", err)
+		}
+		return nil
+	}
+
+	return p.conn.Publish(SubjectAircraftCluster, payload)
+}
+
+// PublishUserCreated announces a newly provisioned user account. It is sent
+// on core NATS, where UserEventWorker subscribes.
+func (p *Publisher) PublishUserCreated(user *model.User) error {
+	if p == nil || p.conn == nil {
+		return fmt.Errorf("publisher not initialized")
+	}
+
+	payload, err := json.Marshal(struct {
+		ID         string    `json:"id"`
+		Email      string    `json:"email,omitempty"`
+		Provider   string    `json:"provider"`
+		OccurredAt time.Time `json:"occurred_at"`
+	}{
+		ID:         user.ID.String(),
+		Email:      user.Email,
+		Provider:   string(user.Provider),
+		OccurredAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("marshal user event: %w", err)
+	}
+
+	return p.conn.Publish(SubjectUserCreated, payload)
+}
+
 // IsConnected reports whether the NATS connection is healthy.
 func (p *Publisher) IsConnected() bool {
 	return p != nil && p.conn != nil && p.conn.Status() == nats.CONNECTED
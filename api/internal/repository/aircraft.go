@@ -24,6 +24,14 @@ func NewAircraftRepository(pool *pgxpool.Pool) *AircraftRepository {
 	return &AircraftRepository{pool: pool}
 }
 
+// aircraftColumns lists the columns scanned into model.Aircraft. Optional
+// text columns are NULL until reported, so they are read as empty strings.
+const aircraftColumns = `id, icao, COALESCE(callsign, ''), COALESCE(registration, ''),
+	latitude, longitude, altitude, ground_speed, track, vertical_rate,
+	COALESCE(aircraft_type, ''), COALESCE(category, ''), COALESCE(operator, ''),
+	COALESCE(on_ground, false), COALESCE(squawk, ''), COALESCE(emergency, ''), COALESCE(cluster_id, ''),
+	metadata, first_seen_at, last_seen_at, created_at, updated_at`
+
 // Upsert creates or updates an aircraft by ICAO code.
 func (r *AircraftRepository) Upsert(ctx context.Context, input model.UpsertAircraftInput) (*model.Aircraft, error) {
 	now := time.Now()
@@ -39,7 +47,7 @@ func (r *AircraftRepository) Upsert(ctx context.Context, input model.UpsertAircr
 			ground_speed, track, vertical_rate, aircraft_type, category,
 			operator, on_ground, squawk, emergency, metadata, last_seen_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, COALESCE($13::boolean, false), $14, $15, $16, $17
 		)
 		ON CONFLICT (icao) DO UPDATE SET
 			callsign = COALESCE(EXCLUDED.callsign, aircraft.callsign),
@@ -59,10 +67,7 @@ func (r *AircraftRepository) Upsert(ctx context.Context, input model.UpsertAircr
 			metadata = COALESCE(EXCLUDED.metadata, aircraft.metadata),
 			last_seen_at = $17,
 			updated_at = NOW()
-		RETURNING id, icao, callsign, registration, latitude, longitude, altitude,
-			ground_speed, track, vertical_rate, aircraft_type, category, operator,
-			on_ground, squawk, emergency, cluster_id, metadata,
-			first_seen_at, last_seen_at, created_at, updated_at`
+		RETURNING ` + aircraftColumns
 
 	var aircraft model.Aircraft
 	var metadataBytes []byte
@@ -93,13 +98,110 @@ func (r *AircraftRepository) Upsert(ctx context.Context, input model.UpsertAircr
 	return &aircraft, nil
 }
 
+// upsertBatchQuery upserts one aircraft from a feed, where empty strings
+// and NULLs mean "not observed" and keep the stored value, and records its
+// position in aircraft_history when the observation has one. Feeds can
+// deliver observations out of order, so an observation older than the
+// stored one does not overwrite the position or ground state. When the
+// input says whether the aircraft is on the watchlist, the tags from a
+// previous match (category and the watchlist_id, watchlist_group and
+// image_url metadata) are replaced, or cleared if it no longer matches.
+const upsertBatchQuery = `
+	WITH upserted AS (
+		INSERT INTO aircraft (
+			icao, callsign, registration, latitude, longitude, altitude,
+			ground_speed, track, vertical_rate, aircraft_type, category,
+			operator, on_ground, squawk, emergency, metadata, last_seen_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12,
+			COALESCE($13::boolean, false), $14, $15,
+			COALESCE($16::jsonb, '{}'::jsonb), $17
+		)
+		ON CONFLICT (icao) DO UPDATE SET
+			callsign = COALESCE(NULLIF(EXCLUDED.callsign, ''), aircraft.callsign),
+			registration = COALESCE(NULLIF(EXCLUDED.registration, ''), aircraft.registration),
+			latitude = CASE WHEN EXCLUDED.last_seen_at >= aircraft.last_seen_at
+				THEN COALESCE(EXCLUDED.latitude, aircraft.latitude) ELSE aircraft.latitude END,
+			longitude = CASE WHEN EXCLUDED.last_seen_at >= aircraft.last_seen_at
+				THEN COALESCE(EXCLUDED.longitude, aircraft.longitude) ELSE aircraft.longitude END,
+			altitude = CASE WHEN EXCLUDED.last_seen_at >= aircraft.last_seen_at
+				THEN COALESCE(EXCLUDED.altitude, aircraft.altitude) ELSE aircraft.altitude END,
+			ground_speed = CASE WHEN EXCLUDED.last_seen_at >= aircraft.last_seen_at
+				THEN COALESCE(EXCLUDED.ground_speed, aircraft.ground_speed) ELSE aircraft.ground_speed END,
+			track = CASE WHEN EXCLUDED.last_seen_at >= aircraft.last_seen_at
+				THEN COALESCE(EXCLUDED.track, aircraft.track) ELSE aircraft.track END,
+			vertical_rate = CASE WHEN EXCLUDED.last_seen_at >= aircraft.last_seen_at
+				THEN COALESCE(EXCLUDED.vertical_rate, aircraft.vertical_rate) ELSE aircraft.vertical_rate END,
+			aircraft_type = COALESCE(NULLIF(EXCLUDED.aircraft_type, ''), aircraft.aircraft_type),
+			category = CASE WHEN $18::boolean IS FALSE AND aircraft.metadata ? 'watchlist_id'
+				THEN NULLIF(EXCLUDED.category, '')
+				ELSE COALESCE(NULLIF(EXCLUDED.category, ''), aircraft.category) END,
+			operator = COALESCE(NULLIF(EXCLUDED.operator, ''), aircraft.operator),
+			on_ground = CASE WHEN EXCLUDED.last_seen_at >= aircraft.last_seen_at
+				THEN COALESCE($13::boolean, aircraft.on_ground) ELSE aircraft.on_ground END,
+			squawk = COALESCE(NULLIF(EXCLUDED.squawk, ''), aircraft.squawk),
+			emergency = COALESCE(NULLIF(EXCLUDED.emergency, ''), aircraft.emergency),
+			metadata = CASE WHEN $18::boolean IS NOT NULL AND aircraft.metadata ? 'watchlist_id'
+				THEN aircraft.metadata - 'watchlist_id' - 'watchlist_group' - 'image_url'
+				ELSE COALESCE(aircraft.metadata, '{}'::jsonb) END || EXCLUDED.metadata,
+			last_seen_at = GREATEST(aircraft.last_seen_at, EXCLUDED.last_seen_at),
+			updated_at = NOW()
+		RETURNING ` + aircraftColumns + `
+	), history AS (
+		INSERT INTO aircraft_history (aircraft_id, latitude, longitude, altitude, ground_speed, track, recorded_at)
+		SELECT id, $4, $5, $6, $7, $8, $17 FROM upserted
+		WHERE $4::double precision IS NOT NULL AND $5::double precision IS NOT NULL
+	)
+	SELECT * FROM upserted`
+
+// UpsertBatch upserts aircraft observations from a feed in one round trip
+// and appends their positions to history. Unlike Upsert, fields missing
+// from an observation keep their stored values.
+func (r *AircraftRepository) UpsertBatch(ctx context.Context, inputs []model.UpsertAircraftInput) ([]model.Aircraft, error) {
+	if len(inputs) == 0 {
+		return nil, nil
+	}
+
+	now := time.Now()
+	batch := &pgx.Batch{}
+	for _, in := range inputs {
+		var metadataJSON []byte
+		if len(in.Metadata) > 0 {
+			var err error
+			if metadataJSON, err = json.Marshal(in.Metadata); err != nil {
+				return nil, fmt.Errorf("failed to marshal metadata: %w", err)
+			}
+		}
+		seenAt := in.SeenAt
+		if seenAt.IsZero() {
+			seenAt = now
+		}
+		batch.Queue(upsertBatchQuery,
+			in.ICAO, in.Callsign, in.Registration, in.Latitude, in.Longitude,
+			in.Altitude, in.GroundSpeed, in.Track, in.VerticalRate,
+			in.AircraftType, string(in.Category), in.Operator, in.OnGround,
+			in.Squawk, in.Emergency, metadataJSON, seenAt, in.Watchlisted,
+		)
+	}
+
+	results := r.pool.SendBatch(ctx, batch)
+	defer results.Close()
+
+	aircraft := make([]model.Aircraft, 0, len(inputs))
+	for range inputs {
+		a, err := r.scanAircraft(results.QueryRow())
+		if err != nil {
+			return nil, fmt.Errorf("failed to upsert aircraft batch: %w", err)
+		}
+		aircraft = append(aircraft, *a)
+	}
+	return aircraft, nil
+}
+
 // GetByID retrieves an aircraft by ID.
 func (r *AircraftRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Aircraft, error) {
 	query := `
-		SELECT id, icao, callsign, registration, latitude, longitude, altitude,
-			   ground_speed, track, vertical_rate, aircraft_type, category, operator,
-			   on_ground, squawk, emergency, cluster_id, metadata,
-			   first_seen_at, last_seen_at, created_at, updated_at
+		SELECT ` + aircraftColumns + `
 		FROM aircraft
 		WHERE id = $1`
 
@@ -109,10 +211,7 @@ func (r *AircraftRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.
 // GetByICAO retrieves an aircraft by ICAO code.
 func (r *AircraftRepository) GetByICAO(ctx context.Context, icao string) (*model.Aircraft, error) {
 	query := `
-		SELECT id, icao, callsign, registration, latitude, longitude, altitude,
-			   ground_speed, track, vertical_rate, aircraft_type, category, operator,
-			   on_ground, squawk, emergency, cluster_id, metadata,
-			   first_seen_at, last_seen_at, created_at, updated_at
+		SELECT ` + aircraftColumns + `
 		FROM aircraft
 		WHERE icao = $1`
 
@@ -149,6 +248,11 @@ func (r *AircraftRepository) List(ctx context.Context, opts model.AircraftListOp
 		args = append(args, *opts.OnGround)
 		argNum++
 	}
+	if opts.SeenAfter != nil {
+		baseQuery += fmt.Sprintf(" AND last_seen_at > $%d", argNum)
+		args = append(args, *opts.SeenAfter)
+		argNum++
+	}
 
 	// Geographic bounding box filter
 	if opts.MinLat != nil && opts.MaxLat != nil && opts.MinLng != nil && opts.MaxLng != nil {
@@ -167,10 +271,7 @@ func (r *AircraftRepository) List(ctx context.Context, opts model.AircraftListOp
 
 	// Get aircraft
 	selectQuery := fmt.Sprintf(`
-		SELECT id, icao, callsign, registration, latitude, longitude, altitude,
-			   ground_speed, track, vertical_rate, aircraft_type, category, operator,
-			   on_ground, squawk, emergency, cluster_id, metadata,
-			   first_seen_at, last_seen_at, created_at, updated_at
+		SELECT ` + aircraftColumns + `
 		%s ORDER BY last_seen_at DESC LIMIT $%d OFFSET $%d`,
 		baseQuery, argNum, argNum+1)
 
@@ -208,11 +309,83 @@ func (r *AircraftRepository) List(ctx context.Context, opts model.AircraftListOp
 
 // UpdateCluster assigns aircraft to a cluster.
 func (r *AircraftRepository) UpdateCluster(ctx context.Context, id uuid.UUID, clusterID string) error {
-	query := `UPDATE aircraft SET cluster_id = $2, updated_at = NOW() WHERE id = $1`
+	query := `UPDATE aircraft SET cluster_id = NULLIF($2, ''), updated_at = NOW() WHERE id = $1`
 	_, err := r.pool.Exec(ctx, query, id, clusterID)
 	return err
 }
 
+// UpdateClusters assigns aircraft, by ID, to clusters in one statement. An
+// empty cluster ID removes the aircraft from its cluster.
+func (r *AircraftRepository) UpdateClusters(ctx context.Context, clusters map[uuid.UUID]string) error {
+	if len(clusters) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(clusters))
+	clusterIDs := make([]string, 0, len(clusters))
+	for id, clusterID := range clusters {
+		ids = append(ids, id)
+		clusterIDs = append(clusterIDs, clusterID)
+	}
+
+	query := `
+		UPDATE aircraft a SET cluster_id = NULLIF(u.cluster_id, ''), updated_at = NOW()
+		FROM unnest($1::uuid[], $2::text[]) AS u(id, cluster_id)
+		WHERE a.id = u.id`
+	if _, err := r.pool.Exec(ctx, query, ids, clusterIDs); err != nil {
+		return fmt.Errorf("failed to update aircraft clusters: %w", err)
+	}
+	return nil
+}
+
+// ObserveEmergencies records emergency reports received at now: it ends the
+// episodes of the aircraft in ended, starts one for each aircraft in
+// declaring without one, and returns the ICAO addresses whose episodes
+// started. Starting is a single conditional update, so when replicas observe
+// the same aircraft only one of them sees the episode start.
+func (r *AircraftRepository) ObserveEmergencies(ctx context.Context, now time.Time, declaring, ended []string) ([]string, error) {
+	if len(ended) > 0 {
+		query := `UPDATE aircraft SET emergency_started_at = NULL WHERE emergency_started_at IS NOT NULL AND icao = ANY($1)`
+		if _, err := r.pool.Exec(ctx, query, ended); err != nil {
+			return nil, fmt.Errorf("failed to end aircraft emergencies: %w", err)
+		}
+	}
+	if len(declaring) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		UPDATE aircraft SET emergency_started_at = $2
+		WHERE icao = ANY($1) AND emergency_started_at IS NULL
+		RETURNING icao`
+	rows, err := r.pool.Query(ctx, query, declaring, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start aircraft emergencies: %w", err)
+	}
+	defer rows.Close()
+
+	var started []string
+	for rows.Next() {
+		var icao string
+		if err := rows.Scan(&icao); err != nil {
+			return nil, fmt.Errorf("failed to scan started emergency: %w", err)
+		}
+		started = append(started, icao)
+	}
+	return started, rows.Err()
+}
+
+// EndSilentEmergencies ends the emergency episodes of aircraft not seen since
+// the given time, so a later emergency starts a new one.
+func (r *AircraftRepository) EndSilentEmergencies(ctx context.Context, since time.Time) (int64, error) {
+	query := `UPDATE aircraft SET emergency_started_at = NULL WHERE emergency_started_at IS NOT NULL AND last_seen_at < $1`
+	result, err := r.pool.Exec(ctx, query, since)
+	if err != nil {
+		return 0, fmt.Errorf("failed to end silent aircraft emergencies: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
+
 // Delete removes an aircraft.
 func (r *AircraftRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM aircraft WHERE id = $1`
@@ -278,6 +451,78 @@ func (r *AircraftRepository) GetHistory(ctx context.Context, aircraftID uuid.UUI
 	return history, rows.Err()
 }
 
+// GetTrack returns an aircraft's positions recorded in [from, to], oldest
+// first, up to limit points (the most recent are kept).
+func (r *AircraftRepository) GetTrack(ctx context.Context, aircraftID uuid.UUID, from, to time.Time, limit int) ([]model.AircraftHistory, error) {
+	query := `
+		SELECT * FROM (
+			SELECT id, aircraft_id, latitude, longitude, altitude, ground_speed, track, recorded_at
+			FROM aircraft_history
+			WHERE aircraft_id = $1 AND recorded_at BETWEEN $2 AND $3
+			ORDER BY recorded_at DESC
+			LIMIT $4
+		) recent
+		ORDER BY recorded_at ASC`
+
+	rows, err := r.pool.Query(ctx, query, aircraftID, from, to, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get aircraft track: %w", err)
+	}
+	defer rows.Close()
+
+	history := []model.AircraftHistory{}
+	for rows.Next() {
+		var h model.AircraftHistory
+		err := rows.Scan(&h.ID, &h.AircraftID, &h.Latitude, &h.Longitude,
+			&h.Altitude, &h.GroundSpeed, &h.Track, &h.RecordedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan aircraft track: %w", err)
+		}
+		history = append(history, h)
+	}
+
+	return history, rows.Err()
+}
+
+// GetRecentHistory returns the positions recorded since the given time for
+// the aircraft with the given ICAO addresses, keyed by ICAO address and
+// oldest first, up to limit positions in total (the most recent are kept).
+func (r *AircraftRepository) GetRecentHistory(ctx context.Context, icaos []string, since time.Time, limit int) (map[string][]model.AircraftHistory, error) {
+	query := `
+		SELECT * FROM (
+			SELECT a.icao, h.id, h.aircraft_id, h.latitude, h.longitude, h.altitude,
+				h.ground_speed, h.track, h.recorded_at
+			FROM aircraft_history h
+			JOIN aircraft a ON a.id = h.aircraft_id
+			WHERE a.icao = ANY($1) AND h.recorded_at >= $2
+			ORDER BY h.recorded_at DESC
+			LIMIT $3
+		) recent
+		ORDER BY recorded_at ASC`
+
+	rows, err := r.pool.Query(ctx, query, icaos, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent aircraft history: %w", err)
+	}
+	defer rows.Close()
+
+	history := make(map[string][]model.AircraftHistory)
+	for rows.Next() {
+		var (
+			icao string
+			h    model.AircraftHistory
+		)
+		err := rows.Scan(&icao, &h.ID, &h.AircraftID, &h.Latitude, &h.Longitude,
+			&h.Altitude, &h.GroundSpeed, &h.Track, &h.RecordedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan aircraft history: %w", err)
+		}
+		history[icao] = append(history[icao], h)
+	}
+
+	return history, rows.Err()
+}
+
 // DeleteOldHistory removes history older than the given time.
 func (r *AircraftRepository) DeleteOldHistory(ctx context.Context, before time.Time) (int64, error) {
 	query := `DELETE FROM aircraft_history WHERE recorded_at < $1`
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"chaseapp.tv/api/internal/model"
+)
+
+const aircraftClusterColumns = `id, centroid_lat, centroid_lng, size, aircraft_ids, media_present,
+	first_seen_at, last_seen_at, ended_at`
+
+// SaveClusters upserts the current state of tracked aircraft clusters.
+func (r *AircraftRepository) SaveClusters(ctx context.Context, clusters []model.AircraftCluster) error {
+	if len(clusters) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO aircraft_clusters (
+			id, centroid_lat, centroid_lng, size, aircraft_ids, media_present,
+			first_seen_at, last_seen_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (id) DO UPDATE SET
+			centroid_lat = EXCLUDED.centroid_lat,
+			centroid_lng = EXCLUDED.centroid_lng,
+			size = EXCLUDED.size,
+			aircraft_ids = EXCLUDED.aircraft_ids,
+			media_present = EXCLUDED.media_present,
+			last_seen_at = EXCLUDED.last_seen_at,
+			ended_at = NULL`
+
+	batch := &pgx.Batch{}
+	for _, c := range clusters {
+		batch.Queue(query, c.ID, c.CentroidLat, c.CentroidLng, c.Size, c.AircraftIDs,
+			c.MediaPresent, c.FirstSeenAt, c.LastSeenAt)
+	}
+	if err := r.pool.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to save aircraft clusters: %w", err)
+	}
+	return nil
+}
+
+// EndClusters marks clusters as merged or dissolved.
+func (r *AircraftRepository) EndClusters(ctx context.Context, ids []string, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	query := `UPDATE aircraft_clusters SET ended_at = $2 WHERE id = ANY($1) AND ended_at IS NULL`
+	if _, err := r.pool.Exec(ctx, query, ids, at); err != nil {
+		return fmt.Errorf("failed to end aircraft clusters: %w", err)
+	}
+	return nil
+}
+
+// EndStaleClusters ends clusters not seen since before, e.g. those left
+// open when the tracker stopped.
+func (r *AircraftRepository) EndStaleClusters(ctx context.Context, before time.Time) (int64, error) {
+	query := `UPDATE aircraft_clusters SET ended_at = last_seen_at WHERE ended_at IS NULL AND last_seen_at < $1`
+	result, err := r.pool.Exec(ctx, query, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to end stale aircraft clusters: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
+
+// ListClusters returns the clusters seen since the given time that have not
+// ended, most recently seen first.
+func (r *AircraftRepository) ListClusters(ctx context.Context, since time.Time) ([]model.AircraftCluster, error) {
+	query := `SELECT ` + aircraftClusterColumns + `
+		FROM aircraft_clusters
+		WHERE ended_at IS NULL AND last_seen_at >= $1
+		ORDER BY last_seen_at DESC, id`
+
+	rows, err := r.pool.Query(ctx, query, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list aircraft clusters: %w", err)
+	}
+	defer rows.Close()
+
+	clusters := []model.AircraftCluster{}
+	for rows.Next() {
+		c, err := scanAircraftCluster(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan aircraft cluster: %w", err)
+		}
+		clusters = append(clusters, *c)
+	}
+	return clusters, rows.Err()
+}
+
+// GetCluster retrieves a cluster by ID, including ended ones.
+func (r *AircraftRepository) GetCluster(ctx context.Context, id string) (*model.AircraftCluster, error) {
+	query := `SELECT ` + aircraftClusterColumns + ` FROM aircraft_clusters WHERE id = $1`
+	c, err := scanAircraftCluster(r.pool.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get aircraft cluster: %w", err)
+	}
+	return c, nil
+}
+
+func scanAircraftCluster(row pgx.Row) (*model.AircraftCluster, error) {
+	var c model.AircraftCluster
+	err := row.Scan(&c.ID, &c.CentroidLat, &c.CentroidLng, &c.Size, &c.AircraftIDs,
+		&c.MediaPresent, &c.FirstSeenAt, &c.LastSeenAt, &c.EndedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
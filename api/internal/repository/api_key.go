@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"chaseapp.tv/api/internal/model"
+)
+
+// APIKeyRepository handles API key data access.
+type APIKeyRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewAPIKeyRepository creates a new APIKeyRepository.
+func NewAPIKeyRepository(pool *pgxpool.Pool) *APIKeyRepository {
+	return &APIKeyRepository{pool: pool}
+}
+
+const apiKeyColumns = `id, name, key_prefix, scopes, created_by, expires_at, revoked_at,
+	last_used_at, request_count, created_at`
+
+// Create stores a new API key by its hash.
+func (r *APIKeyRepository) Create(ctx context.Context, input model.CreateAPIKeyInput, keyHash, prefix string, createdBy *uuid.UUID) (*model.APIKey, error) {
+	scopes := make([]string, len(input.Scopes))
+	for i, s := range input.Scopes {
+		scopes[i] = string(s)
+	}
+
+	query := `
+		INSERT INTO api_keys (id, name, key_hash, key_prefix, scopes, created_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + apiKeyColumns
+
+	key, err := scanAPIKey(r.pool.QueryRow(ctx, query,
+		uuid.New(), input.Name, keyHash, prefix, scopes, createdBy, input.ExpiresAt,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create api key: %w", err)
+	}
+	return key, nil
+}
+
+// Authenticate looks up an active key by hash and records the request
+// against it in the same statement.
+func (r *APIKeyRepository) Authenticate(ctx context.Context, keyHash string) (*model.APIKey, error) {
+	query := `
+		UPDATE api_keys SET
+			last_used_at = NOW(),
+			request_count = request_count + 1
+		WHERE key_hash = $1
+			AND revoked_at IS NULL
+			AND (expires_at IS NULL OR expires_at > NOW())
+		RETURNING ` + apiKeyColumns
+
+	key, err := scanAPIKey(r.pool.QueryRow(ctx, query, keyHash))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to authenticate api key: %w", err)
+	}
+	return key, nil
+}
+
+// List returns all API keys, newest first.
+func (r *APIKeyRepository) List(ctx context.Context) ([]model.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY created_at DESC`
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []model.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan api key: %w", err)
+		}
+		keys = append(keys, *key)
+	}
+	return keys, rows.Err()
+}
+
+// Revoke disables a key. Revoking an already revoked key is not an error.
+func (r *APIKeyRepository) Revoke(ctx context.Context, id uuid.UUID) (*model.APIKey, error) {
+	query := `
+		UPDATE api_keys SET revoked_at = COALESCE(revoked_at, NOW())
+		WHERE id = $1
+		RETURNING ` + apiKeyColumns
+
+	key, err := scanAPIKey(r.pool.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	return key, nil
+}
+
+func scanAPIKey(row pgx.Row) (*model.APIKey, error) {
+	var key model.APIKey
+	err := row.Scan(
+		&key.ID, &key.Name, &key.Prefix, &key.Scopes, &key.CreatedBy, &key.ExpiresAt, &key.RevokedAt,
+		&key.LastUsedAt, &key.RequestCount, &key.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
@@ -18,6 +18,12 @@ import (
 // ErrNotFound is returned when a resource is not found.
 var ErrNotFound = errors.New("resource not found")
 
+// ErrConflict is returned when a write violates a uniqueness constraint.
+var ErrConflict = errors.New("resource already exists")
+
+// ErrUserDeleted is returned when signing in to an account that was deleted.
+var ErrUserDeleted = errors.New("user account deleted")
+
 // ChaseRepository handles chase data access.
 type ChaseRepository struct {
 	pool *pgxpool.Pool
@@ -57,9 +63,9 @@ func (r *ChaseRepository) Create(ctx context.Context, input model.CreateChaseInp
 		INSERT INTO chases (
 			id, title, description, chase_type, location, city, state, country,
 			live, started_at, thumbnail_url, streams, source, source_url,
-			created_by, metadata, created_at, updated_at
+			created_by, metadata, created_at, updated_at, draft
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19
 		) RETURNING id, created_at, updated_at`
 
 	var chase model.Chase
@@ -67,7 +73,7 @@ func (r *ChaseRepository) Create(ctx context.Context, input model.CreateChaseInp
 		id, input.Title, input.Description, input.ChaseType, locationJSON,
 		input.City, input.State, input.Country, input.Live, startedAt,
 		input.ThumbnailURL, streamsJSON, input.Source, input.SourceURL,
-		createdBy, metadataJSON, now, now,
+		createdBy, metadataJSON, now, now, input.Draft,
 	).Scan(&chase.ID, &chase.CreatedAt, &chase.UpdatedAt)
 
 	if err != nil {
@@ -83,6 +89,7 @@ func (r *ChaseRepository) Create(ctx context.Context, input model.CreateChaseInp
 	chase.State = input.State
 	chase.Country = input.Country
 	chase.Live = input.Live
+	chase.Draft = input.Draft
 	chase.StartedAt = startedAt
 	chase.ThumbnailURL = input.ThumbnailURL
 	chase.Streams = input.Streams
@@ -99,7 +106,7 @@ func (r *ChaseRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Cha
 	query := `
 		SELECT id, title, description, chase_type, location, city, state, country,
 			   live, started_at, ended_at, thumbnail_url, streams, view_count, share_count,
-			   source, source_url, created_by, metadata, created_at, updated_at
+			   source, source_url, created_by, metadata, created_at, updated_at, draft
 		FROM chases
 		WHERE id = $1 AND deleted_at IS NULL`
 
@@ -112,7 +119,7 @@ func (r *ChaseRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Cha
 		&chase.Live, &chase.StartedAt, &chase.EndedAt, &chase.ThumbnailURL,
 		&streamsJSON, &chase.ViewCount, &chase.ShareCount,
 		&chase.Source, &chase.SourceURL, &chase.CreatedBy, &metadataJSON,
-		&chase.CreatedAt, &chase.UpdatedAt,
+		&chase.CreatedAt, &chase.UpdatedAt, &chase.Draft,
 	)
 
 	if errors.Is(err, pgx.ErrNoRows) {
@@ -155,9 +162,9 @@ func (r *ChaseRepository) List(ctx context.Context, opts model.ChaseListOptions)
 	offset := (opts.Page - 1) * opts.Limit
 
 	// Build query with filters
-	baseQuery := `FROM chases WHERE deleted_at IS NULL`
-	args := []interface{}{}
-	argNum := 1
+	baseQuery := `FROM chases WHERE deleted_at IS NULL AND draft = $1`
+	args := []interface{}{opts.Drafts}
+	argNum := 2
 
 	if opts.Live != nil {
 		baseQuery += fmt.Sprintf(" AND live = $%d", argNum)
@@ -191,7 +198,7 @@ func (r *ChaseRepository) List(ctx context.Context, opts model.ChaseListOptions)
 	selectQuery := fmt.Sprintf(`
 		SELECT id, title, description, chase_type, location, city, state, country,
 			   live, started_at, ended_at, thumbnail_url, streams, view_count, share_count,
-			   source, source_url, created_by, metadata, created_at, updated_at
+			   source, source_url, created_by, metadata, created_at, updated_at, draft
 		%s ORDER BY created_at DESC LIMIT $%d OFFSET $%d`,
 		baseQuery, argNum, argNum+1)
 
@@ -214,7 +221,7 @@ func (r *ChaseRepository) List(ctx context.Context, opts model.ChaseListOptions)
 			&chase.Live, &chase.StartedAt, &chase.EndedAt, &chase.ThumbnailURL,
 			&streamsJSON, &chase.ViewCount, &chase.ShareCount,
 			&chase.Source, &chase.SourceURL, &chase.CreatedBy, &metadataJSON,
-			&chase.CreatedAt, &chase.UpdatedAt,
+			&chase.CreatedAt, &chase.UpdatedAt, &chase.Draft,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan chase: %w", err)
@@ -286,6 +293,9 @@ func (r *ChaseRepository) Update(ctx context.Context, id uuid.UUID, input model.
 			chase.EndedAt = endedAt
 		}
 	}
+	if input.Draft != nil {
+		chase.Draft = *input.Draft
+	}
 	if input.ThumbnailURL != nil {
 		chase.ThumbnailURL = *input.ThumbnailURL
 	}
@@ -304,13 +314,13 @@ func (r *ChaseRepository) Update(ctx context.Context, id uuid.UUID, input model.
 		UPDATE chases SET
 			title = $2, description = $3, location = $4, city = $5, state = $6,
 			live = $7, ended_at = $8, thumbnail_url = $9, streams = $10,
-			metadata = $11, updated_at = NOW()
+			metadata = $11, draft = $12, updated_at = NOW()
 		WHERE id = $1 AND deleted_at IS NULL
 		RETURNING updated_at`
 
 	err = r.pool.QueryRow(ctx, query,
 		id, chase.Title, chase.Description, locationJSON, chase.City, chase.State,
-		chase.Live, endedAt, chase.ThumbnailURL, streamsJSON, metadataJSON,
+		chase.Live, endedAt, chase.ThumbnailURL, streamsJSON, metadataJSON, chase.Draft,
 	).Scan(&chase.UpdatedAt)
 
 	if errors.Is(err, pgx.ErrNoRows) {
@@ -339,6 +349,40 @@ func (r *ChaseRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+// EndEmergencyChases ends the live chases created for aircraft emergencies
+// whose episode is over: the aircraft is gone, no longer in an emergency, or
+// in one that started after the chase. Only chases the emergency worker
+// created (ADS-B aircraft chases) are considered, whatever metadata other
+// chases carry. It returns the IDs of the chases ended.
+func (r *ChaseRepository) EndEmergencyChases(ctx context.Context, now time.Time) ([]uuid.UUID, error) {
+	query := `
+		UPDATE chases c SET live = false, ended_at = $1
+		WHERE c.live AND c.deleted_at IS NULL AND c.metadata ? 'emergency'
+			AND c.source = 'adsb' AND c.chase_type = 'aircraft'
+			AND NOT EXISTS (
+				SELECT 1 FROM aircraft a
+				WHERE a.icao = c.metadata->'emergency'->>'icao'
+					AND a.emergency_started_at <= c.created_at
+			)
+		RETURNING c.id`
+
+	rows, err := r.pool.Query(ctx, query, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to end emergency chases: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan ended chase: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
 // IncrementViewCount increments the view count for a chase.
 func (r *ChaseRepository) IncrementViewCount(ctx context.Context, id uuid.UUID) error {
 	query := `UPDATE chases SET view_count = view_count + 1 WHERE id = $1 AND deleted_at IS NULL`
@@ -367,10 +411,10 @@ func (r *ChaseRepository) GetLiveChases(ctx context.Context) ([]model.Chase, err
 
 // CountChases returns total and live counts.
 func (r *ChaseRepository) CountChases(ctx context.Context) (total int, live int, err error) {
-	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM chases WHERE deleted_at IS NULL`).Scan(&total); err != nil {
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM chases WHERE deleted_at IS NULL AND NOT draft`).Scan(&total); err != nil {
 		return 0, 0, fmt.Errorf("count chases: %w", err)
 	}
-	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM chases WHERE deleted_at IS NULL AND live = true`).Scan(&live); err != nil {
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM chases WHERE deleted_at IS NULL AND NOT draft AND live = true`).Scan(&live); err != nil {
 		return 0, 0, fmt.Errorf("count live chases: %w", err)
 	}
 	return total, live, nil
@@ -3,7 +3,6 @@ package repository
 import (
 	"context"
 	"fmt"
-	"os"
 	"testing"
 	"time"
 
@@ -16,6 +15,7 @@ import (
 
 func setupTestDB(t *testing.T) *pgxpool.Pool {
 	t.Helper()
+	tc.SkipIfProviderIsNotHealthy(t)
 
 	ctx := context.Background()
 	req := tc.ContainerRequest{
@@ -59,6 +59,7 @@ CREATE TABLE chases (
 	state TEXT,
 	country TEXT,
 	live BOOLEAN DEFAULT false,
+	draft BOOLEAN NOT NULL DEFAULT false,
 	started_at TIMESTAMP,
 	ended_at TIMESTAMP,
 	thumbnail_url TEXT,
@@ -0,0 +1,321 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"chaseapp.tv/api/internal/model"
+)
+
+// ChatRepository handles chat channels, roles and moderation data access.
+type ChatRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewChatRepository creates a new ChatRepository.
+func NewChatRepository(pool *pgxpool.Pool) *ChatRepository {
+	return &ChatRepository{pool: pool}
+}
+
+// EnsureChannel returns the chase's channel, creating it, named after the
+// chase, if needed. Draft chases have no channel and return ErrNotFound.
+func (r *ChatRepository) EnsureChannel(ctx context.Context, chaseID uuid.UUID) (*model.ChatChannel, error) {
+	query := `
+		WITH chase AS (
+			SELECT id, title FROM chases
+			WHERE id = $2 AND deleted_at IS NULL AND NOT draft
+		), inserted AS (
+			INSERT INTO chat_channels (id, chase_id, name)
+			SELECT $1, id, title FROM chase
+			ON CONFLICT (chase_id) DO NOTHING
+			RETURNING id, chase_id, name, created_at
+		)
+		SELECT id, chase_id, name, created_at FROM inserted
+		UNION ALL
+		SELECT ch.id, ch.chase_id, ch.name, ch.created_at
+		FROM chat_channels ch JOIN chase ON chase.id = ch.chase_id
+		LIMIT 1`
+
+	var ch model.ChatChannel
+	err := r.pool.QueryRow(ctx, query, uuid.New(), chaseID).Scan(&ch.ID, &ch.ChaseID, &ch.Name, &ch.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to ensure chat channel: %w", err)
+	}
+	return &ch, nil
+}
+
+// GetChannel retrieves a channel by ID.
+func (r *ChatRepository) GetChannel(ctx context.Context, id uuid.UUID) (*model.ChatChannel, error) {
+	query := `SELECT id, chase_id, name, created_at FROM chat_channels WHERE id = $1`
+
+	var ch model.ChatChannel
+	err := r.pool.QueryRow(ctx, query, id).Scan(&ch.ID, &ch.ChaseID, &ch.Name, &ch.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chat channel: %w", err)
+	}
+	return &ch, nil
+}
+
+// GetRole returns a user's chat role.
+func (r *ChatRepository) GetRole(ctx context.Context, userID uuid.UUID) (model.ChatRole, error) {
+	query := `SELECT chat_role FROM users WHERE id = $1 AND deleted_at IS NULL`
+
+	var role model.ChatRole
+	err := r.pool.QueryRow(ctx, query, userID).Scan(&role)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get chat role: %w", err)
+	}
+	return role, nil
+}
+
+// SetRole changes a user's chat role.
+func (r *ChatRepository) SetRole(ctx context.Context, userID uuid.UUID, role model.ChatRole) error {
+	query := `UPDATE users SET chat_role = $2, updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
+
+	result, err := r.pool.Exec(ctx, query, userID, string(role))
+	if err != nil {
+		return fmt.Errorf("failed to set chat role: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+const moderationColumns = `id, channel_id, user_id, action, COALESCE(reason, ''), moderator_id,
+	expires_at, lifted_at, created_at`
+
+// CreateModeration records a mute or ban.
+func (r *ChatRepository) CreateModeration(ctx context.Context, a *model.ChatModerationAction) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+
+	query := `
+		INSERT INTO chat_moderation_actions (id, channel_id, user_id, action, reason, moderator_id, expires_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7)
+		RETURNING created_at`
+
+	err := r.pool.QueryRow(ctx, query,
+		a.ID, a.ChannelID, a.UserID, string(a.Action), a.Reason, a.ModeratorID, a.ExpiresAt,
+	).Scan(&a.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create moderation action: %w", err)
+	}
+	return nil
+}
+
+// GetModeration retrieves a moderation action by ID.
+func (r *ChatRepository) GetModeration(ctx context.Context, id uuid.UUID) (*model.ChatModerationAction, error) {
+	query := `SELECT ` + moderationColumns + ` FROM chat_moderation_actions WHERE id = $1`
+
+	a, err := scanModeration(r.pool.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get moderation action: %w", err)
+	}
+	return a, nil
+}
+
+// LiftModeration ends a mute or ban early.
+func (r *ChatRepository) LiftModeration(ctx context.Context, id uuid.UUID) (*model.ChatModerationAction, error) {
+	query := `
+		UPDATE chat_moderation_actions SET lifted_at = COALESCE(lifted_at, NOW())
+		WHERE id = $1
+		RETURNING ` + moderationColumns
+
+	a, err := scanModeration(r.pool.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lift moderation action: %w", err)
+	}
+	return a, nil
+}
+
+// ActiveModeration returns the user's unexpired, unlifted sanctions that
+// apply to the channel, including global ones. A nil channel returns the
+// sanctions in every channel.
+func (r *ChatRepository) ActiveModeration(ctx context.Context, userID uuid.UUID, channelID *uuid.UUID) ([]model.ChatModerationAction, error) {
+	query := `
+		SELECT ` + moderationColumns + `
+		FROM chat_moderation_actions
+		WHERE user_id = $1
+			AND ($2::uuid IS NULL OR channel_id IS NULL OR channel_id = $2)
+			AND lifted_at IS NULL
+			AND (expires_at IS NULL OR expires_at > $3)
+		ORDER BY created_at DESC`
+
+	rows, err := r.pool.Query(ctx, query, userID, channelID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active moderation: %w", err)
+	}
+	defer rows.Close()
+
+	return scanModerations(rows)
+}
+
+// ListModeration returns a channel's moderation history, newest first.
+func (r *ChatRepository) ListModeration(ctx context.Context, channelID uuid.UUID) ([]model.ChatModerationAction, error) {
+	query := `
+		SELECT ` + moderationColumns + `
+		FROM chat_moderation_actions
+		WHERE channel_id = $1
+		ORDER BY created_at DESC
+		LIMIT 100`
+
+	rows, err := r.pool.Query(ctx, query, channelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list moderation actions: %w", err)
+	}
+	defer rows.Close()
+
+	return scanModerations(rows)
+}
+
+func scanModeration(row pgx.Row) (*model.ChatModerationAction, error) {
+	var a model.ChatModerationAction
+	err := row.Scan(
+		&a.ID, &a.ChannelID, &a.UserID, &a.Action, &a.Reason, &a.ModeratorID,
+		&a.ExpiresAt, &a.LiftedAt, &a.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func scanModerations(rows pgx.Rows) ([]model.ChatModerationAction, error) {
+	actions := []model.ChatModerationAction{}
+	for rows.Next() {
+		a, err := scanModeration(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan moderation action: %w", err)
+		}
+		actions = append(actions, *a)
+	}
+	return actions, rows.Err()
+}
+
+// RecordToken stores an issued chat token.
+func (r *ChatRepository) RecordToken(ctx context.Context, t *model.ChatToken) error {
+	query := `
+		INSERT INTO chat_tokens (jti, user_id, channel_id, issued_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5)`
+
+	_, err := r.pool.Exec(ctx, query, t.ID, t.UserID, t.ChannelID, t.IssuedAt, t.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to record chat token: %w", err)
+	}
+	return nil
+}
+
+// RevokeToken revokes a chat token by jti. Revoking an already revoked
+// token keeps the original reason.
+func (r *ChatRepository) RevokeToken(ctx context.Context, jti uuid.UUID, reason string) (*model.ChatToken, error) {
+	query := `
+		UPDATE chat_tokens
+		SET revoked_at = COALESCE(revoked_at, NOW()),
+			revoked_reason = COALESCE(revoked_reason, NULLIF($2, ''))
+		WHERE jti = $1
+		RETURNING ` + tokenColumns
+
+	t, err := scanToken(r.pool.QueryRow(ctx, query, jti, reason))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke chat token: %w", err)
+	}
+	return t, nil
+}
+
+// RevokeUserTokens revokes a user's chat tokens that expire after
+// expiresAfter. With a channel, only tokens for that channel and unscoped
+// tokens are revoked.
+func (r *ChatRepository) RevokeUserTokens(ctx context.Context, userID uuid.UUID, channelID *uuid.UUID, reason string, expiresAfter time.Time) ([]model.ChatToken, error) {
+	query := `
+		UPDATE chat_tokens
+		SET revoked_at = NOW(), revoked_reason = NULLIF($3, '')
+		WHERE user_id = $1
+			AND ($2::uuid IS NULL OR channel_id IS NULL OR channel_id = $2)
+			AND revoked_at IS NULL
+			AND expires_at > $4
+		RETURNING ` + tokenColumns
+
+	rows, err := r.pool.Query(ctx, query, userID, channelID, reason, expiresAfter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke chat tokens: %w", err)
+	}
+	defer rows.Close()
+
+	return scanTokens(rows)
+}
+
+// RevokedTokens returns tokens revoked after since that expire after
+// expiresAfter.
+func (r *ChatRepository) RevokedTokens(ctx context.Context, since, expiresAfter time.Time) ([]model.ChatToken, error) {
+	query := `
+		SELECT ` + tokenColumns + `
+		FROM chat_tokens
+		WHERE revoked_at IS NOT NULL AND revoked_at > $1 AND expires_at > $2
+		ORDER BY revoked_at`
+
+	rows, err := r.pool.Query(ctx, query, since, expiresAfter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list revoked chat tokens: %w", err)
+	}
+	defer rows.Close()
+
+	return scanTokens(rows)
+}
+
+// DeleteExpiredTokens removes tokens that expired before the cutoff.
+func (r *ChatRepository) DeleteExpiredTokens(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.pool.Exec(ctx, `DELETE FROM chat_tokens WHERE expires_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired chat tokens: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
+
+const tokenColumns = `jti, user_id, channel_id, issued_at, expires_at, revoked_at, COALESCE(revoked_reason, '')`
+
+func scanToken(row pgx.Row) (*model.ChatToken, error) {
+	var t model.ChatToken
+	err := row.Scan(&t.ID, &t.UserID, &t.ChannelID, &t.IssuedAt, &t.ExpiresAt, &t.RevokedAt, &t.RevokedReason)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func scanTokens(rows pgx.Rows) ([]model.ChatToken, error) {
+	tokens := []model.ChatToken{}
+	for rows.Next() {
+		t, err := scanToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan chat token: %w", err)
+		}
+		tokens = append(tokens, *t)
+	}
+	return tokens, rows.Err()
+}
@@ -0,0 +1,286 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"chaseapp.tv/api/internal/model"
+)
+
+// NotificationRepository handles notification history data access.
+type NotificationRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewNotificationRepository creates a new NotificationRepository.
+func NewNotificationRepository(pool *pgxpool.Pool) *NotificationRepository {
+	return &NotificationRepository{pool: pool}
+}
+
+const notificationColumns = `n.id, n.title, n.body, n.image_url, n.chase_id, n.topic, n.private,
+	n.target_count, n.filtered_count, n.deactivated_count, n.succeeded, n.failed, n.created_at`
+
+// Create records a dispatched notification and the outcome for each
+// recipient user. Recipients that no longer exist are skipped.
+func (r *NotificationRepository) Create(ctx context.Context, n *model.Notification, recipients map[uuid.UUID]model.RecipientStatus) error {
+	if n.ID == uuid.Nil {
+		n.ID = uuid.New()
+	}
+
+	succeeded, err := json.Marshal(n.Succeeded)
+	if err != nil {
+		return fmt.Errorf("failed to marshal succeeded counts: %w", err)
+	}
+	failed, err := json.Marshal(n.Failed)
+	if err != nil {
+		return fmt.Errorf("failed to marshal failed counts: %w", err)
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	query := `
+		INSERT INTO notifications (id, title, body, image_url, chase_id, topic, private,
+			target_count, filtered_count, deactivated_count, succeeded, failed)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING created_at`
+
+	err = tx.QueryRow(ctx, query,
+		n.ID, n.Title, n.Body, n.ImageURL, n.ChaseID, n.Topic, n.Private,
+		n.TargetCount, n.FilteredCount, n.DeactivatedCount, succeeded, failed,
+	).Scan(&n.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+
+	if len(recipients) > 0 {
+		userIDs := make([]uuid.UUID, 0, len(recipients))
+		statuses := make([]string, 0, len(recipients))
+		for id, status := range recipients {
+			userIDs = append(userIDs, id)
+			statuses = append(statuses, string(status))
+		}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO notification_recipients (notification_id, user_id, status)
+			SELECT $1, r.user_id, r.status
+			FROM unnest($2::uuid[], $3::text[]) AS r(user_id, status)
+			WHERE EXISTS (SELECT 1 FROM users u WHERE u.id = r.user_id)
+			ON CONFLICT DO NOTHING`,
+			n.ID, userIDs, statuses,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create notification recipients: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit notification: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a notification by ID.
+func (r *NotificationRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Notification, error) {
+	query := `SELECT ` + notificationColumns + ` FROM notifications n WHERE n.id = $1`
+
+	n, err := scanNotification(r.pool.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification: %w", err)
+	}
+	return n, nil
+}
+
+// List returns the global notification feed, newest first. Private
+// notifications are left out unless opts.Private is set.
+func (r *NotificationRepository) List(ctx context.Context, opts model.NotificationListOptions) (*model.NotificationListResult, error) {
+	if opts.Page < 1 {
+		opts.Page = 1
+	}
+	if opts.Limit < 1 || opts.Limit > 100 {
+		opts.Limit = 20
+	}
+	offset := (opts.Page - 1) * opts.Limit
+
+	baseQuery := `FROM notifications n WHERE 1=1`
+	args := []interface{}{}
+	argNum := 1
+
+	if !opts.Private {
+		baseQuery += " AND NOT n.private"
+	}
+	if opts.Topic != "" {
+		baseQuery += fmt.Sprintf(" AND n.topic = $%d", argNum)
+		args = append(args, opts.Topic)
+		argNum++
+	}
+	if opts.ChaseID != nil {
+		baseQuery += fmt.Sprintf(" AND n.chase_id = $%d", argNum)
+		args = append(args, *opts.ChaseID)
+		argNum++
+	}
+
+	var total int
+	if err := r.pool.QueryRow(ctx, "SELECT COUNT(*) "+baseQuery, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("failed to count notifications: %w", err)
+	}
+
+	selectQuery := fmt.Sprintf(`SELECT %s %s ORDER BY n.created_at DESC LIMIT $%d OFFSET $%d`,
+		notificationColumns, baseQuery, argNum, argNum+1)
+	args = append(args, opts.Limit, offset)
+
+	rows, err := r.pool.Query(ctx, selectQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notifications: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := []model.Notification{}
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		notifications = append(notifications, *n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate notifications: %w", err)
+	}
+
+	return &model.NotificationListResult{
+		Notifications: notifications,
+		Total:         total,
+		Page:          opts.Page,
+		Limit:         opts.Limit,
+		TotalPages:    (total + opts.Limit - 1) / opts.Limit,
+	}, nil
+}
+
+// ListInbox returns the notifications sent to a user, newest first.
+// Notifications suppressed by the user's preferences are not included.
+func (r *NotificationRepository) ListInbox(ctx context.Context, userID uuid.UUID, opts model.NotificationListOptions) (*model.InboxResult, error) {
+	if opts.Page < 1 {
+		opts.Page = 1
+	}
+	if opts.Limit < 1 || opts.Limit > 100 {
+		opts.Limit = 20
+	}
+	offset := (opts.Page - 1) * opts.Limit
+
+	baseQuery := `
+		FROM notification_recipients nr
+		JOIN notifications n ON n.id = nr.notification_id
+		WHERE nr.user_id = $1 AND nr.status <> 'filtered'`
+	if opts.UnreadOnly {
+		baseQuery += ` AND nr.read_at IS NULL`
+	}
+
+	var total, unread int
+	err := r.pool.QueryRow(ctx, `
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE nr.read_at IS NULL) `+baseQuery, userID,
+	).Scan(&total, &unread)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count inbox: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+notificationColumns+`, nr.status, nr.read_at `+baseQuery+`
+		ORDER BY nr.created_at DESC LIMIT $2 OFFSET $3`,
+		userID, opts.Limit, offset,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list inbox: %w", err)
+	}
+	defer rows.Close()
+
+	items := []model.InboxNotification{}
+	for rows.Next() {
+		var item model.InboxNotification
+		var succeeded, failed []byte
+		err := rows.Scan(
+			&item.ID, &item.Title, &item.Body, &item.ImageURL, &item.ChaseID, &item.Topic, &item.Private,
+			&item.TargetCount, &item.FilteredCount, &item.DeactivatedCount, &succeeded, &failed,
+			&item.CreatedAt, &item.Status, &item.ReadAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan inbox notification: %w", err)
+		}
+		json.Unmarshal(succeeded, &item.Succeeded)
+		json.Unmarshal(failed, &item.Failed)
+		item.Read = item.ReadAt != nil
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate inbox: %w", err)
+	}
+
+	return &model.InboxResult{
+		Notifications: items,
+		Unread:        unread,
+		Total:         total,
+		Page:          opts.Page,
+		Limit:         opts.Limit,
+		TotalPages:    (total + opts.Limit - 1) / opts.Limit,
+	}, nil
+}
+
+// SetRead marks one of a user's notifications as read or unread.
+func (r *NotificationRepository) SetRead(ctx context.Context, userID, notificationID uuid.UUID, read bool) error {
+	query := `
+		UPDATE notification_recipients
+		SET read_at = CASE WHEN $3 THEN COALESCE(read_at, NOW()) ELSE NULL END
+		WHERE user_id = $1 AND notification_id = $2 AND status <> 'filtered'`
+
+	result, err := r.pool.Exec(ctx, query, userID, notificationID, read)
+	if err != nil {
+		return fmt.Errorf("failed to update read state: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// MarkAllRead marks every unread notification in a user's inbox as read and
+// returns how many changed.
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, userID uuid.UUID) (int64, error) {
+	query := `
+		UPDATE notification_recipients SET read_at = NOW()
+		WHERE user_id = $1 AND read_at IS NULL AND status <> 'filtered'`
+
+	result, err := r.pool.Exec(ctx, query, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications read: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
+
+func scanNotification(row pgx.Row) (*model.Notification, error) {
+	var n model.Notification
+	var succeeded, failed []byte
+
+	err := row.Scan(
+		&n.ID, &n.Title, &n.Body, &n.ImageURL, &n.ChaseID, &n.Topic, &n.Private,
+		&n.TargetCount, &n.FilteredCount, &n.DeactivatedCount, &succeeded, &failed,
+		&n.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	json.Unmarshal(succeeded, &n.Succeeded)
+	json.Unmarshal(failed, &n.Failed)
+	return &n, nil
+}
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"chaseapp.tv/api/internal/model"
+)
+
+// NotificationPreferencesRepository handles notification preference data access.
+type NotificationPreferencesRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewNotificationPreferencesRepository creates a new NotificationPreferencesRepository.
+func NewNotificationPreferencesRepository(pool *pgxpool.Pool) *NotificationPreferencesRepository {
+	return &NotificationPreferencesRepository{pool: pool}
+}
+
+// Preferences are read through users so that users without a row get the
+// defaults along with their notifications_enabled flag.
+const notificationPreferencesSelect = `
+	SELECT u.id, u.notifications_enabled, p.chase_types, p.home_lat, p.home_lng,
+		   p.radius_km, p.states, p.quiet_hours_start, p.quiet_hours_end, p.timezone,
+		   COALESCE(p.created_at, u.created_at), COALESCE(p.updated_at, u.updated_at)
+	FROM users u
+	LEFT JOIN notification_preferences p ON p.user_id = u.id`
+
+// Get retrieves a user's notification preferences.
+func (r *NotificationPreferencesRepository) Get(ctx context.Context, userID uuid.UUID) (*model.NotificationPreferences, error) {
+	query := notificationPreferencesSelect + `
+		WHERE u.id = $1 AND u.deleted_at IS NULL`
+
+	prefs, err := scanNotificationPreferences(r.pool.QueryRow(ctx, query, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+	return prefs, nil
+}
+
+// GetByUserIDs retrieves preferences for many users, keyed by user ID.
+// Deleted or unknown users are omitted.
+func (r *NotificationPreferencesRepository) GetByUserIDs(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]*model.NotificationPreferences, error) {
+	query := notificationPreferencesSelect + `
+		WHERE u.id = ANY($1) AND u.deleted_at IS NULL`
+
+	rows, err := r.pool.Query(ctx, query, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[uuid.UUID]*model.NotificationPreferences, len(userIDs))
+	for rows.Next() {
+		prefs, err := scanNotificationPreferences(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification preferences: %w", err)
+		}
+		result[prefs.UserID] = prefs
+	}
+	return result, rows.Err()
+}
+
+// Upsert replaces a user's notification preferences.
+func (r *NotificationPreferencesRepository) Upsert(ctx context.Context, userID uuid.UUID, input model.UpdateNotificationPreferencesInput) (*model.NotificationPreferences, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	result, err := tx.Exec(ctx, `
+		UPDATE users SET notifications_enabled = COALESCE($2, notifications_enabled)
+		WHERE id = $1 AND deleted_at IS NULL`, userID, input.Enabled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update notifications enabled: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return nil, ErrNotFound
+	}
+
+	chaseTypes := make([]string, len(input.ChaseTypes))
+	for i, t := range input.ChaseTypes {
+		chaseTypes[i] = string(t)
+	}
+	states := input.States
+	if states == nil {
+		states = []string{}
+	}
+
+	var homeLat, homeLng, radius *float64
+	if input.HomeLocation != nil {
+		homeLat, homeLng = &input.HomeLocation.Lat, &input.HomeLocation.Lng
+	}
+	if input.RadiusKm > 0 {
+		radius = &input.RadiusKm
+	}
+
+	var quietStart, quietEnd *string
+	timezone := "UTC"
+	if input.QuietHours != nil {
+		quietStart, quietEnd = &input.QuietHours.Start, &input.QuietHours.End
+		if input.QuietHours.Timezone != "" {
+			timezone = input.QuietHours.Timezone
+		}
+	}
+
+	query := `
+		INSERT INTO notification_preferences (
+			user_id, chase_types, home_lat, home_lng, radius_km, states,
+			quiet_hours_start, quiet_hours_end, timezone
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (user_id) DO UPDATE SET
+			chase_types = EXCLUDED.chase_types,
+			home_lat = EXCLUDED.home_lat,
+			home_lng = EXCLUDED.home_lng,
+			radius_km = EXCLUDED.radius_km,
+			states = EXCLUDED.states,
+			quiet_hours_start = EXCLUDED.quiet_hours_start,
+			quiet_hours_end = EXCLUDED.quiet_hours_end,
+			timezone = EXCLUDED.timezone`
+
+	if _, err := tx.Exec(ctx, query,
+		userID, chaseTypes, homeLat, homeLng, radius, states,
+		quietStart, quietEnd, timezone,
+	); err != nil {
+		return nil, fmt.Errorf("failed to upsert notification preferences: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit notification preferences: %w", err)
+	}
+
+	return r.Get(ctx, userID)
+}
+
+// Delete resets a user's notification preferences to the defaults.
+func (r *NotificationPreferencesRepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	query := `DELETE FROM notification_preferences WHERE user_id = $1`
+	if _, err := r.pool.Exec(ctx, query, userID); err != nil {
+		return fmt.Errorf("failed to delete notification preferences: %w", err)
+	}
+	return nil
+}
+
+func scanNotificationPreferences(row pgx.Row) (*model.NotificationPreferences, error) {
+	var (
+		prefs                model.NotificationPreferences
+		chaseTypes           []string
+		homeLat, homeLng     *float64
+		radius               *float64
+		quietStart, quietEnd *string
+		timezone             *string
+	)
+
+	err := row.Scan(
+		&prefs.UserID, &prefs.Enabled, &chaseTypes, &homeLat, &homeLng,
+		&radius, &prefs.States, &quietStart, &quietEnd, &timezone,
+		&prefs.CreatedAt, &prefs.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	prefs.ChaseTypes = make([]model.ChaseType, len(chaseTypes))
+	for i, t := range chaseTypes {
+		prefs.ChaseTypes[i] = model.ChaseType(t)
+	}
+	if prefs.States == nil {
+		prefs.States = []string{}
+	}
+	if homeLat != nil && homeLng != nil {
+		prefs.HomeLocation = &model.Location{Lat: *homeLat, Lng: *homeLng}
+	}
+	if radius != nil {
+		prefs.RadiusKm = *radius
+	}
+	if quietStart != nil && quietEnd != nil {
+		prefs.QuietHours = &model.QuietHours{Start: *quietStart, End: *quietEnd, Timezone: "UTC"}
+		if timezone != nil {
+			prefs.QuietHours.Timezone = *timezone
+		}
+	}
+
+	return &prefs, nil
+}
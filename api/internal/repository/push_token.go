@@ -30,14 +30,15 @@ func (r *PushTokenRepository) Create(ctx context.Context, userID *uuid.UUID, inp
 	now := time.Now()
 
 	query := `
-		INSERT INTO push_tokens (id, user_id, token, platform, device_id, device_name, app_version, subscribed_topics, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO push_tokens (id, user_id, token, platform, device_id, device_name, app_version, subscribed_topics, metadata, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		ON CONFLICT (token, platform) DO UPDATE SET
 			user_id = COALESCE(EXCLUDED.user_id, push_tokens.user_id),
 			device_id = COALESCE(EXCLUDED.device_id, push_tokens.device_id),
 			device_name = COALESCE(EXCLUDED.device_name, push_tokens.device_name),
 			app_version = COALESCE(EXCLUDED.app_version, push_tokens.app_version),
 			subscribed_topics = COALESCE(EXCLUDED.subscribed_topics, push_tokens.subscribed_topics),
+			metadata = COALESCE(push_tokens.metadata, '{}'::jsonb) || EXCLUDED.metadata,
 			is_active = true,
 			last_used_at = NOW(),
 			updated_at = NOW()
@@ -52,9 +53,18 @@ func (r *PushTokenRepository) Create(ctx context.Context, userID *uuid.UUID, inp
 		topics = []string{}
 	}
 
-	err := r.pool.QueryRow(ctx, query,
+	metadata := input.Metadata
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal push token metadata: %w", err)
+	}
+
+	err = r.pool.QueryRow(ctx, query,
 		id, userID, input.Token, input.Platform, input.DeviceID,
-		input.DeviceName, input.AppVersion, topics, now, now,
+		input.DeviceName, input.AppVersion, topics, metadataJSON, now, now,
 	).Scan(
 		&token.ID, &token.UserID, &token.Token, &token.Platform,
 		&token.DeviceID, &token.DeviceName, &token.AppVersion,
@@ -147,6 +157,60 @@ func (r *PushTokenRepository) GetByPlatformAndTopic(ctx context.Context, platfor
 	return r.scanTokens(rows)
 }
 
+// Find retrieves active tokens matching filter.
+func (r *PushTokenRepository) Find(ctx context.Context, filter model.PushTokenFilter) ([]model.PushToken, error) {
+	query := `
+		SELECT id, user_id, token, platform, device_id, device_name, app_version,
+			   subscribed_topics, is_active, last_used_at, metadata, created_at, updated_at
+		FROM push_tokens
+		WHERE is_active = true`
+
+	args := []interface{}{}
+	argNum := 1
+
+	if len(filter.Platforms) > 0 {
+		platforms := make([]string, len(filter.Platforms))
+		for i, p := range filter.Platforms {
+			platforms[i] = string(p)
+		}
+		query += fmt.Sprintf(" AND platform = ANY($%d)", argNum)
+		args = append(args, platforms)
+		argNum++
+	}
+
+	if filter.Topic != "" {
+		query += fmt.Sprintf(" AND $%d = ANY(subscribed_topics)", argNum)
+		args = append(args, filter.Topic)
+		argNum++
+	}
+
+	if len(filter.UserIDs) > 0 {
+		query += fmt.Sprintf(" AND user_id = ANY($%d)", argNum)
+		args = append(args, filter.UserIDs)
+		argNum++
+	}
+
+	if filter.Near != nil {
+		// Haversine distance in km between the home location and Near.
+		query += fmt.Sprintf(` AND user_id IN (
+			SELECT user_id FROM notification_preferences
+			WHERE home_lat IS NOT NULL AND 12742 * asin(sqrt(
+				power(sin(radians(home_lat - $%d) / 2), 2) +
+				cos(radians($%d)) * cos(radians(home_lat)) * power(sin(radians(home_lng - $%d) / 2), 2)
+			)) <= $%d)`, argNum, argNum, argNum+1, argNum+2)
+		args = append(args, filter.Near.Lat, filter.Near.Lng, filter.RadiusKm)
+		argNum += 3
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find push tokens: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanTokens(rows)
+}
+
 // Update updates a push token.
 func (r *PushTokenRepository) Update(ctx context.Context, id uuid.UUID, input model.UpdatePushTokenInput) (*model.PushToken, error) {
 	token, err := r.GetByID(ctx, id)
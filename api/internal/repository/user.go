@@ -8,6 +8,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"chaseapp.tv/api/internal/model"
@@ -30,7 +31,7 @@ func (r *UserRepository) Create(ctx context.Context, input model.CreateUserInput
 
 	query := `
 		INSERT INTO users (id, external_id, email, display_name, photo_url, provider, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7, $8)
 		RETURNING id, created_at, updated_at`
 
 	var user model.User
@@ -48,6 +49,7 @@ func (r *UserRepository) Create(ctx context.Context, input model.CreateUserInput
 	user.PhotoURL = input.PhotoURL
 	user.Provider = input.Provider
 	user.NotificationsEnabled = true
+	user.Roles = []model.Role{model.RoleUser}
 
 	return &user, nil
 }
@@ -55,15 +57,15 @@ func (r *UserRepository) Create(ctx context.Context, input model.CreateUserInput
 // GetByID retrieves a user by ID.
 func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.User, error) {
 	query := `
-		SELECT id, external_id, email, display_name, photo_url, provider,
-			   notifications_enabled, created_at, updated_at, last_login_at
+		SELECT id, external_id, COALESCE(email, ''), display_name, photo_url, provider,
+			   notifications_enabled, roles, created_at, updated_at, last_login_at
 		FROM users
 		WHERE id = $1 AND deleted_at IS NULL`
 
 	var user model.User
 	err := r.pool.QueryRow(ctx, query, id).Scan(
 		&user.ID, &user.ExternalID, &user.Email, &user.DisplayName, &user.PhotoURL,
-		&user.Provider, &user.NotificationsEnabled, &user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt,
+		&user.Provider, &user.NotificationsEnabled, &user.Roles, &user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt,
 	)
 
 	if errors.Is(err, pgx.ErrNoRows) {
@@ -79,15 +81,15 @@ func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.User
 // GetByExternalID retrieves a user by external OAuth ID.
 func (r *UserRepository) GetByExternalID(ctx context.Context, externalID string) (*model.User, error) {
 	query := `
-		SELECT id, external_id, email, display_name, photo_url, provider,
-			   notifications_enabled, created_at, updated_at, last_login_at
+		SELECT id, external_id, COALESCE(email, ''), display_name, photo_url, provider,
+			   notifications_enabled, roles, created_at, updated_at, last_login_at
 		FROM users
 		WHERE external_id = $1 AND deleted_at IS NULL`
 
 	var user model.User
 	err := r.pool.QueryRow(ctx, query, externalID).Scan(
 		&user.ID, &user.ExternalID, &user.Email, &user.DisplayName, &user.PhotoURL,
-		&user.Provider, &user.NotificationsEnabled, &user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt,
+		&user.Provider, &user.NotificationsEnabled, &user.Roles, &user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt,
 	)
 
 	if errors.Is(err, pgx.ErrNoRows) {
@@ -103,15 +105,15 @@ func (r *UserRepository) GetByExternalID(ctx context.Context, externalID string)
 // GetByEmail retrieves a user by email.
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
 	query := `
-		SELECT id, external_id, email, display_name, photo_url, provider,
-			   notifications_enabled, created_at, updated_at, last_login_at
+		SELECT id, external_id, COALESCE(email, ''), display_name, photo_url, provider,
+			   notifications_enabled, roles, created_at, updated_at, last_login_at
 		FROM users
 		WHERE email = $1 AND deleted_at IS NULL`
 
 	var user model.User
 	err := r.pool.QueryRow(ctx, query, email).Scan(
 		&user.ID, &user.ExternalID, &user.Email, &user.DisplayName, &user.PhotoURL,
-		&user.Provider, &user.NotificationsEnabled, &user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt,
+		&user.Provider, &user.NotificationsEnabled, &user.Roles, &user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt,
 	)
 
 	if errors.Is(err, pgx.ErrNoRows) {
@@ -148,7 +150,7 @@ func (r *UserRepository) Update(ctx context.Context, id uuid.UUID, input model.U
 
 	query := `
 		UPDATE users SET
-			email = $2, display_name = $3, photo_url = $4, notifications_enabled = $5,
+			email = NULLIF($2, ''), display_name = $3, photo_url = $4, notifications_enabled = $5,
 			updated_at = NOW()
 		WHERE id = $1 AND deleted_at IS NULL
 		RETURNING updated_at`
@@ -160,6 +162,9 @@ func (r *UserRepository) Update(ctx context.Context, id uuid.UUID, input model.U
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, ErrNotFound
 	}
+	if uniqueViolation(err) != "" {
+		return nil, ErrConflict
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to update user: %w", err)
 	}
@@ -183,6 +188,62 @@ func (r *UserRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+// DeleteAccount deletes a user at their request. The row is kept, stripped
+// of personal data, so that signing in again with the same external ID
+// returns ErrUserDeleted instead of creating a new account. The user's push
+// tokens, notification preferences and inbox are removed, their chat tokens
+// revoked, and they are detached from the chases they created.
+func (r *UserRepository) DeleteAccount(ctx context.Context, id uuid.UUID) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	result, err := tx.Exec(ctx, `
+		UPDATE users SET
+			email = NULL, display_name = NULL, photo_url = NULL,
+			notifications_enabled = false, roles = ARRAY['user']::TEXT[], chat_role = 'viewer',
+			deleted_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete user account: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	for _, query := range []string{
+		`DELETE FROM push_tokens WHERE user_id = $1`,
+		`DELETE FROM notification_preferences WHERE user_id = $1`,
+		`DELETE FROM notification_recipients WHERE user_id = $1`,
+		`UPDATE chat_tokens SET revoked_at = NOW(), revoked_reason = 'account_deleted'
+			WHERE user_id = $1 AND revoked_at IS NULL`,
+		`UPDATE chases SET created_by = NULL WHERE created_by = $1`,
+	} {
+		if _, err := tx.Exec(ctx, query, id); err != nil {
+			return fmt.Errorf("failed to delete user account data: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit account deletion: %w", err)
+	}
+	return nil
+}
+
+// Exists reports whether a user exists and has not been deleted.
+func (r *UserRepository) Exists(ctx context.Context, id uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.pool.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM users WHERE id = $1 AND deleted_at IS NULL)`, id,
+	).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check user: %w", err)
+	}
+	return exists, nil
+}
+
 // UpdateLastLogin updates the last login timestamp.
 func (r *UserRepository) UpdateLastLogin(ctx context.Context, id uuid.UUID) error {
 	query := `UPDATE users SET last_login_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
@@ -190,7 +251,67 @@ func (r *UserRepository) UpdateLastLogin(ctx context.Context, id uuid.UUID) erro
 	return err
 }
 
-// FindOrCreate finds a user by external ID or creates a new one.
+// GetRoles returns the roles granted to a user.
+func (r *UserRepository) GetRoles(ctx context.Context, id uuid.UUID) ([]model.Role, error) {
+	query := `SELECT roles FROM users WHERE id = $1 AND deleted_at IS NULL`
+
+	var roles []model.Role
+	err := r.pool.QueryRow(ctx, query, id).Scan(&roles)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user roles: %w", err)
+	}
+
+	return roles, nil
+}
+
+// GrantRole adds a role to a user and returns the resulting roles.
+func (r *UserRepository) GrantRole(ctx context.Context, id uuid.UUID, role model.Role) ([]model.Role, error) {
+	query := `
+		UPDATE users SET
+			roles = CASE WHEN $2 = ANY(roles) THEN roles ELSE array_append(roles, $2) END,
+			updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING roles`
+
+	var roles []model.Role
+	err := r.pool.QueryRow(ctx, query, id, string(role)).Scan(&roles)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to grant role: %w", err)
+	}
+
+	return roles, nil
+}
+
+// RevokeRole removes a role from a user and returns the resulting roles.
+func (r *UserRepository) RevokeRole(ctx context.Context, id uuid.UUID, role model.Role) ([]model.Role, error) {
+	query := `
+		UPDATE users SET
+			roles = array_remove(roles, $2),
+			updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING roles`
+
+	var roles []model.Role
+	err := r.pool.QueryRow(ctx, query, id, string(role)).Scan(&roles)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke role: %w", err)
+	}
+
+	return roles, nil
+}
+
+// FindOrCreate finds a user by external ID or creates a new one. When
+// another account already has the email, the user is created without one.
+// A deleted account's external ID returns ErrUserDeleted.
 func (r *UserRepository) FindOrCreate(ctx context.Context, input model.CreateUserInput) (*model.User, bool, error) {
 	// Try to find existing user
 	user, err := r.GetByExternalID(ctx, input.ExternalID)
@@ -206,9 +327,35 @@ func (r *UserRepository) FindOrCreate(ctx context.Context, input model.CreateUse
 
 	// Create new user
 	user, err = r.Create(ctx, input)
+	if uniqueViolation(err) == "users_email_key" {
+		input.Email = ""
+		user, err = r.Create(ctx, input)
+	}
 	if err != nil {
-		return nil, false, err
+		if uniqueViolation(err) != "users_external_id_key" {
+			return nil, false, err
+		}
+		// A concurrent first login may have created the user; otherwise the
+		// external ID belongs to a deleted account.
+		existing, getErr := r.GetByExternalID(ctx, input.ExternalID)
+		if errors.Is(getErr, ErrNotFound) {
+			return nil, false, ErrUserDeleted
+		}
+		if getErr != nil {
+			return nil, false, getErr
+		}
+		return existing, false, nil
 	}
 
 	return user, true, nil
 }
+
+// uniqueViolation returns the constraint a PostgreSQL unique_violation
+// violated, or "" for other errors.
+func uniqueViolation(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return pgErr.ConstraintName
+	}
+	return ""
+}
@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"chaseapp.tv/api/internal/model"
+)
+
+// WatchlistRepository handles aircraft watchlist data access.
+type WatchlistRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewWatchlistRepository creates a new WatchlistRepository.
+func NewWatchlistRepository(pool *pgxpool.Pool) *WatchlistRepository {
+	return &WatchlistRepository{pool: pool}
+}
+
+const watchlistColumns = `id, COALESCE(icao, ''), COALESCE(registration, ''), COALESCE(group_name, ''),
+	category, COALESCE(image_url, ''), COALESCE(operator, ''), created_at, updated_at`
+
+// tagAircraftQuery copies an entry onto the aircraft it matches, so the
+// aircraft list reflects a new or edited entry before the next report.
+const tagAircraftQuery = `
+	UPDATE aircraft a SET
+		category = w.category,
+		operator = COALESCE(w.operator, a.operator),
+		registration = COALESCE(a.registration, w.registration),
+		metadata = COALESCE(a.metadata, '{}'::jsonb) || jsonb_strip_nulls(jsonb_build_object(
+			'watchlist_id', w.id,
+			'watchlist_group', w.group_name,
+			'image_url', w.image_url
+		)),
+		updated_at = NOW()
+	FROM aircraft_watchlist w
+	WHERE w.id = $1
+		AND (a.icao = w.icao OR (w.registration IS NOT NULL AND
+			(UPPER(a.registration) = w.registration OR UPPER(TRIM(a.callsign)) = w.registration)))`
+
+// untagAircraftQuery removes what tagAircraftQuery set for an entry.
+const untagAircraftQuery = `
+	UPDATE aircraft SET
+		category = NULL,
+		metadata = metadata - 'watchlist_id' - 'watchlist_group' - 'image_url',
+		updated_at = NOW()
+	WHERE metadata->>'watchlist_id' = $1`
+
+// Create adds a watchlist entry and tags matching aircraft.
+func (r *WatchlistRepository) Create(ctx context.Context, input model.CreateWatchlistEntryInput) (*model.WatchlistEntry, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO aircraft_watchlist (id, icao, registration, group_name, category, image_url, operator)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), NULLIF($4, ''), $5, NULLIF($6, ''), NULLIF($7, ''))
+		RETURNING ` + watchlistColumns
+
+	entry, err := scanWatchlistEntry(tx.QueryRow(ctx, query,
+		uuid.New(), input.ICAO, input.Registration, input.Group, input.Category, input.ImageURL, input.Operator,
+	))
+	if uniqueViolation(err) != "" {
+		return nil, ErrConflict
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create watchlist entry: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, tagAircraftQuery, entry.ID); err != nil {
+		return nil, fmt.Errorf("failed to tag aircraft: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit watchlist entry: %w", err)
+	}
+	return entry, nil
+}
+
+// GetByID retrieves a watchlist entry by ID.
+func (r *WatchlistRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.WatchlistEntry, error) {
+	query := `SELECT ` + watchlistColumns + ` FROM aircraft_watchlist WHERE id = $1`
+
+	entry, err := scanWatchlistEntry(r.pool.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get watchlist entry: %w", err)
+	}
+	return entry, nil
+}
+
+// List returns watchlist entries ordered by group and tail number.
+func (r *WatchlistRepository) List(ctx context.Context, opts model.WatchlistListOptions) ([]model.WatchlistEntry, error) {
+	query := `SELECT ` + watchlistColumns + ` FROM aircraft_watchlist
+		WHERE ($1 = '' OR category = $1) AND ($2 = '' OR group_name = $2)
+		ORDER BY group_name NULLS LAST, registration NULLS LAST, icao`
+
+	rows, err := r.pool.Query(ctx, query, string(opts.Category), opts.Group)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list watchlist: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []model.WatchlistEntry{}
+	for rows.Next() {
+		entry, err := scanWatchlistEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan watchlist entry: %w", err)
+		}
+		entries = append(entries, *entry)
+	}
+	return entries, rows.Err()
+}
+
+// Update modifies a watchlist entry and re-tags aircraft: those matched
+// before the change are untagged, then those matching now are tagged.
+func (r *WatchlistRepository) Update(ctx context.Context, id uuid.UUID, input model.UpdateWatchlistEntryInput) (*model.WatchlistEntry, error) {
+	entry, err := r.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.ICAO != nil {
+		entry.ICAO = *input.ICAO
+	}
+	if input.Registration != nil {
+		entry.Registration = *input.Registration
+	}
+	if input.Group != nil {
+		entry.Group = *input.Group
+	}
+	if input.Category != nil {
+		entry.Category = *input.Category
+	}
+	if input.ImageURL != nil {
+		entry.ImageURL = *input.ImageURL
+	}
+	if input.Operator != nil {
+		entry.Operator = *input.Operator
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE aircraft_watchlist SET
+			icao = NULLIF($2, ''), registration = NULLIF($3, ''), group_name = NULLIF($4, ''),
+			category = $5, image_url = NULLIF($6, ''), operator = NULLIF($7, '')
+		WHERE id = $1
+		RETURNING ` + watchlistColumns
+
+	entry, err = scanWatchlistEntry(tx.QueryRow(ctx, query,
+		id, entry.ICAO, entry.Registration, entry.Group, entry.Category, entry.ImageURL, entry.Operator,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if uniqueViolation(err) != "" {
+		return nil, ErrConflict
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update watchlist entry: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, untagAircraftQuery, id.String()); err != nil {
+		return nil, fmt.Errorf("failed to untag aircraft: %w", err)
+	}
+	if _, err := tx.Exec(ctx, tagAircraftQuery, id); err != nil {
+		return nil, fmt.Errorf("failed to tag aircraft: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit watchlist entry: %w", err)
+	}
+	return entry, nil
+}
+
+// Delete removes a watchlist entry and untags the aircraft it matched.
+func (r *WatchlistRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `DELETE FROM aircraft_watchlist WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete watchlist entry: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	if _, err := tx.Exec(ctx, untagAircraftQuery, id.String()); err != nil {
+		return fmt.Errorf("failed to untag aircraft: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit watchlist delete: %w", err)
+	}
+	return nil
+}
+
+func scanWatchlistEntry(row pgx.Row) (*model.WatchlistEntry, error) {
+	var entry model.WatchlistEntry
+	err := row.Scan(
+		&entry.ID, &entry.ICAO, &entry.Registration, &entry.Group,
+		&entry.Category, &entry.ImageURL, &entry.Operator, &entry.CreatedAt, &entry.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
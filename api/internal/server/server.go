@@ -12,12 +12,17 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"chaseapp.tv/api/internal/auth"
+	"chaseapp.tv/api/internal/chat"
 	"chaseapp.tv/api/internal/config"
+	"chaseapp.tv/api/internal/database"
 	"chaseapp.tv/api/internal/external"
+	"chaseapp.tv/api/internal/gateway"
 	"chaseapp.tv/api/internal/handler"
+	"chaseapp.tv/api/internal/ingest"
 	"chaseapp.tv/api/internal/middleware"
 	"chaseapp.tv/api/internal/model"
 	"chaseapp.tv/api/internal/observability"
+	"chaseapp.tv/api/internal/push"
 	"chaseapp.tv/api/internal/realtime"
 	"chaseapp.tv/api/internal/repository"
 	"chaseapp.tv/api/internal/search"
@@ -32,17 +37,33 @@ type Server struct {
 	router *mux.Router
 	http   *http.Server
 	pool   *pgxpool.Pool
+	users  *repository.UserRepository
+	keys   *repository.APIKeyRepository
+
+	// authenticate resolves the request identity per AUTH_MODE.
+	authenticate func(http.Handler) http.Handler
 
 	// Handlers
-	chaseHandler    *handler.ChaseHandler
-	aircraftHandler *handler.AircraftHandler
-	pushHandler     *handler.PushHandler
-	externalHandler *handler.ExternalHandler
-	streamHandler   *handler.StreamHandler
-	geoHandler      *handler.GeoHandler
-	authHandler     *handler.AuthHandler
-	webhookHandler  *handler.WebhookHandler
-	searchHandler   *handler.SearchHandler
+	chaseHandler     *handler.ChaseHandler
+	aircraftHandler  *handler.AircraftHandler
+	pushHandler      *handler.PushHandler
+	prefsHandler     *handler.NotificationPreferencesHandler
+	notifyHandler    *handler.NotificationHandler
+	broadcastHandler *handler.BroadcastHandler
+	roleHandler      *handler.RoleHandler
+	userHandler      *handler.UserHandler
+	apiKeyHandler    *handler.APIKeyHandler
+	externalHandler  *handler.ExternalHandler
+	streamHandler    *handler.StreamHandler
+	geoHandler       *handler.GeoHandler
+	authHandler      *handler.AuthHandler
+	chatHandler      *handler.ChatHandler
+	eventsHandler    *handler.EventsHandler
+	aircraftWS       *handler.AircraftStreamHandler
+	ingestHandler    *handler.AircraftIngestHandler
+	watchlistHandler *handler.WatchlistHandler
+	webhookHandler   *handler.WebhookHandler
+	searchHandler    *handler.SearchHandler
 
 	// Realtime
 	publisher  *realtime.Publisher
@@ -58,6 +79,14 @@ type Server struct {
 	statsWorker    *worker.StatsWorker
 	weatherWorker  *worker.WeatherWorker
 	mediaWorker    *worker.MediaWorker
+	notifyWorker   *worker.NotificationWorker
+	chatWorker     *worker.ChatChannelWorker
+	chatTokens     *chat.Tokens
+	chaseBroker    *realtime.ChaseBroker
+	sbsListener    *ingest.SBSListener
+	watchlist      *ingest.Watchlist
+	bofWorker      *worker.BoFWorker
+	squawkWorker   *worker.EmergencyWorker
 
 	// Observability
 	traceShutdown func(context.Context) error
@@ -74,7 +103,13 @@ func New(cfg *config.Config, logger *slog.Logger, pool *pgxpool.Pool) (*Server,
 	chaseRepo := repository.NewChaseRepository(pool)
 	userRepo := repository.NewUserRepository(pool)
 	aircraftRepo := repository.NewAircraftRepository(pool)
+	watchlistRepo := repository.NewWatchlistRepository(pool)
 	pushTokenRepo := repository.NewPushTokenRepository(pool)
+	notificationPrefsRepo := repository.NewNotificationPreferencesRepository(pool)
+	notificationRepo := repository.NewNotificationRepository(pool)
+	apiKeyRepo := repository.NewAPIKeyRepository(pool)
+	chatRepo := repository.NewChatRepository(pool)
+	chatPolicy := chat.NewPolicy(chatRepo, userRepo, cfg.Auth.AdminUserIDs)
 
 	js, err := realtime.NewJetStream(cfg.NATS, logger)
 	if err != nil {
@@ -87,7 +122,7 @@ func New(cfg *config.Config, logger *slog.Logger, pool *pgxpool.Pool) (*Server,
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
 	}
-	publisher.js = js
+	publisher.UseJetStream(js)
 	subscriber, err := realtime.NewSubscriber(cfg.NATS, logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to NATS subscriber: %w", err)
@@ -95,14 +130,43 @@ func New(cfg *config.Config, logger *slog.Logger, pool *pgxpool.Pool) (*Server,
 
 	externalClient := external.NewClient(cfg.External, logger)
 	streamExtractor := scraper.NewExtractor()
+	var verifier *auth.Verifier
+	if cfg.Auth.Mode == middleware.AuthModeJWT || cfg.Auth.Mode == middleware.AuthModeBoth {
+		verifier, err = auth.NewVerifier(cfg.Auth)
+		if err != nil {
+			return nil, fmt.Errorf("jwt verifier init: %w", err)
+		}
+	}
+	authenticate, err := middleware.Authenticate(cfg.Auth.Mode, verifier, logger)
+	if err != nil {
+		return nil, fmt.Errorf("auth middleware init: %w", err)
+	}
 	chatSigner, err := auth.NewChatTokenSigner(cfg.Chat)
 	if err != nil {
 		return nil, fmt.Errorf("chat token signer init: %w", err)
 	}
+	chatTokens := chat.NewTokens(chatSigner, chatRepo, cfg.Chat, logger)
+	chaseBroker := realtime.NewChaseBroker(js, logger)
+	aircraftHub := gateway.NewAircraftHub(gateway.AircraftConfig{
+		Throttle:   cfg.Server.WSThrottle,
+		StaleAfter: cfg.Server.WSStaleAfter,
+	}, aircraftRepo, logger)
+	watchlist := ingest.NewWatchlist(watchlistRepo, cfg.ADSB.WatchlistRefresh, logger)
+	emergencies := ingest.NewEmergencies(aircraftRepo)
+	ingester := ingest.NewIngester(aircraftRepo, publisher, watchlist, emergencies, logger)
+	var sbsListener *ingest.SBSListener
+	if cfg.ADSB.SBSListenAddr != "" {
+		sbsListener = ingest.NewSBSListener(ingest.SBSConfig{
+			Addr:          cfg.ADSB.SBSListenAddr,
+			AllowedCIDRs:  cfg.ADSB.SBSAllowedCIDRs,
+			FlushInterval: cfg.ADSB.SBSFlushInterval,
+		}, ingester, logger)
+	}
 	webhookHandler, err := handler.NewWebhookHandler(cfg.External, logger)
 	if err != nil {
 		return nil, fmt.Errorf("webhook handler init: %w", err)
 	}
+	dispatcher := push.NewDispatcher(cfg.Push, pushTokenRepo, notificationPrefsRepo, notificationRepo, logger)
 	typesenseClient, err := search.NewClient(cfg.Search)
 	if err != nil {
 		return nil, fmt.Errorf("typesense client init: %w", err)
@@ -116,30 +180,57 @@ func New(cfg *config.Config, logger *slog.Logger, pool *pgxpool.Pool) (*Server,
 		logger:    logger,
 		router:    mux.NewRouter(),
 		pool:      pool,
+		users:     userRepo,
+		keys:      apiKeyRepo,
 		publisher: publisher,
 		js:        js,
 
+		authenticate:  authenticate,
+		traceShutdown: traceShutdown,
+
 		// Initialize handlers with their dependencies
-		chaseHandler:    handler.NewChaseHandler(chaseRepo, publisher, logger),
-		aircraftHandler: handler.NewAircraftHandler(aircraftRepo, logger),
-		pushHandler:     handler.NewPushHandler(pushTokenRepo, userRepo, cfg.Push, logger),
-		externalHandler: handler.NewExternalHandler(externalClient, logger),
-		streamHandler:   handler.NewStreamHandler(chaseRepo, streamExtractor, publisher, logger),
-		geoHandler:      handler.NewGeoHandler(logger),
-		authHandler:     handler.NewAuthHandler(chatSigner, logger),
-		webhookHandler:  webhookHandler,
-		searchHandler:   handler.NewSearchHandler(typesenseClient, logger),
-		subscriber:      subscriber,
+		chaseHandler:     handler.NewChaseHandler(chaseRepo, publisher, logger),
+		aircraftHandler:  handler.NewAircraftHandler(aircraftRepo, logger),
+		pushHandler:      handler.NewPushHandler(pushTokenRepo, userRepo, cfg.Push, logger),
+		prefsHandler:     handler.NewNotificationPreferencesHandler(notificationPrefsRepo, logger),
+		notifyHandler:    handler.NewNotificationHandler(notificationRepo, logger),
+		broadcastHandler: handler.NewBroadcastHandler(dispatcher, logger),
+		roleHandler:      handler.NewRoleHandler(userRepo, logger),
+		userHandler:      handler.NewUserHandler(userRepo, pushTokenRepo, publisher, logger),
+		apiKeyHandler:    handler.NewAPIKeyHandler(apiKeyRepo, logger),
+		externalHandler:  handler.NewExternalHandler(externalClient, logger),
+		streamHandler:    handler.NewStreamHandler(chaseRepo, streamExtractor, publisher, logger),
+		geoHandler:       handler.NewGeoHandler(logger),
+		authHandler:      handler.NewAuthHandler(chatTokens, chatRepo, chatPolicy, logger),
+		chatHandler:      handler.NewChatHandler(chatRepo, chatPolicy, chatTokens, logger),
+		eventsHandler:    handler.NewEventsHandler(chaseBroker, cfg.Server.SSEHeartbeat, logger),
+		aircraftWS:       handler.NewAircraftStreamHandler(aircraftHub, logger),
+		ingestHandler:    handler.NewAircraftIngestHandler(ingester, logger),
+		watchlistHandler: handler.NewWatchlistHandler(watchlistRepo, watchlist, logger),
+		webhookHandler:   webhookHandler,
+		searchHandler:    handler.NewSearchHandler(typesenseClient, logger),
+		subscriber:       subscriber,
 
 		// Workers
 		aircraftWorker: worker.NewAircraftSyncWorker(aircraftRepo, logger),
 		workerManager:  worker.NewManager(logger),
 		indexerWorker:  worker.NewIndexerWorker(subscriber, typesenseClient, logger),
 		userWorker:     worker.NewUserEventWorker(subscriber, webhookHandler.DiscordClient(), logger),
-		airWorker:      worker.NewAircraftEventWorker(subscriber, logger),
+		airWorker:      worker.NewAircraftEventWorker(subscriber, aircraftHub, logger),
 		statsWorker:    worker.NewStatsWorker(chaseRepo, logger),
 		weatherWorker:  worker.NewWeatherWorker(externalClient, logger),
 		mediaWorker:    worker.NewMediaWorker(chaseRepo, streamExtractor, logger),
+		notifyWorker:   worker.NewNotificationWorker(js, dispatcher, logger),
+		chatWorker:     worker.NewChatChannelWorker(js, chatRepo, logger),
+		squawkWorker:   worker.NewEmergencyWorker(js, aircraftRepo, chaseRepo, publisher, webhookHandler.DiscordClient(), cfg.ADSB.EmergencyCreateChase, cfg.ADSB.EmergencyResetAfter, logger),
+		chatTokens:     chatTokens,
+		chaseBroker:    chaseBroker,
+		sbsListener:    sbsListener,
+		watchlist:      watchlist,
+	}
+
+	if cfg.BoF.Enabled {
+		s.bofWorker = worker.NewBoFWorker(database.NewAdvisoryLock(pool, "bof-worker"), aircraftRepo, chaseRepo, publisher, webhookHandler.DiscordClient(), cfg.BoF, logger)
 	}
 
 	// Subscribe to user registration events
@@ -158,6 +249,9 @@ func New(cfg *config.Config, logger *slog.Logger, pool *pgxpool.Pool) (*Server,
 		ReadTimeout:  cfg.Server.ReadTimeout,
 		WriteTimeout: cfg.Server.WriteTimeout,
 	}
+	// End event streams so Shutdown does not wait on them.
+	s.http.RegisterOnShutdown(chaseBroker.Close)
+	s.http.RegisterOnShutdown(aircraftHub.Close)
 
 	return s, nil
 }
@@ -171,7 +265,10 @@ func (s *Server) setupMiddleware() {
 	s.router.Use(middleware.CORS([]string{"*"})) // TODO: Configure allowed origins
 	s.router.Use(middleware.Metrics)
 	s.router.Use(middleware.Logging(s.logger))
-	s.router.Use(middleware.Auth)
+	s.router.Use(s.authenticate)
+	s.router.Use(middleware.Provision(s.userHandler, s.logger))
+	s.router.Use(middleware.APIKeyAuth(s.keys, s.logger))
+	s.router.Use(middleware.Roles(s.users, s.cfg.Auth.AdminUserIDs, s.logger))
 }
 
 // setupRoutes configures all HTTP routes.
@@ -184,17 +281,34 @@ func (s *Server) setupRoutes() {
 	// API v1 routes
 	api := s.router.PathPrefix("/api/v1").Subrouter()
 
+	// Mutating chase and outbound webhook routes are limited to staff;
+	// chase writes are also open to API keys with the chases:write scope.
+	staff := middleware.RequireRole(model.RoleAdmin, model.RoleModerator)
+	chaseWriters := middleware.RequireAccess(model.ScopeChasesWrite, model.RoleAdmin, model.RoleModerator)
+	aircraftFeeders := middleware.RequireAccess(model.ScopeAircraftIngest, model.RoleAdmin)
+
 	// Chases
 	api.HandleFunc("/chases", s.chaseHandler.List).Methods(http.MethodGet)
-	api.HandleFunc("/chases", s.chaseHandler.Create).Methods(http.MethodPost)
+	api.Handle("/chases", chaseWriters(http.HandlerFunc(s.chaseHandler.Create))).Methods(http.MethodPost)
 	api.HandleFunc("/chases/bundle", s.chaseHandler.GetBundle).Methods(http.MethodGet)
+	api.HandleFunc("/chases/events", s.eventsHandler.Stream).Methods(http.MethodGet)
 	api.HandleFunc("/chases/{id}", s.chaseHandler.Get).Methods(http.MethodGet)
-	api.HandleFunc("/chases/{id}", s.chaseHandler.Update).Methods(http.MethodPut)
-	api.HandleFunc("/chases/{id}", s.chaseHandler.Delete).Methods(http.MethodDelete)
+	api.Handle("/chases/{id}", chaseWriters(http.HandlerFunc(s.chaseHandler.Update))).Methods(http.MethodPut)
+	api.Handle("/chases/{id}", chaseWriters(http.HandlerFunc(s.chaseHandler.Delete))).Methods(http.MethodDelete)
 
 	// Aircraft
 	api.HandleFunc("/aircraft", s.aircraftHandler.List).Methods(http.MethodGet)
 	api.HandleFunc("/aircraft/cluster", s.aircraftHandler.Cluster).Methods(http.MethodPost)
+	api.HandleFunc("/aircraft/clusters", s.aircraftHandler.ListClusters).Methods(http.MethodGet)
+	api.HandleFunc("/aircraft/clusters/{id}", s.aircraftHandler.GetCluster).Methods(http.MethodGet)
+	api.HandleFunc("/aircraft/{icao}/track", s.aircraftHandler.Track).Methods(http.MethodGet)
+	api.HandleFunc("/aircraft/stream", s.aircraftWS.Stream).Methods(http.MethodGet)
+	api.Handle("/aircraft/ingest", aircraftFeeders(http.HandlerFunc(s.ingestHandler.Ingest))).Methods(http.MethodPost)
+	api.HandleFunc("/aircraft/watchlist", s.watchlistHandler.List).Methods(http.MethodGet)
+	api.Handle("/aircraft/watchlist", staff(http.HandlerFunc(s.watchlistHandler.Create))).Methods(http.MethodPost)
+	api.HandleFunc("/aircraft/watchlist/{id}", s.watchlistHandler.Get).Methods(http.MethodGet)
+	api.Handle("/aircraft/watchlist/{id}", staff(http.HandlerFunc(s.watchlistHandler.Update))).Methods(http.MethodPatch)
+	api.Handle("/aircraft/watchlist/{id}", staff(http.HandlerFunc(s.watchlistHandler.Delete))).Methods(http.MethodDelete)
 
 	// External data
 	api.HandleFunc("/quakes", s.externalHandler.GetQuakes).Methods(http.MethodGet)
@@ -203,21 +317,74 @@ func (s *Server) setupRoutes() {
 	api.HandleFunc("/weather/alerts", s.externalHandler.GetWeatherAlerts).Methods(http.MethodGet)
 
 	// Streams
-	api.HandleFunc("/streams/extract", s.streamHandler.ExtractStreamURLs).Methods(http.MethodPost)
+	api.Handle("/streams/extract", chaseWriters(http.HandlerFunc(s.streamHandler.ExtractStreamURLs))).Methods(http.MethodPost)
 
 	// Geo utilities
 	api.HandleFunc("/geo/bounding-rect", s.geoHandler.GetBoundingRectangle).Methods(http.MethodPost)
 
 	// Auth
+	chatTokenReaders := middleware.RequireAccess(model.ScopeChatTokens, model.RoleAdmin)
 	api.HandleFunc("/auth/chat-token", s.authHandler.GetChatToken).Methods(http.MethodPost)
+	api.HandleFunc("/auth/chat-token/refresh", s.authHandler.RefreshChatToken).Methods(http.MethodPost)
+	api.HandleFunc("/auth/chat-token/revoke", s.authHandler.RevokeChatToken).Methods(http.MethodPost)
+	api.Handle("/auth/chat-token/verify", chatTokenReaders(http.HandlerFunc(s.authHandler.VerifyChatToken))).Methods(http.MethodPost)
+	api.Handle("/auth/chat-token/revocations", chatTokenReaders(http.HandlerFunc(s.authHandler.ChatTokenRevocations))).Methods(http.MethodGet)
+
+	// Chat moderation
+	chatMods := api.PathPrefix("/chat").Subrouter()
+	chatMods.Use(middleware.RequireAuth)
+	chatMods.HandleFunc("/channels/{id}/moderation", s.chatHandler.ListModeration).Methods(http.MethodGet)
+	chatMods.HandleFunc("/channels/{id}/moderation", s.chatHandler.Moderate).Methods(http.MethodPost)
+	chatMods.HandleFunc("/moderation/{id}", s.chatHandler.LiftModeration).Methods(http.MethodDelete)
 
 	// Push notifications
 	api.HandleFunc("/push/subscribe", s.pushHandler.Subscribe).Methods(http.MethodPost)
 	api.HandleFunc("/push/unsubscribe", s.pushHandler.Unsubscribe).Methods(http.MethodPost)
 	api.HandleFunc("/push/safari-package", s.pushHandler.GetSafariPushPackage).Methods(http.MethodGet)
+	api.Handle("/push/safari-user-info", middleware.RequireAuth(http.HandlerFunc(s.pushHandler.GetSafariUserInfo))).Methods(http.MethodGet)
+	api.HandleFunc("/push/vapid-public-key", s.pushHandler.GetVAPIDPublicKey).Methods(http.MethodGet)
+
+	// Safari push web service (webServiceURL in the push package)
+	api.HandleFunc("/push/safari/v2/pushPackages/{pushID}", s.pushHandler.SafariPushPackage).Methods(http.MethodPost)
+	api.HandleFunc("/push/safari/v2/devices/{deviceToken}/registrations/{pushID}", s.pushHandler.SafariRegisterDevice).Methods(http.MethodPost)
+	api.HandleFunc("/push/safari/v2/devices/{deviceToken}/registrations/{pushID}", s.pushHandler.SafariUnregisterDevice).Methods(http.MethodDelete)
+	api.HandleFunc("/push/safari/v2/log", s.pushHandler.SafariLog).Methods(http.MethodPost)
+
+	// Notification history
+	api.HandleFunc("/notifications", s.notifyHandler.List).Methods(http.MethodGet)
+	api.HandleFunc("/notifications/{id}", s.notifyHandler.Get).Methods(http.MethodGet)
+
+	// Current user
+	me := api.PathPrefix("/me").Subrouter()
+	me.Use(middleware.RequireAuth)
+	me.HandleFunc("", s.userHandler.Get).Methods(http.MethodGet)
+	me.HandleFunc("", s.userHandler.Update).Methods(http.MethodPatch)
+	me.HandleFunc("", s.userHandler.Delete).Methods(http.MethodDelete)
+	me.HandleFunc("/devices", s.userHandler.Devices).Methods(http.MethodGet)
+	me.HandleFunc("/notification-preferences", s.prefsHandler.Get).Methods(http.MethodGet)
+	me.HandleFunc("/notification-preferences", s.prefsHandler.Update).Methods(http.MethodPut)
+	me.HandleFunc("/notification-preferences", s.prefsHandler.Delete).Methods(http.MethodDelete)
+	me.HandleFunc("/notifications", s.notifyHandler.Inbox).Methods(http.MethodGet)
+	me.HandleFunc("/notifications/read", s.notifyHandler.MarkAllRead).Methods(http.MethodPost)
+	me.HandleFunc("/notifications/{id}/read", s.notifyHandler.MarkRead).Methods(http.MethodPost)
+	me.HandleFunc("/notifications/{id}/read", s.notifyHandler.MarkUnread).Methods(http.MethodDelete)
+
+	// Admin
+	broadcasters := middleware.RequireAccess(model.ScopePushBroadcast, model.RoleAdmin)
+	api.Handle("/admin/push/broadcast", broadcasters(http.HandlerFunc(s.broadcastHandler.Broadcast))).Methods(http.MethodPost)
+
+	admin := api.PathPrefix("/admin").Subrouter()
+	admin.Use(middleware.RequireRole(model.RoleAdmin))
+	admin.HandleFunc("/api-keys", s.apiKeyHandler.List).Methods(http.MethodGet)
+	admin.HandleFunc("/api-keys", s.apiKeyHandler.Create).Methods(http.MethodPost)
+	admin.HandleFunc("/api-keys/{id}", s.apiKeyHandler.Revoke).Methods(http.MethodDelete)
+	admin.HandleFunc("/users/{id}/roles", s.roleHandler.Get).Methods(http.MethodGet)
+	admin.HandleFunc("/users/{id}/roles/{role}", s.roleHandler.Grant).Methods(http.MethodPut)
+	admin.HandleFunc("/users/{id}/roles/{role}", s.roleHandler.Revoke).Methods(http.MethodDelete)
+	admin.HandleFunc("/users/{id}/chat-role", s.chatHandler.SetRole).Methods(http.MethodPut)
 
 	// Webhooks
-	api.HandleFunc("/webhooks/discord", s.webhookHandler.SendDiscordWebhook).Methods(http.MethodPost)
+	api.Handle("/webhooks/discord", staff(http.HandlerFunc(s.webhookHandler.SendDiscordWebhook))).Methods(http.MethodPost)
 
 	// Search
 	api.HandleFunc("/search", s.searchHandler.Search).Methods(http.MethodGet)
@@ -270,6 +437,55 @@ func (s *Server) Start() error {
 			}
 		})
 	}
+	if s.workerManager != nil && s.watchlist != nil {
+		s.workerManager.Go("aircraft-watchlist", s.watchlist.Run)
+	}
+	if s.workerManager != nil && s.bofWorker != nil {
+		s.logger.Info("starting bof worker")
+		s.workerManager.Go("bof", s.bofWorker.Start)
+	}
+	if s.workerManager != nil && s.squawkWorker != nil {
+		s.logger.Info("starting aircraft emergency worker")
+		s.workerManager.Go("aircraft-emergencies", func(ctx context.Context) {
+			if err := s.squawkWorker.Start(ctx); err != nil {
+				s.logger.Warn("aircraft emergency worker stopped", slog.Any("error", err))
+			}
+		})
+	}
+	if s.workerManager != nil && s.sbsListener != nil {
+		s.logger.Info("starting sbs listener", slog.String("addr", s.cfg.ADSB.SBSListenAddr))
+		s.workerManager.Go("sbs-listener", func(ctx context.Context) {
+			if err := s.sbsListener.Run(ctx); err != nil {
+				s.logger.Warn("sbs listener stopped", slog.Any("error", err))
+			}
+		})
+	}
+	if s.workerManager != nil && s.notifyWorker != nil {
+		s.logger.Info("starting notification worker")
+		s.workerManager.Go("push-notifications", func(ctx context.Context) {
+			if err := s.notifyWorker.Start(ctx); err != nil {
+				s.logger.Warn("notification worker stopped", slog.Any("error", err))
+			}
+		})
+	}
+	if s.workerManager != nil && s.chaseBroker != nil {
+		s.workerManager.Go("chase-events", func(ctx context.Context) {
+			if err := s.chaseBroker.Start(ctx); err != nil {
+				s.logger.Warn("chase event broker stopped", slog.Any("error", err))
+			}
+		})
+	}
+	if s.workerManager != nil && s.chatTokens != nil {
+		s.workerManager.Go("chat-revocations", s.chatTokens.Run)
+	}
+	if s.workerManager != nil && s.chatWorker != nil {
+		s.logger.Info("starting chat channel worker")
+		s.workerManager.Go("chat-channels", func(ctx context.Context) {
+			if err := s.chatWorker.Start(ctx); err != nil {
+				s.logger.Warn("chat channel worker stopped", slog.Any("error", err))
+			}
+		})
+	}
 	if s.workerManager != nil {
 		if s.statsWorker != nil {
 			s.logger.Info("starting stats worker")
@@ -306,6 +522,9 @@ func (s *Server) Shutdown(ctx context.Context) error {
 	if s.subscriber != nil {
 		s.subscriber.Close()
 	}
+	if s.js != nil {
+		s.js.Close()
+	}
 	if s.workerManager != nil {
 		s.workerManager.Stop(ctx)
 	}
@@ -5,31 +5,39 @@ import (
 	"encoding/json"
 	"log/slog"
 
+	"chaseapp.tv/api/internal/gateway"
 	"chaseapp.tv/api/internal/realtime"
 )
 
-// AircraftEventWorker listens for aircraft.updated events.
+// AircraftEventWorker relays aircraft.updated events to WebSocket clients.
 type AircraftEventWorker struct {
 	subscriber *realtime.Subscriber
+	hub        *gateway.AircraftHub
 	logger     *slog.Logger
 }
 
 // NewAircraftEventWorker creates a new worker.
-func NewAircraftEventWorker(sub *realtime.Subscriber, logger *slog.Logger) *AircraftEventWorker {
+func NewAircraftEventWorker(sub *realtime.Subscriber, hub *gateway.AircraftHub, logger *slog.Logger) *AircraftEventWorker {
 	return &AircraftEventWorker{
 		subscriber: sub,
+		hub:        hub,
 		logger:     logger,
 	}
 }
 
-// Start subscribes and logs aircraft updates.
+// Start subscribes and hands aircraft updates to the hub.
 func (w *AircraftEventWorker) Start(ctx context.Context) error {
-	if w.subscriber == nil {
+	if w.subscriber == nil || w.hub == nil {
 		return nil
 	}
 
 	if err := w.subscriber.SubscribeAircraftUpdated(func(payload json.RawMessage) {
-		w.logger.Info("aircraft.updated event received", slog.Int("bytes", len(payload)))
+		var evt realtime.AircraftEvent
+		if err := json.Unmarshal(payload, &evt); err != nil {
+			w.logger.Warn("failed to unmarshal aircraft event", slog.Any("error", err))
+			return
+		}
+		w.hub.Publish(evt.Aircraft)
 	}); err != nil {
 		return err
 	}
@@ -0,0 +1,511 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"chaseapp.tv/api/internal/config"
+	"chaseapp.tv/api/internal/model"
+	"chaseapp.tv/api/internal/realtime"
+	"chaseapp.tv/api/internal/repository"
+	"chaseapp.tv/api/internal/webhook"
+	"chaseapp.tv/api/pkg/dbscan"
+)
+
+// bofLiveWindow is how recently an aircraft must have reported to be
+// clustered.
+const bofLiveWindow = 2 * time.Minute
+
+// Lock elects the one replica that runs a worker, such as a
+// database.AdvisoryLock.
+type Lock interface {
+	TryAcquire(ctx context.Context) (bool, error)
+	Release(ctx context.Context)
+}
+
+// BoFWorker clusters live aircraft, keeping each cluster's ID stable across
+// runs, and, when media and law enforcement aircraft circle the same spot
+// for a while ("Birds of a Feather"), creates a draft chase for moderators.
+// This is how chases were historically spotted before TV cut in. Only the
+// replica holding the lock runs, so clusters are detected and reported once.
+type BoFWorker struct {
+	lock      Lock
+	aircraft  *repository.AircraftRepository
+	chases    *repository.ChaseRepository
+	publisher *realtime.Publisher
+	discord   *webhook.Client
+	cfg       config.BoFConfig
+	clusters  *dbscan.Tracker
+	tracker   *bofTracker
+	logger    *slog.Logger
+}
+
+// NewBoFWorker creates a new BoFWorker. discord may be nil.
+func NewBoFWorker(lock Lock, aircraft *repository.AircraftRepository, chases *repository.ChaseRepository, publisher *realtime.Publisher, discord *webhook.Client, cfg config.BoFConfig, logger *slog.Logger) *BoFWorker {
+	// A cluster missing for two runs has dissolved rather than flickered.
+	gap := 2 * cfg.Interval
+	return &BoFWorker{
+		lock:      lock,
+		aircraft:  aircraft,
+		chases:    chases,
+		publisher: publisher,
+		discord:   discord,
+		cfg:       cfg,
+		clusters: dbscan.NewTracker(dbscan.TrackerOptions{
+			MaxDistanceMeters: float64(cfg.EpsMeters),
+			Expiry:            gap,
+		}),
+		tracker: newBoFTracker(cfg.Dwell, cfg.Cooldown, float64(cfg.EpsMeters), gap),
+		logger:  logger,
+	}
+}
+
+// Start clusters aircraft every interval, while this replica holds the
+// lock, until ctx is cancelled. On taking the lock it continues the clusters
+// stored by the previous holder, so cluster IDs survive restarts and
+// failover.
+func (w *BoFWorker) Start(ctx context.Context) {
+	defer w.lock.Release(context.Background())
+
+	leading := false
+	RunInterval(ctx, w.cfg.Interval, func(ctx context.Context) {
+		held, err := w.lock.TryAcquire(ctx)
+		if err != nil {
+			w.logger.Warn("failed to take bof worker lock", slog.Any("error", err))
+		}
+		if held != leading {
+			leading = held
+			w.logger.Info("bof worker leadership changed", slog.Bool("leading", leading))
+			if leading {
+				// Continue from the clusters the previous holder stored,
+				// not from what this replica tracked before it lost the
+				// lock.
+				w.restoreClusters(ctx, time.Now())
+			}
+		}
+		if !held {
+			return
+		}
+
+		if err := w.run(ctx, time.Now()); err != nil {
+			w.logger.Warn("bof detection failed", slog.Any("error", err))
+		}
+	})
+}
+
+func (w *BoFWorker) run(ctx context.Context, now time.Time) error {
+	aircraft, err := w.liveAircraft(ctx, now.Add(-bofLiveWindow))
+	if err != nil {
+		return err
+	}
+
+	points := make([]dbscan.Point, 0, len(aircraft))
+	for _, a := range aircraft {
+		if a.Latitude == nil || a.Longitude == nil {
+			continue
+		}
+		if a.Altitude != nil && *a.Altitude > w.cfg.MaxAltitude {
+			continue
+		}
+		points = append(points, dbscan.Point{
+			ID:       a.ID.String(),
+			Lat:      *a.Latitude,
+			Lng:      *a.Longitude,
+			Metadata: map[string]any{"point": clusterPoint(a)},
+		})
+	}
+
+	clustered := dbscan.ClusterPoints(points, float64(w.cfg.EpsMeters), w.cfg.MinPoints)
+	tracks, events := w.clusters.Update(now, clustered)
+	clusters := summarizeClusters(clustered)
+	stored, err := w.saveClusters(ctx, now, clusters, tracks)
+	if err != nil {
+		return err
+	}
+
+	assigned := make(map[string]string)
+	for _, c := range clusters {
+		for _, p := range c.Points {
+			assigned[p.ID] = c.ID
+		}
+	}
+	changed := make(map[uuid.UUID]string)
+	for _, a := range aircraft {
+		if clusterID := assigned[a.ID.String()]; clusterID != a.ClusterID {
+			changed[a.ID] = clusterID
+		}
+	}
+	if err := w.aircraft.UpdateClusters(ctx, changed); err != nil {
+		return fmt.Errorf("update aircraft clusters: %w", err)
+	}
+
+	if err := w.clusterEvents(ctx, now, stored, events); err != nil {
+		return err
+	}
+
+	for _, c := range w.tracker.observe(now, clusters) {
+		w.report(ctx, c)
+	}
+	return nil
+}
+
+// restoreClusters resumes tracking the clusters stored by the previous lock
+// holder and ends those too old to continue.
+func (w *BoFWorker) restoreClusters(ctx context.Context, now time.Time) {
+	since := now.Add(-2 * w.cfg.Interval)
+	if _, err := w.aircraft.EndStaleClusters(ctx, since); err != nil {
+		w.logger.Warn("failed to end stale aircraft clusters", slog.Any("error", err))
+	}
+	stored, err := w.aircraft.ListClusters(ctx, since)
+	if err != nil {
+		w.logger.Warn("failed to restore aircraft clusters", slog.Any("error", err))
+		return
+	}
+
+	tracks := make([]dbscan.Track, 0, len(stored))
+	for _, c := range stored {
+		tr := dbscan.Track{
+			ID:          c.ID,
+			CentroidLat: c.CentroidLat,
+			CentroidLng: c.CentroidLng,
+			FirstSeen:   c.FirstSeenAt,
+			LastSeen:    c.LastSeenAt,
+		}
+		for _, id := range c.AircraftIDs {
+			tr.Members = append(tr.Members, id.String())
+		}
+		tracks = append(tracks, tr)
+	}
+	w.clusters.Restore(tracks)
+}
+
+// saveClusters stores this run's clusters under their tracked IDs and
+// returns them by ID.
+func (w *BoFWorker) saveClusters(ctx context.Context, now time.Time, clusters []model.ClusterResult, tracks []dbscan.Track) (map[string]model.AircraftCluster, error) {
+	firstSeen := make(map[string]time.Time, len(tracks))
+	for _, tr := range tracks {
+		firstSeen[tr.ID] = tr.FirstSeen
+	}
+
+	stored := make([]model.AircraftCluster, 0, len(clusters))
+	byID := make(map[string]model.AircraftCluster, len(clusters))
+	for _, c := range clusters {
+		ac := aircraftCluster(c, firstSeen[c.ID], now)
+		stored = append(stored, ac)
+		byID[ac.ID] = ac
+	}
+	if err := w.aircraft.SaveClusters(ctx, stored); err != nil {
+		return nil, fmt.Errorf("save clusters: %w", err)
+	}
+	return byID, nil
+}
+
+// clusterEvents ends merged and dissolved clusters and publishes
+// aircraft.cluster for each change.
+func (w *BoFWorker) clusterEvents(ctx context.Context, now time.Time, current map[string]model.AircraftCluster, events []dbscan.TrackEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	var ended []string
+	for _, e := range events {
+		switch e.Type {
+		case dbscan.TrackMerged:
+			ended = append(ended, e.Related...)
+		case dbscan.TrackDissolved:
+			ended = append(ended, e.ID)
+		}
+	}
+	if err := w.aircraft.EndClusters(ctx, ended, now); err != nil {
+		return fmt.Errorf("end clusters: %w", err)
+	}
+
+	for _, e := range events {
+		evt := realtime.ClusterEvent{Event: string(e.Type), ClusterID: e.ID, Related: e.Related}
+		if c, ok := current[e.ID]; ok {
+			evt.Cluster = &c
+		}
+		if err := w.publisher.PublishClusterEvent(evt); err != nil {
+			w.logger.Warn("failed to publish cluster event", slog.Any("error", err))
+		}
+	}
+	return nil
+}
+
+// liveAircraft pages through airborne aircraft seen since the given time.
+func (w *BoFWorker) liveAircraft(ctx context.Context, since time.Time) ([]model.Aircraft, error) {
+	onGround := false
+	opts := model.AircraftListOptions{Page: 1, Limit: 100, OnGround: &onGround, SeenAfter: &since}
+
+	var aircraft []model.Aircraft
+	for {
+		result, err := w.aircraft.List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		aircraft = append(aircraft, result.Aircraft...)
+		if opts.Page >= result.TotalPages {
+			return aircraft, nil
+		}
+		opts.Page++
+	}
+}
+
+// report creates the draft chase for a detected cluster and alerts
+// moderators.
+func (w *BoFWorker) report(ctx context.Context, c model.ClusterResult) {
+	icaos := make([]string, 0, len(c.Points))
+	for _, p := range c.Points {
+		icaos = append(icaos, p.ICAO)
+	}
+
+	chase, err := w.chases.Create(ctx, model.CreateChaseInput{
+		Title:       "Aircraft circling near " + formatLatLng(c.CentroidLat, c.CentroidLng),
+		Description: "Detected from " + strings.Join(aircraftLabels(c.Points), ", "),
+		ChaseType:   model.ChaseTypeChase,
+		Location:    &model.Location{Lat: c.CentroidLat, Lng: c.CentroidLng},
+		Draft:       true,
+		Source:      "bof",
+		Metadata: map[string]interface{}{
+			"bof": map[string]interface{}{
+				"aircraft":   icaos,
+				"cluster_id": c.ID,
+			},
+		},
+	}, nil)
+	if err != nil {
+		w.logger.Error("failed to create bof draft chase", slog.Any("error", err))
+		return
+	}
+
+	w.logger.Info("bof detected",
+		slog.String("chase_id", chase.ID.String()),
+		slog.Float64("lat", c.CentroidLat),
+		slog.Float64("lng", c.CentroidLng),
+		slog.Any("aircraft", icaos),
+	)
+
+	if err := w.publisher.PublishBoF(chase, c); err != nil {
+		w.logger.Warn("failed to publish bof event", slog.Any("error", err))
+	}
+	if w.discord != nil {
+		if err := w.discord.Send(ctx, bofMessage(chase, c)); err != nil {
+			w.logger.Warn("failed to post bof to discord", slog.Any("error", err))
+		}
+	}
+}
+
+func bofMessage(chase *model.Chase, c model.ClusterResult) webhook.Message {
+	return webhook.Message{
+		Content: "Possible chase detected, draft awaiting review",
+		Embeds: []webhook.Embed{{
+			Title:       chase.Title,
+			Description: chase.Description,
+			URL:         fmt.Sprintf("https://www.google.com/maps?q=%.5f,%.5f", c.CentroidLat, c.CentroidLng),
+			Color:       0xF5A623,
+			Fields: []webhook.EmbedField{
+				{Name: "Draft chase", Value: chase.ID.String()},
+				{Name: "Aircraft", Value: fmt.Sprintf("%d", c.Size), Inline: true},
+			},
+		}},
+	}
+}
+
+// aircraftCluster converts a cluster result to its stored form.
+func aircraftCluster(c model.ClusterResult, firstSeen, now time.Time) model.AircraftCluster {
+	stored := model.AircraftCluster{
+		ID:           c.ID,
+		CentroidLat:  c.CentroidLat,
+		CentroidLng:  c.CentroidLng,
+		Size:         c.Size,
+		AircraftIDs:  make([]uuid.UUID, 0, len(c.Points)),
+		MediaPresent: c.MediaPresent,
+		FirstSeenAt:  firstSeen,
+		LastSeenAt:   now,
+	}
+	for _, p := range c.Points {
+		if id, err := uuid.Parse(p.ID); err == nil {
+			stored.AircraftIDs = append(stored.AircraftIDs, id)
+		}
+	}
+	return stored
+}
+
+func clusterPoint(a model.Aircraft) model.ClusterPoint {
+	return model.ClusterPoint{
+		ID:        a.ID.String(),
+		ICAO:      a.ICAO,
+		Callsign:  a.Callsign,
+		Latitude:  *a.Latitude,
+		Longitude: *a.Longitude,
+		Altitude:  a.Altitude,
+		Category:  a.Category,
+		OnGround:  a.OnGround,
+		Metadata:  a.Metadata,
+	}
+}
+
+// summarizeClusters converts DBSCAN output into cluster results with
+// centroids. Points must carry their model.ClusterPoint as "point".
+func summarizeClusters(clusters []dbscan.Cluster) []model.ClusterResult {
+	results := make([]model.ClusterResult, 0, len(clusters))
+	for _, c := range clusters {
+		result := model.ClusterResult{ID: c.ID, Points: make([]model.ClusterPoint, 0, len(c.Points))}
+		for _, pt := range c.Points {
+			cp, ok := pt.Metadata["point"].(model.ClusterPoint)
+			if !ok {
+				continue
+			}
+			result.Points = append(result.Points, cp)
+			result.CentroidLat += cp.Latitude
+			result.CentroidLng += cp.Longitude
+			if cp.Category == model.AircraftCategoryMedia {
+				result.MediaPresent = true
+			}
+		}
+		if len(result.Points) == 0 {
+			continue
+		}
+		result.Size = len(result.Points)
+		result.CentroidLat /= float64(result.Size)
+		result.CentroidLng /= float64(result.Size)
+		results = append(results, result)
+	}
+	return results
+}
+
+// aircraftLabels names cluster members by watchlist group, callsign or ICAO.
+func aircraftLabels(points []model.ClusterPoint) []string {
+	labels := make([]string, 0, len(points))
+	for _, p := range points {
+		label := p.ICAO
+		if p.Callsign != "" {
+			label = p.Callsign
+		}
+		if group, ok := p.Metadata["watchlist_group"].(string); ok && group != "" {
+			label = group + " (" + label + ")"
+		}
+		labels = append(labels, label)
+	}
+	return labels
+}
+
+func formatLatLng(lat, lng float64) string {
+	return fmt.Sprintf("%.4f, %.4f", lat, lng)
+}
+
+// bofCandidate is a media and law enforcement cluster being watched.
+type bofCandidate struct {
+	anchorLat, anchorLng float64
+	members              []string // ICAO addresses
+	since                time.Time
+	lastSeen             time.Time
+	reported             bool
+}
+
+// bofReport is a spot a draft chase was created for.
+type bofReport struct {
+	lat, lng float64
+	at       time.Time
+}
+
+// bofTracker follows candidate clusters across runs. A cluster continues a
+// candidate when they share an aircraft and its centroid is still within
+// drift of where the candidate started; a cluster that moves further away
+// is transiting, not circling, and starts over even though its tracked ID
+// is unchanged.
+type bofTracker struct {
+	dwell    time.Duration
+	cooldown time.Duration
+	drift    float64 // meters
+	gap      time.Duration
+
+	candidates []*bofCandidate
+	reports    []bofReport
+}
+
+func newBoFTracker(dwell, cooldown time.Duration, drift float64, gap time.Duration) *bofTracker {
+	return &bofTracker{
+		dwell:    dwell,
+		cooldown: cooldown,
+		drift:    drift,
+		gap:      gap,
+	}
+}
+
+// observe records one run's clusters and returns those that have now
+// circled for the dwell time.
+func (t *bofTracker) observe(now time.Time, clusters []model.ClusterResult) []model.ClusterResult {
+	var due []model.ClusterResult
+	for _, c := range clusters {
+		if !c.MediaPresent || !hasCategory(c.Points, model.AircraftCategoryLawEnforcement) {
+			continue
+		}
+
+		members := make([]string, 0, len(c.Points))
+		for _, p := range c.Points {
+			members = append(members, p.ICAO)
+		}
+
+		cand := t.match(members)
+		if cand == nil {
+			cand = &bofCandidate{anchorLat: c.CentroidLat, anchorLng: c.CentroidLng, since: now}
+			t.candidates = append(t.candidates, cand)
+		} else if dbscan.DistanceMeters(cand.anchorLat, cand.anchorLng, c.CentroidLat, c.CentroidLng) > t.drift {
+			cand.anchorLat, cand.anchorLng, cand.since, cand.reported = c.CentroidLat, c.CentroidLng, now, false
+		}
+		cand.members = members
+		cand.lastSeen = now
+
+		if cand.reported || now.Sub(cand.since) < t.dwell || t.cooling(now, c.CentroidLat, c.CentroidLng) {
+			continue
+		}
+		cand.reported = true
+		t.reports = append(t.reports, bofReport{lat: c.CentroidLat, lng: c.CentroidLng, at: now})
+		due = append(due, c)
+	}
+
+	t.candidates = slices.DeleteFunc(t.candidates, func(c *bofCandidate) bool {
+		return now.Sub(c.lastSeen) > t.gap
+	})
+	t.reports = slices.DeleteFunc(t.reports, func(r bofReport) bool {
+		return now.Sub(r.at) > t.cooldown
+	})
+	return due
+}
+
+func (t *bofTracker) match(members []string) *bofCandidate {
+	for _, cand := range t.candidates {
+		for _, icao := range members {
+			if slices.Contains(cand.members, icao) {
+				return cand
+			}
+		}
+	}
+	return nil
+}
+
+// cooling reports whether a draft was created near this spot recently.
+func (t *bofTracker) cooling(now time.Time, lat, lng float64) bool {
+	for _, r := range t.reports {
+		if now.Sub(r.at) <= t.cooldown && dbscan.DistanceMeters(r.lat, r.lng, lat, lng) <= t.drift {
+			return true
+		}
+	}
+	return false
+}
+
+func hasCategory(points []model.ClusterPoint, category model.AircraftCategory) bool {
+	for _, p := range points {
+		if p.Category == category {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"chaseapp.tv/api/internal/model"
+	"chaseapp.tv/api/pkg/dbscan"
+)
+
+func bofPoint(icao string, lat, lng float64, category model.AircraftCategory) dbscan.Point {
+	return dbscan.Point{
+		ID:  icao,
+		Lat: lat,
+		Lng: lng,
+		Metadata: map[string]any{"point": model.ClusterPoint{
+			ID: icao, ICAO: icao, Latitude: lat, Longitude: lng, Category: category,
+		}},
+	}
+}
+
+// orbit returns a media/police cluster around lat, lng, offset slightly per
+// tick so the aircraft appear to circle.
+func orbit(lat, lng float64, tick int) []model.ClusterResult {
+	d := 0.002 * float64(tick%4)
+	points := []dbscan.Point{
+		bofPoint("A4B2C1", lat+d, lng, model.AircraftCategoryMedia),
+		bofPoint("AD64F3", lat, lng+d, model.AircraftCategoryLawEnforcement),
+		bofPoint("A1F3E2", lat-d, lng-d, model.AircraftCategoryMedia),
+	}
+	return summarizeClusters(dbscan.ClusterPoints(points, 3000, 2))
+}
+
+func TestBoFTrackerReportsAfterDwell(t *testing.T) {
+	tracker := newBoFTracker(5*time.Minute, time.Hour, 3000, time.Minute)
+	start := time.Now()
+
+	var reported []model.ClusterResult
+	for tick := 0; tick <= 10; tick++ {
+		now := start.Add(time.Duration(tick) * 30 * time.Second)
+		due := tracker.observe(now, orbit(34.05, -118.24, tick))
+		if tick < 10 {
+			require.Empty(t, due, "tick %d is before the dwell time", tick)
+		}
+		reported = append(reported, due...)
+	}
+	require.Len(t, reported, 1)
+	require.InDelta(t, 34.05, reported[0].CentroidLat, 0.01)
+
+	// Still circling: no second draft.
+	require.Empty(t, tracker.observe(start.Add(6*time.Minute), orbit(34.05, -118.24, 1)))
+}
+
+func TestBoFTrackerIgnoresTransitAndMediaOnly(t *testing.T) {
+	tracker := newBoFTracker(2*time.Minute, time.Hour, 3000, time.Minute)
+	start := time.Now()
+
+	// The cluster moves ~5.5 km per tick, so it never settles.
+	for tick := 0; tick < 10; tick++ {
+		now := start.Add(time.Duration(tick) * 30 * time.Second)
+		require.Empty(t, tracker.observe(now, orbit(34.05+0.05*float64(tick), -118.24, 0)))
+	}
+
+	media := summarizeClusters(dbscan.ClusterPoints([]dbscan.Point{
+		bofPoint("A00001", 33.9, -118.1, model.AircraftCategoryMedia),
+		bofPoint("A00002", 33.9, -118.1, model.AircraftCategoryMedia),
+		bofPoint("A00003", 33.9, -118.1, model.AircraftCategoryMedia),
+	}, 3000, 2))
+	require.Len(t, media, 1)
+	for tick := 0; tick < 10; tick++ {
+		require.Empty(t, tracker.observe(start.Add(time.Duration(tick)*time.Minute), media))
+	}
+}
@@ -0,0 +1,54 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+
+	"chaseapp.tv/api/internal/realtime"
+	"chaseapp.tv/api/internal/repository"
+)
+
+// chatChannelConsumer is the durable JetStream queue for channel creation.
+const chatChannelConsumer = "chat-channels"
+
+// ChatChannelWorker creates a chat channel for each new chase.
+type ChatChannelWorker struct {
+	js     *realtime.JetStream
+	repo   *repository.ChatRepository
+	logger *slog.Logger
+}
+
+// NewChatChannelWorker creates a new chat channel worker.
+func NewChatChannelWorker(js *realtime.JetStream, repo *repository.ChatRepository, logger *slog.Logger) *ChatChannelWorker {
+	return &ChatChannelWorker{
+		js:     js,
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// Start consumes chases.created and blocks until context cancellation.
+func (w *ChatChannelWorker) Start(ctx context.Context) error {
+	if w.js == nil || w.repo == nil {
+		return nil
+	}
+
+	_, err := w.js.ConsumeChases(chatChannelConsumer, func(evt realtime.ChaseEvent) error {
+		if evt.Event != realtime.SubjectChaseCreated || evt.Chase == nil {
+			return nil
+		}
+		channel, err := w.repo.EnsureChannel(ctx, evt.Chase.ID)
+		if err != nil {
+			w.logger.Error("failed to create chat channel", slog.Any("error", err), slog.String("chase_id", evt.Chase.ID.String()))
+			return err
+		}
+		w.logger.Info("chat channel ready", slog.String("chase_id", evt.Chase.ID.String()), slog.String("channel_id", channel.ID.String()))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	<-ctx.Done()
+	return nil
+}
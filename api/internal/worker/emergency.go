@@ -0,0 +1,201 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"chaseapp.tv/api/internal/model"
+	"chaseapp.tv/api/internal/realtime"
+	"chaseapp.tv/api/internal/repository"
+	"chaseapp.tv/api/internal/webhook"
+)
+
+// emergencyConsumer is the durable JetStream queue shared by all replicas
+// so each emergency is alerted once.
+const emergencyConsumer = "aircraft-emergencies"
+
+// emergencySweepInterval is how often episodes of aircraft that stopped
+// reporting are ended, along with the chases of ended episodes.
+const emergencySweepInterval = time.Minute
+
+// EmergencyWorker alerts Discord to aircraft.emergency events and, when
+// enabled, creates a live aircraft chase for each, which it ends when the
+// emergency does.
+type EmergencyWorker struct {
+	js          *realtime.JetStream
+	aircraft    *repository.AircraftRepository
+	chases      *repository.ChaseRepository
+	publisher   *realtime.Publisher
+	discord     *webhook.Client
+	createChase bool
+	resetAfter  time.Duration
+	logger      *slog.Logger
+}
+
+// NewEmergencyWorker creates a new emergency worker. discord may be nil.
+// Emergencies end once the aircraft has not been seen for resetAfter.
+func NewEmergencyWorker(js *realtime.JetStream, aircraft *repository.AircraftRepository, chases *repository.ChaseRepository, publisher *realtime.Publisher, discord *webhook.Client, createChase bool, resetAfter time.Duration, logger *slog.Logger) *EmergencyWorker {
+	return &EmergencyWorker{
+		js:          js,
+		aircraft:    aircraft,
+		chases:      chases,
+		publisher:   publisher,
+		discord:     discord,
+		createChase: createChase,
+		resetAfter:  resetAfter,
+		logger:      logger,
+	}
+}
+
+// Start consumes aircraft.emergency and ends finished emergencies until
+// context cancellation. Every replica sweeps; each episode and chase is
+// ended by a single conditional update, so only one replica ends it.
+func (w *EmergencyWorker) Start(ctx context.Context) error {
+	if w.js != nil && (w.discord != nil || w.createChase) {
+		_, err := w.js.ConsumeAircraftEmergencies(emergencyConsumer, func(evt realtime.EmergencyEvent) error {
+			return w.handle(ctx, evt)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	RunInterval(ctx, emergencySweepInterval, w.sweep)
+	return nil
+}
+
+// sweep ends the episodes of aircraft that stopped reporting, then the live
+// chases of episodes that are over, announcing each as ended.
+func (w *EmergencyWorker) sweep(ctx context.Context) {
+	now := time.Now()
+	if _, err := w.aircraft.EndSilentEmergencies(ctx, now.Add(-w.resetAfter)); err != nil {
+		w.logger.Warn("failed to end silent aircraft emergencies", slog.Any("error", err))
+	}
+
+	ids, err := w.chases.EndEmergencyChases(ctx, now)
+	if err != nil {
+		w.logger.Warn("failed to end emergency chases", slog.Any("error", err))
+		return
+	}
+	for _, id := range ids {
+		chase, err := w.chases.GetByID(ctx, id)
+		if err != nil {
+			w.logger.Warn("failed to load ended emergency chase",
+				slog.Any("error", err),
+				slog.String("chase_id", id.String()),
+			)
+			continue
+		}
+		w.publishChase(realtime.SubjectChaseEnded, chase)
+	}
+}
+
+// handle creates the chase before alerting so that a failed create is
+// redelivered without posting to Discord twice.
+func (w *EmergencyWorker) handle(ctx context.Context, evt realtime.EmergencyEvent) error {
+	var chase *model.Chase
+	if w.createChase {
+		var err error
+		chase, err = w.chases.Create(ctx, emergencyChase(evt), nil)
+		if err != nil {
+			w.logger.Error("failed to create emergency chase",
+				slog.Any("error", err),
+				slog.String("icao", evt.Aircraft.ICAO),
+			)
+			return err
+		}
+		w.publishChase(realtime.SubjectChaseCreated, chase)
+		w.publishChase(realtime.SubjectChaseLive, chase)
+	}
+
+	if w.discord != nil {
+		if err := w.discord.Send(ctx, emergencyMessage(evt, chase)); err != nil {
+			w.logger.Warn("failed to post emergency to discord",
+				slog.Any("error", err),
+				slog.String("icao", evt.Aircraft.ICAO),
+			)
+		}
+	}
+	return nil
+}
+
+func (w *EmergencyWorker) publishChase(subject string, chase *model.Chase) {
+	if err := w.publisher.PublishChase(subject, chase); err != nil {
+		w.logger.Warn("failed to publish emergency chase",
+			slog.Any("error", err),
+			slog.String("subject", subject),
+			slog.String("chase_id", chase.ID.String()),
+		)
+	}
+}
+
+func emergencyChase(evt realtime.EmergencyEvent) model.CreateChaseInput {
+	a := evt.Aircraft
+	in := model.CreateChaseInput{
+		Title:       evt.Reason + ": " + aircraftName(a),
+		Description: fmt.Sprintf("%s is declaring an emergency.", aircraftName(a)),
+		ChaseType:   model.ChaseTypeAircraft,
+		Live:        true,
+		Source:      "adsb",
+		Metadata: map[string]interface{}{
+			"emergency": map[string]interface{}{
+				"icao":      a.ICAO,
+				"callsign":  a.Callsign,
+				"squawk":    a.Squawk,
+				"emergency": a.Emergency,
+				"reason":    evt.Reason,
+			},
+		},
+	}
+	if a.Latitude != nil && a.Longitude != nil {
+		in.Location = &model.Location{Lat: *a.Latitude, Lng: *a.Longitude}
+	}
+	return in
+}
+
+func emergencyMessage(evt realtime.EmergencyEvent, chase *model.Chase) webhook.Message {
+	a := evt.Aircraft
+	embed := webhook.Embed{
+		Title:       evt.Reason,
+		Description: aircraftName(a),
+		Color:       0xD0021B,
+		Fields: []webhook.EmbedField{
+			{Name: "ICAO", Value: a.ICAO, Inline: true},
+		},
+	}
+	if a.Squawk != "" {
+		embed.Fields = append(embed.Fields, webhook.EmbedField{Name: "Squawk", Value: a.Squawk, Inline: true})
+	}
+	if a.Altitude != nil {
+		embed.Fields = append(embed.Fields, webhook.EmbedField{Name: "Altitude", Value: fmt.Sprintf("%d ft", *a.Altitude), Inline: true})
+	}
+	if a.Latitude != nil && a.Longitude != nil {
+		embed.URL = fmt.Sprintf("https://globe.adsbexchange.com/?icao=%s", a.ICAO)
+		embed.Fields = append(embed.Fields, webhook.EmbedField{Name: "Position", Value: formatLatLng(*a.Latitude, *a.Longitude)})
+	}
+	if chase != nil {
+		embed.Fields = append(embed.Fields, webhook.EmbedField{Name: "Chase", Value: chase.ID.String()})
+	}
+	return webhook.Message{
+		Content: "Aircraft emergency",
+		Embeds:  []webhook.Embed{embed},
+	}
+}
+
+// aircraftName labels an aircraft by callsign, registration and type where
+// known, e.g. "N411LA (AS50)".
+func aircraftName(a model.Aircraft) string {
+	name := a.ICAO
+	switch {
+	case a.Callsign != "":
+		name = a.Callsign
+	case a.Registration != "":
+		name = a.Registration
+	}
+	if a.AircraftType != "" {
+		name += " (" + a.AircraftType + ")"
+	}
+	return name
+}
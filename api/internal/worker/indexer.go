@@ -3,7 +3,6 @@ package worker
 import (
 	"context"
 	"log/slog"
-	"time"
 
 	"chaseapp.tv/api/internal/model"
 	"chaseapp.tv/api/internal/realtime"
@@ -54,10 +53,3 @@ func (w *IndexerWorker) Start(ctx context.Context) error {
 	<-ctx.Done()
 	return nil
 }
-
-// StatsWorker placeholder for stats aggregation.
-func StatsWorker(ctx context.Context, logger *slog.Logger) {
-	RunInterval(ctx, 10*time.Minute, func(ctx context.Context) {
-		logger.Info("stats worker tick")
-	})
-}
@@ -0,0 +1,87 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+
+	"chaseapp.tv/api/internal/push"
+	"chaseapp.tv/api/internal/realtime"
+)
+
+// notificationConsumer is the durable JetStream queue shared by all replicas
+// so each chase event is dispatched once.
+const notificationConsumer = "push-dispatcher"
+
+// NotificationWorker fans chase events out to push subscribers.
+type NotificationWorker struct {
+	js         *realtime.JetStream
+	dispatcher *push.Dispatcher
+	logger     *slog.Logger
+}
+
+// NewNotificationWorker creates a new notification worker.
+func NewNotificationWorker(js *realtime.JetStream, dispatcher *push.Dispatcher, logger *slog.Logger) *NotificationWorker {
+	return &NotificationWorker{
+		js:         js,
+		dispatcher: dispatcher,
+		logger:     logger,
+	}
+}
+
+// Start consumes chases.created and chases.live and blocks until context cancellation.
+func (w *NotificationWorker) Start(ctx context.Context) error {
+	if w.js == nil || w.dispatcher == nil {
+		return nil
+	}
+
+	_, err := w.js.ConsumeChases(notificationConsumer, func(evt realtime.ChaseEvent) error {
+		return w.handle(ctx, evt)
+	})
+	if err != nil {
+		return err
+	}
+
+	<-ctx.Done()
+	return nil
+}
+
+func (w *NotificationWorker) handle(ctx context.Context, evt realtime.ChaseEvent) error {
+	chase := evt.Chase
+	if chase == nil {
+		return nil
+	}
+
+	switch evt.Event {
+	case realtime.SubjectChaseLive:
+	case realtime.SubjectChaseCreated:
+		// Chases created live also publish chases.live; notify once.
+		if chase.Live {
+			return nil
+		}
+	default:
+		return nil
+	}
+
+	n := push.ChaseNotification(chase)
+	result, err := w.dispatcher.DispatchTopic(ctx, n)
+	if err != nil {
+		w.logger.Error("push dispatch failed",
+			slog.Any("error", err),
+			slog.String("chase_id", chase.ID.String()),
+			slog.String("event", evt.Event),
+		)
+		return err
+	}
+
+	w.logger.Info("push dispatch complete",
+		slog.String("chase_id", chase.ID.String()),
+		slog.String("event", evt.Event),
+		slog.String("topic", n.Topic),
+		slog.Int("targeted", result.Targeted),
+		slog.Any("succeeded", result.Succeeded),
+		slog.Any("failed", result.Failed),
+		slog.Int("deactivated", result.Deactivated),
+		slog.Int("filtered", result.Filtered),
+	)
+	return nil
+}
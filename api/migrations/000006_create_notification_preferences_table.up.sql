@@ -0,0 +1,32 @@
+-- Notification preferences table
+-- Per-user filters evaluated before a push is delivered
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+
+    -- Chase types to receive; empty means all types
+    chase_types TEXT[] NOT NULL DEFAULT ARRAY[]::TEXT[],
+
+    -- Geographic filters; a chase passes if it is within radius_km of home
+    -- or in one of the listed states
+    home_lat DOUBLE PRECISION,
+    home_lng DOUBLE PRECISION,
+    radius_km DOUBLE PRECISION,
+    states TEXT[] NOT NULL DEFAULT ARRAY[]::TEXT[],  -- ['CA', 'TX']
+
+    -- Quiet hours as local wall-clock times, e.g. 22:00 to 07:00
+    quiet_hours_start VARCHAR(5),
+    quiet_hours_end VARCHAR(5),
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT notification_preferences_home_check
+        CHECK ((home_lat IS NULL) = (home_lng IS NULL))
+);
+
+-- Updated at trigger
+CREATE TRIGGER update_notification_preferences_updated_at
+    BEFORE UPDATE ON notification_preferences
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,41 @@
+-- Notifications table
+-- Records every push dispatch for the public feed and auditing
+CREATE TABLE IF NOT EXISTS notifications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    -- Content
+    title VARCHAR(500) NOT NULL,
+    body TEXT,
+    image_url TEXT,
+    chase_id UUID REFERENCES chases(id) ON DELETE SET NULL,
+    topic VARCHAR(100) NOT NULL,
+
+    -- Delivery receipts
+    target_count INTEGER NOT NULL DEFAULT 0,
+    filtered_count INTEGER NOT NULL DEFAULT 0,
+    deactivated_count INTEGER NOT NULL DEFAULT 0,
+    succeeded JSONB NOT NULL DEFAULT '{}'::jsonb,  -- { "ios": 10, "android": 7 }
+    failed JSONB NOT NULL DEFAULT '{}'::jsonb,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_notifications_created_at ON notifications(created_at DESC);
+CREATE INDEX idx_notifications_chase_id ON notifications(chase_id) WHERE chase_id IS NOT NULL;
+CREATE INDEX idx_notifications_topic ON notifications(topic);
+
+-- Per-user inbox and delivery outcome
+CREATE TABLE IF NOT EXISTS notification_recipients (
+    notification_id UUID NOT NULL REFERENCES notifications(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+
+    status VARCHAR(20) NOT NULL,  -- delivered, failed, filtered
+    read_at TIMESTAMPTZ,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (notification_id, user_id)
+);
+
+CREATE INDEX idx_notification_recipients_inbox ON notification_recipients(user_id, created_at DESC);
+CREATE INDEX idx_notification_recipients_unread ON notification_recipients(user_id) WHERE read_at IS NULL;
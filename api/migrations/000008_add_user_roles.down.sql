@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_users_roles;
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS users_roles_check,
+    DROP COLUMN IF EXISTS roles;
@@ -0,0 +1,8 @@
+-- User roles
+-- Every user has the user role; admins and moderators are granted explicitly
+ALTER TABLE users
+    ADD COLUMN roles TEXT[] NOT NULL DEFAULT ARRAY['user']::TEXT[],
+    ADD CONSTRAINT users_roles_check
+        CHECK (roles <@ ARRAY['admin', 'moderator', 'user']::TEXT[]);
+
+CREATE INDEX idx_users_roles ON users USING GIN(roles);
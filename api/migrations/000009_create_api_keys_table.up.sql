@@ -0,0 +1,24 @@
+-- API keys table
+-- Credentials for machine clients (ingestion bots, ADS-B feeders, partners)
+CREATE TABLE IF NOT EXISTS api_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+
+    -- Only the SHA-256 of the key is stored; the prefix identifies it in lists
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    key_prefix VARCHAR(16) NOT NULL,
+
+    scopes TEXT[] NOT NULL DEFAULT ARRAY[]::TEXT[],  -- ['chases:write', 'aircraft:ingest']
+
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+
+    -- Usage
+    last_used_at TIMESTAMPTZ,
+    request_count BIGINT NOT NULL DEFAULT 0,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_api_keys_created_at ON api_keys(created_at DESC);
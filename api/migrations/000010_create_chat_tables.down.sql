@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS chat_moderation_actions;
+DROP TABLE IF EXISTS chat_channels;
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS users_chat_role_check,
+    DROP COLUMN IF EXISTS chat_role;
@@ -0,0 +1,33 @@
+-- Chat roles
+-- Per-user chat role used to derive chat token permissions
+ALTER TABLE users
+    ADD COLUMN chat_role VARCHAR(20) NOT NULL DEFAULT 'viewer',
+    ADD CONSTRAINT users_chat_role_check
+        CHECK (chat_role IN ('viewer', 'moderator', 'admin'));
+
+-- Chat channels
+-- One channel per chase, created when the chase is
+CREATE TABLE IF NOT EXISTS chat_channels (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    chase_id UUID NOT NULL UNIQUE REFERENCES chases(id) ON DELETE CASCADE,
+    name VARCHAR(500) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Chat moderation actions
+-- Mutes and bans; a NULL channel applies to every channel
+CREATE TABLE IF NOT EXISTS chat_moderation_actions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    channel_id UUID REFERENCES chat_channels(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    action VARCHAR(10) NOT NULL CHECK (action IN ('mute', 'ban')),
+    reason TEXT,
+    moderator_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMPTZ,
+    lifted_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_chat_moderation_user ON chat_moderation_actions(user_id)
+    WHERE lifted_at IS NULL;
+CREATE INDEX idx_chat_moderation_channel ON chat_moderation_actions(channel_id, created_at DESC);
@@ -0,0 +1,17 @@
+-- Chat tokens
+-- Issued chat JWTs by jti; revoked rows that have not expired form the
+-- revocation list
+CREATE TABLE IF NOT EXISTS chat_tokens (
+    jti UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    channel_id UUID REFERENCES chat_channels(id) ON DELETE CASCADE,
+    issued_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ,
+    revoked_reason VARCHAR(100)
+);
+
+CREATE INDEX idx_chat_tokens_user ON chat_tokens(user_id, expires_at);
+CREATE INDEX idx_chat_tokens_revoked ON chat_tokens(revoked_at)
+    WHERE revoked_at IS NOT NULL;
+CREATE INDEX idx_chat_tokens_expires ON chat_tokens(expires_at);
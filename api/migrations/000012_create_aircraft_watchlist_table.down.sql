@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS update_aircraft_watchlist_updated_at ON aircraft_watchlist;
+DROP TABLE IF EXISTS aircraft_watchlist;
@@ -0,0 +1,28 @@
+-- Aircraft watchlist
+-- Known aircraft (news helicopters, police units, ...) by ICAO address or
+-- tail number; ingestion copies category and operator onto matching aircraft
+CREATE TABLE IF NOT EXISTS aircraft_watchlist (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    icao VARCHAR(10),           -- ICAO 24-bit address (hex, upper case)
+    registration VARCHAR(20),   -- Tail number (upper case)
+
+    group_name VARCHAR(100),    -- Station or agency, e.g. "KTLA", "LAPD"
+    category VARCHAR(50) NOT NULL,
+    image_url TEXT,
+    operator VARCHAR(255),
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT aircraft_watchlist_identified CHECK (icao IS NOT NULL OR registration IS NOT NULL)
+);
+
+CREATE UNIQUE INDEX idx_aircraft_watchlist_icao ON aircraft_watchlist(icao) WHERE icao IS NOT NULL;
+CREATE UNIQUE INDEX idx_aircraft_watchlist_registration ON aircraft_watchlist(registration) WHERE registration IS NOT NULL;
+CREATE INDEX idx_aircraft_watchlist_category ON aircraft_watchlist(category);
+
+CREATE TRIGGER update_aircraft_watchlist_updated_at
+    BEFORE UPDATE ON aircraft_watchlist
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_chases_drafts;
+ALTER TABLE chases
+    DROP COLUMN IF EXISTS draft;
@@ -0,0 +1,7 @@
+-- Chase drafts
+-- Drafts (e.g. detected from aircraft clusters) are hidden from public
+-- listings, search and notifications until a moderator publishes them
+ALTER TABLE chases
+    ADD COLUMN draft BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX idx_chases_drafts ON chases(created_at DESC) WHERE draft AND deleted_at IS NULL;
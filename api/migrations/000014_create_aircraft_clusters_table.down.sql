@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS update_aircraft_clusters_updated_at ON aircraft_clusters;
+DROP TABLE IF EXISTS aircraft_clusters;
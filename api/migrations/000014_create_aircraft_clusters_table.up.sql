@@ -0,0 +1,26 @@
+-- Aircraft clusters
+-- Clusters followed across clustering runs with stable IDs; aircraft.cluster_id
+-- refers to these
+CREATE TABLE IF NOT EXISTS aircraft_clusters (
+    id VARCHAR(50) PRIMARY KEY,
+
+    centroid_lat DOUBLE PRECISION NOT NULL,
+    centroid_lng DOUBLE PRECISION NOT NULL,
+    size INTEGER NOT NULL,
+    aircraft_ids UUID[] NOT NULL DEFAULT '{}',
+    media_present BOOLEAN NOT NULL DEFAULT false,
+
+    first_seen_at TIMESTAMPTZ NOT NULL,
+    last_seen_at TIMESTAMPTZ NOT NULL,
+    ended_at TIMESTAMPTZ,        -- Set when the cluster merged or dissolved
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_aircraft_clusters_active ON aircraft_clusters(last_seen_at DESC) WHERE ended_at IS NULL;
+
+CREATE TRIGGER update_aircraft_clusters_updated_at
+    BEFORE UPDATE ON aircraft_clusters
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
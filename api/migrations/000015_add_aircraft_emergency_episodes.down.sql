@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_aircraft_emergency;
+ALTER TABLE aircraft
+    DROP COLUMN IF EXISTS emergency_started_at;
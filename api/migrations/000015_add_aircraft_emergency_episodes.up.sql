@@ -0,0 +1,7 @@
+-- Aircraft emergency episodes
+-- Kept on the aircraft row so that replicas ingesting reports for the same
+-- aircraft agree on when an emergency started and announce it once
+ALTER TABLE aircraft
+    ADD COLUMN emergency_started_at TIMESTAMPTZ;  -- Start of the current emergency, if any
+
+CREATE INDEX idx_aircraft_emergency ON aircraft(icao) WHERE emergency_started_at IS NOT NULL;
@@ -0,0 +1,5 @@
+-- Private notifications
+-- Broadcasts addressed to chosen users or an area are kept out of the public
+-- feed and only shown in their recipients' inboxes
+ALTER TABLE notifications
+    ADD COLUMN private BOOLEAN NOT NULL DEFAULT false;  -- Hidden from the public feed
@@ -0,0 +1,99 @@
+// Package adsb parses aircraft reports from dump1090/readsb aircraft.json
+// and SBS-1 (BaseStation) feeds.
+package adsb
+
+import (
+	"strings"
+	"time"
+)
+
+// Report is an observation of one aircraft. Nil and empty fields were not
+// part of the observation, so reports can be merged.
+type Report struct {
+	ICAO         string // upper-case 24-bit hex address
+	Callsign     string
+	Registration string
+	AircraftType string
+	Category     string // ADS-B emitter category, e.g. "A7"
+	Squawk       string
+	Emergency    string // readsb values: none, general, lifeguard, minfuel, nordo, unlawful, downed
+
+	Lat          *float64
+	Lng          *float64
+	Altitude     *int // feet, barometric
+	GroundSpeed  *int // knots
+	Track        *int // degrees
+	VerticalRate *int // ft/min
+	OnGround     *bool
+
+	SeenAt time.Time
+}
+
+// HasPosition reports whether the report carries a position.
+func (r Report) HasPosition() bool {
+	return r.Lat != nil && r.Lng != nil
+}
+
+// Merge overlays the fields present in u onto r.
+func (r *Report) Merge(u Report) {
+	mergeString(&r.Callsign, u.Callsign)
+	mergeString(&r.Registration, u.Registration)
+	mergeString(&r.AircraftType, u.AircraftType)
+	mergeString(&r.Category, u.Category)
+	mergeString(&r.Squawk, u.Squawk)
+	mergeString(&r.Emergency, u.Emergency)
+	if u.HasPosition() {
+		r.Lat, r.Lng = u.Lat, u.Lng
+	}
+	if u.Altitude != nil {
+		r.Altitude = u.Altitude
+	}
+	if u.GroundSpeed != nil {
+		r.GroundSpeed = u.GroundSpeed
+	}
+	if u.Track != nil {
+		r.Track = u.Track
+	}
+	if u.VerticalRate != nil {
+		r.VerticalRate = u.VerticalRate
+	}
+	if u.OnGround != nil {
+		r.OnGround = u.OnGround
+	}
+	if u.SeenAt.After(r.SeenAt) {
+		r.SeenAt = u.SeenAt
+	}
+}
+
+func mergeString(dst *string, v string) {
+	if v != "" {
+		*dst = v
+	}
+}
+
+// normalizeICAO returns the upper-case hex address, or "" for anonymous
+// and non-ICAO addresses (readsb prefixes those with "~").
+func normalizeICAO(hex string) string {
+	hex = strings.ToUpper(strings.TrimSpace(hex))
+	if len(hex) != 6 {
+		return ""
+	}
+	for _, c := range hex {
+		if (c < '0' || c > '9') && (c < 'A' || c > 'F') {
+			return ""
+		}
+	}
+	return hex
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func boolPtr(v bool) *bool {
+	return &v
+}
@@ -0,0 +1,99 @@
+package adsb
+
+import (
+	"bufio"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAircraftJSON(t *testing.T) {
+	data, err := os.ReadFile("testdata/aircraft.json")
+	require.NoError(t, err)
+
+	reports, err := ParseAircraftJSON(data, time.Now())
+	require.NoError(t, err)
+
+	byICAO := map[string]Report{}
+	for _, r := range reports {
+		byICAO[r.ICAO] = r
+	}
+	require.Len(t, byICAO, 6, "the ~ TIS-B address is skipped")
+
+	heli := byICAO["A4B2C1"]
+	require.Equal(t, "N411LA", heli.Callsign)
+	require.Equal(t, "AS50", heli.AircraftType)
+	require.Equal(t, "A7", heli.Category)
+	require.InDelta(t, 34.052235, *heli.Lat, 1e-9)
+	require.Equal(t, 1450, *heli.Altitude)
+	require.Equal(t, 82, *heli.GroundSpeed)
+	require.Equal(t, -128, *heli.VerticalRate)
+	require.False(t, *heli.OnGround)
+	require.Equal(t, time.Unix(1760800000, 0).Add(-200*time.Millisecond), heli.SeenAt)
+
+	require.Equal(t, "general", byICAO["AD64F3"].Emergency)
+	require.Equal(t, 320, *byICAO["AD64F3"].VerticalRate)
+
+	ground := byICAO["A1F3E2"]
+	require.True(t, *ground.OnGround)
+	require.Nil(t, ground.Altitude)
+
+	require.False(t, byICAO["A9C001"].HasPosition(), "stale positions are dropped")
+
+	legacy := byICAO["A0E911"]
+	require.Equal(t, 4200, *legacy.Altitude)
+	require.Equal(t, 140, *legacy.GroundSpeed)
+	require.Equal(t, -640, *legacy.VerticalRate)
+
+	require.False(t, byICAO["A77AA7"].HasPosition())
+}
+
+func TestParseSBS(t *testing.T) {
+	f, err := os.Open("testdata/basestation.sbs")
+	require.NoError(t, err)
+	defer f.Close()
+
+	now := time.Now()
+	merged := map[string]*Report{}
+	var skipped, invalid int
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		r, err := ParseSBS(scanner.Text(), now)
+		if err == ErrNotSBSMessage {
+			skipped++
+			continue
+		}
+		if err != nil {
+			invalid++
+			continue
+		}
+		if m, ok := merged[r.ICAO]; ok {
+			m.Merge(r)
+		} else {
+			merged[r.ICAO] = &r
+		}
+	}
+	require.NoError(t, scanner.Err())
+	require.Equal(t, 2, skipped)
+	require.Equal(t, 1, invalid)
+	require.Len(t, merged, 3)
+
+	heli := merged["A4B2C1"]
+	require.Equal(t, "N411LA", heli.Callsign)
+	require.Equal(t, 1450, *heli.Altitude)
+	require.InDelta(t, -118.24368, *heli.Lng, 1e-9)
+	require.Equal(t, 82, *heli.GroundSpeed)
+	require.Equal(t, 271, *heli.Track)
+	require.Equal(t, "1200", heli.Squawk)
+	require.Equal(t, "none", heli.Emergency)
+	require.False(t, *heli.OnGround)
+
+	news := merged["AD64F3"]
+	require.Equal(t, "KTLA5", news.Callsign)
+	require.Equal(t, "7700", news.Squawk)
+	require.Equal(t, "general", news.Emergency)
+
+	require.True(t, *merged["A1F3E2"].OnGround)
+}
@@ -0,0 +1,125 @@
+package adsb
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// maxPositionAge is how old a position in aircraft.json may be before it is
+// ignored.
+const maxPositionAge = 60 * time.Second
+
+// aircraftFile is the dump1090/readsb aircraft.json document.
+type aircraftFile struct {
+	Now      float64        `json:"now"`
+	Aircraft []jsonAircraft `json:"aircraft"`
+}
+
+// jsonAircraft covers both the readsb field names and the older dump1090
+// ones (altitude, speed, vert_rate).
+type jsonAircraft struct {
+	Hex          string          `json:"hex"`
+	Flight       string          `json:"flight"`
+	Registration string          `json:"r"`
+	Type         string          `json:"t"`
+	Category     string          `json:"category"`
+	Squawk       string          `json:"squawk"`
+	Emergency    string          `json:"emergency"`
+	Lat          *float64        `json:"lat"`
+	Lon          *float64        `json:"lon"`
+	AltBaro      json.RawMessage `json:"alt_baro"`
+	Altitude     json.RawMessage `json:"altitude"`
+	GS           *float64        `json:"gs"`
+	Speed        *float64        `json:"speed"`
+	Track        *float64        `json:"track"`
+	BaroRate     *float64        `json:"baro_rate"`
+	GeomRate     *float64        `json:"geom_rate"`
+	VertRate     *float64        `json:"vert_rate"`
+	Seen         float64         `json:"seen"`
+	SeenPos      *float64        `json:"seen_pos"`
+}
+
+// ParseAircraftJSON parses a dump1090/readsb aircraft.json document.
+// Aircraft without an ICAO address are skipped, as are positions older
+// than a minute. received is used when the document has no "now".
+func ParseAircraftJSON(data []byte, received time.Time) ([]Report, error) {
+	var file aircraftFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse aircraft.json: %w", err)
+	}
+
+	now := received
+	if file.Now > 0 {
+		sec, frac := math.Modf(file.Now)
+		now = time.Unix(int64(sec), int64(frac*1e9))
+	}
+
+	reports := make([]Report, 0, len(file.Aircraft))
+	for _, a := range file.Aircraft {
+		icao := normalizeICAO(a.Hex)
+		if icao == "" {
+			continue
+		}
+
+		r := Report{
+			ICAO:         icao,
+			Callsign:     strings.TrimSpace(a.Flight),
+			Registration: strings.TrimSpace(a.Registration),
+			AircraftType: strings.TrimSpace(a.Type),
+			Category:     a.Category,
+			Squawk:       a.Squawk,
+			Emergency:    a.Emergency,
+			SeenAt:       now.Add(-seconds(a.Seen)),
+		}
+
+		if a.Lat != nil && a.Lon != nil && (a.SeenPos == nil || seconds(*a.SeenPos) <= maxPositionAge) {
+			r.Lat, r.Lng = floatPtr(*a.Lat), floatPtr(*a.Lon)
+		}
+
+		alt := a.AltBaro
+		if len(alt) == 0 {
+			alt = a.Altitude
+		}
+		if len(alt) > 0 {
+			var feet float64
+			var s string
+			switch {
+			case json.Unmarshal(alt, &feet) == nil:
+				r.Altitude = intPtr(int(math.Round(feet)))
+				r.OnGround = boolPtr(false)
+			case json.Unmarshal(alt, &s) == nil && s == "ground":
+				r.OnGround = boolPtr(true)
+			}
+		}
+
+		r.GroundSpeed = roundPtr(firstNonNil(a.GS, a.Speed))
+		r.Track = roundPtr(a.Track)
+		r.VerticalRate = roundPtr(firstNonNil(a.BaroRate, a.GeomRate, a.VertRate))
+
+		reports = append(reports, r)
+	}
+	return reports, nil
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+func firstNonNil(vals ...*float64) *float64 {
+	for _, v := range vals {
+		if v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+func roundPtr(v *float64) *int {
+	if v == nil {
+		return nil
+	}
+	return intPtr(int(math.Round(*v)))
+}
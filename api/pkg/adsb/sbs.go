@@ -0,0 +1,115 @@
+package adsb
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotSBSMessage is returned for SBS-1 lines that carry no aircraft data,
+// such as SEL, ID, AIR, STA and CLK records.
+var ErrNotSBSMessage = errors.New("not an SBS-1 transmission message")
+
+// SBS-1 MSG field indexes.
+const (
+	sbsHexIdent     = 4
+	sbsCallsign     = 10
+	sbsAltitude     = 11
+	sbsGroundSpeed  = 12
+	sbsTrack        = 13
+	sbsLat          = 14
+	sbsLon          = 15
+	sbsVerticalRate = 16
+	sbsSquawk       = 17
+	sbsEmergency    = 19
+	sbsOnGround     = 21
+	sbsFields       = 22
+)
+
+// ParseSBS parses one SBS-1 BaseStation line, as served on port 30003.
+// Each transmission type fills only some fields; merge successive reports
+// for the same aircraft with Report.Merge. received is used as the
+// observation time since SBS timestamps are in the receiver's local zone.
+func ParseSBS(line string, received time.Time) (Report, error) {
+	fields := strings.Split(strings.TrimRight(line, "\r\n"), ",")
+	if len(fields) < sbsFields || fields[0] != "MSG" {
+		return Report{}, ErrNotSBSMessage
+	}
+
+	icao := normalizeICAO(fields[sbsHexIdent])
+	if icao == "" {
+		return Report{}, errors.New("sbs: invalid hex ident")
+	}
+
+	r := Report{
+		ICAO:     icao,
+		Callsign: strings.TrimSpace(fields[sbsCallsign]),
+		Squawk:   strings.TrimSpace(fields[sbsSquawk]),
+		SeenAt:   received,
+	}
+
+	var err error
+	if r.Altitude, err = sbsInt(fields[sbsAltitude]); err != nil {
+		return Report{}, err
+	}
+	if r.GroundSpeed, err = sbsInt(fields[sbsGroundSpeed]); err != nil {
+		return Report{}, err
+	}
+	if r.Track, err = sbsInt(fields[sbsTrack]); err != nil {
+		return Report{}, err
+	}
+	if r.VerticalRate, err = sbsInt(fields[sbsVerticalRate]); err != nil {
+		return Report{}, err
+	}
+
+	lat, lon := strings.TrimSpace(fields[sbsLat]), strings.TrimSpace(fields[sbsLon])
+	if lat != "" && lon != "" {
+		la, err1 := strconv.ParseFloat(lat, 64)
+		lo, err2 := strconv.ParseFloat(lon, 64)
+		if err1 != nil || err2 != nil {
+			return Report{}, errors.New("sbs: invalid position")
+		}
+		r.Lat, r.Lng = floatPtr(la), floatPtr(lo)
+	}
+
+	switch sbsFlag(fields[sbsEmergency]) {
+	case 1:
+		r.Emergency = "general"
+	case 0:
+		r.Emergency = "none"
+	}
+	switch sbsFlag(fields[sbsOnGround]) {
+	case 1:
+		r.OnGround = boolPtr(true)
+	case 0:
+		r.OnGround = boolPtr(false)
+	}
+
+	return r, nil
+}
+
+// sbsInt parses an optional numeric field, rounding decimals.
+func sbsInt(s string) (*int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, errors.New("sbs: invalid number " + strconv.Quote(s))
+	}
+	return roundPtr(&v), nil
+}
+
+// sbsFlag returns 1 for a set flag ("-1" or "1"), 0 for "0" and -1 when the
+// field is empty.
+func sbsFlag(s string) int {
+	switch strings.TrimSpace(s) {
+	case "-1", "1":
+		return 1
+	case "0":
+		return 0
+	}
+	return -1
+}
@@ -3,6 +3,7 @@ package dbscan
 
 import (
 	"math"
+	"slices"
 	"strconv"
 )
 
@@ -23,33 +24,54 @@ type Cluster struct {
 // ClusterPoints performs DBSCAN clustering.
 // epsMeters defines the neighborhood radius in meters.
 // minPoints defines the minimum number of points to form a dense region.
+// Neighbors are found through a grid index, so clustering stays fast for
+// large snapshots.
 func ClusterPoints(points []Point, epsMeters float64, minPoints int) []Cluster {
 	if epsMeters <= 0 || minPoints <= 0 || len(points) == 0 {
 		return nil
 	}
 
-	labels := make([]int, len(points)) // -1 noise, 0 unvisited, >=1 cluster id
+	index := newGrid(points, epsMeters)
+	labels, count := label(len(points), minPoints, func(idx int) []int {
+		return index.regionQuery(points, idx, epsMeters)
+	})
+	return buildClusters(points, labels, count)
+}
+
+// label runs DBSCAN over n points with the given neighbor query, which must
+// return the indexes of all other points in a point's neighborhood in
+// ascending order. It returns each point's label and the cluster count.
+func label(n, minPoints int, regionQuery func(idx int) []int) ([]int, int) {
+	return labelDense(n, func(_ int, neighbors []int) bool {
+		return len(neighbors) >= minPoints
+	}, regionQuery)
+}
+
+// labelDense is label with dense deciding whether a point with the given
+// neighbors is a core point.
+func labelDense(n int, dense func(idx int, neighbors []int) bool, regionQuery func(idx int) []int) ([]int, int) {
+	labels := make([]int, n) // -1 noise, 0 unvisited, >=1 cluster id
 	clusterID := 0
 
-	for i := range points {
+	for i := range labels {
 		if labels[i] != 0 {
 			continue // already processed
 		}
 
-		neighbors := regionQuery(points, i, epsMeters)
-		if len(neighbors) < minPoints {
+		neighbors := regionQuery(i)
+		if !dense(i, neighbors) {
 			labels[i] = -1 // noise
 			continue
 		}
 
 		clusterID++
-		expandCluster(points, labels, i, neighbors, clusterID, epsMeters, minPoints)
+		expandCluster(labels, i, neighbors, clusterID, dense, regionQuery)
 	}
 
-	return buildClusters(points, labels, clusterID)
+	return labels, clusterID
 }
 
-func expandCluster(points []Point, labels []int, pointIdx int, neighbors []int, clusterID int, epsMeters float64, minPoints int) {
+func expandCluster(labels []int, pointIdx int, neighbors []int, clusterID int, dense func(idx int, neighbors []int) bool, regionQuery func(idx int) []int) {
 	labels[pointIdx] = clusterID
 
 	for i := 0; i < len(neighbors); i++ {
@@ -64,13 +86,15 @@ func expandCluster(points []Point, labels []int, pointIdx int, neighbors []int,
 
 		labels[nIdx] = clusterID
 
-		nNeighbors := regionQuery(points, nIdx, epsMeters)
-		if len(nNeighbors) >= minPoints {
+		nNeighbors := regionQuery(nIdx)
+		if dense(nIdx, nNeighbors) {
 			neighbors = append(neighbors, nNeighbors...)
 		}
 	}
 }
 
+// regionQuery compares a point against every other point. It is the
+// reference the grid index must agree with.
 func regionQuery(points []Point, idx int, epsMeters float64) []int {
 	target := points[idx]
 	var neighbors []int
@@ -85,6 +109,78 @@ func regionQuery(points []Point, idx int, epsMeters float64) []int {
 	return neighbors
 }
 
+// grid buckets points into cells at least eps across, so a point's
+// neighbors are all in its own or an adjacent cell.
+type grid struct {
+	latSize float64 // degrees
+	lngSize float64 // degrees
+	cols    int     // longitude cells around the globe
+	cells   map[[2]int][]int
+}
+
+func newGrid(points []Point, epsMeters float64) *grid {
+	// Two points within eps differ in latitude by at most eps/R radians. In
+	// longitude they differ by at most 2·asin(sin(eps/2R) / cos φ), where φ
+	// is the latitude furthest from the equator among the points.
+	angle := epsMeters / earthRadiusMeters
+	minCos := 1.0
+	for _, p := range points {
+		minCos = min(minCos, math.Cos(toRadians(p.Lat)))
+	}
+
+	g := &grid{
+		latSize: toDegrees(angle) * (1 + 1e-9),
+		cols:    1,
+		cells:   make(map[[2]int][]int),
+	}
+	if s := math.Sin(angle/2) / minCos; minCos > 0 && s < 1 {
+		g.cols = max(1, int(360/(toDegrees(2*math.Asin(s))*(1+1e-9))))
+	}
+	g.lngSize = 360 / float64(g.cols)
+
+	for i, p := range points {
+		key := g.cell(p)
+		g.cells[key] = append(g.cells[key], i)
+	}
+	return g
+}
+
+func (g *grid) cell(p Point) [2]int {
+	col := int(math.Floor((p.Lng + 180) / g.lngSize))
+	return [2]int{int(math.Floor(p.Lat / g.latSize)), ((col % g.cols) + g.cols) % g.cols}
+}
+
+// regionQuery returns the same neighbors as the package-level regionQuery,
+// checking only the surrounding cells. Columns wrap at the antimeridian.
+func (g *grid) regionQuery(points []Point, idx int, epsMeters float64) []int {
+	target := points[idx]
+	center := g.cell(target)
+
+	cols := []int{center[1]}
+	for _, c := range []int{center[1] - 1, center[1] + 1} {
+		c = (c + g.cols) % g.cols
+		if !slices.Contains(cols, c) {
+			cols = append(cols, c)
+		}
+	}
+
+	var neighbors []int
+	for row := center[0] - 1; row <= center[0]+1; row++ {
+		for _, col := range cols {
+			for _, i := range g.cells[[2]int{row, col}] {
+				if i == idx {
+					continue
+				}
+				if haversineMeters(target.Lat, target.Lng, points[i].Lat, points[i].Lng) <= epsMeters {
+					neighbors = append(neighbors, i)
+				}
+			}
+		}
+	}
+	slices.Sort(neighbors)
+	return neighbors
+}
+
 func buildClusters(points []Point, labels []int, clusterCount int) []Cluster {
 	if clusterCount == 0 {
 		return nil
@@ -112,19 +208,30 @@ func generateClusterID(id int) string {
 	return "cluster-" + strconv.Itoa(id)
 }
 
+// DistanceMeters returns the great-circle distance between two points in
+// meters.
+func DistanceMeters(lat1, lng1, lat2, lng2 float64) float64 {
+	return haversineMeters(lat1, lng1, lat2, lng2)
+}
+
+// earthRadiusMeters is the mean Earth radius.
+const earthRadiusMeters = 6371000.0
+
 // haversineMeters calculates the great-circle distance between two points.
 func haversineMeters(lat1, lon1, lat2, lon2 float64) float64 {
-	const earthRadius = 6371000.0 // meters
-
 	dLat := toRadians(lat2 - lat1)
 	dLon := toRadians(lon2 - lon1)
 
 	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
 		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
 	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
-	return earthRadius * c
+	return earthRadiusMeters * c
 }
 
 func toRadians(deg float64) float64 {
 	return deg * (math.Pi / 180)
 }
+
+func toDegrees(rad float64) float64 {
+	return rad * (180 / math.Pi)
+}
@@ -0,0 +1,218 @@
+package dbscan
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// randomPoints scatters n points over a box around lat/lng, with a few
+// dense groups so there is something to cluster.
+func randomPoints(r *rand.Rand, n int, lat, lng, spanDeg float64) []Point {
+	points := make([]Point, n)
+	for i := range points {
+		p := Point{ID: strconv.Itoa(i), Lat: lat + (r.Float64()-0.5)*spanDeg, Lng: lng + (r.Float64()-0.5)*spanDeg}
+		if i%5 == 0 && i > 0 {
+			// Near an earlier point.
+			q := points[r.IntN(i)]
+			p.Lat, p.Lng = q.Lat+(r.Float64()-0.5)*0.01, q.Lng+(r.Float64()-0.5)*0.01
+		}
+		if p.Lng > 180 {
+			p.Lng -= 360
+		} else if p.Lng < -180 {
+			p.Lng += 360
+		}
+		points[i] = p
+	}
+	return points
+}
+
+func bruteForce(points []Point, epsMeters float64, minPoints int) []Cluster {
+	labels, count := label(len(points), minPoints, func(idx int) []int {
+		return regionQuery(points, idx, epsMeters)
+	})
+	return buildClusters(points, labels, count)
+}
+
+func TestClusterPointsMatchesBruteForce(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	cases := []struct {
+		name     string
+		lat, lng float64
+		span     float64
+		eps      float64
+	}{
+		{"metro", 34.05, -118.25, 1, 3000},
+		{"antimeridian", -17, 180, 2, 5000},
+		{"high latitude", 78, 15, 2, 2000},
+		{"pole", 89.9, 0, 0.2, 1000},
+		{"wide eps", 40, -100, 20, 500000},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			points := randomPoints(r, 1000, tc.lat, tc.lng, tc.span)
+			for _, minPoints := range []int{1, 3} {
+				want := bruteForce(points, tc.eps, minPoints)
+				require.NotEmpty(t, want)
+				require.Equal(t, want, ClusterPoints(points, tc.eps, minPoints))
+			}
+		})
+	}
+}
+
+// BenchmarkClusterPoints compares the grid index with the brute-force scan
+// on a metro-sized snapshot. The brute-force 10k case takes several seconds
+// per iteration.
+func BenchmarkClusterPoints(b *testing.B) {
+	for _, n := range []int{1000, 10000} {
+		points := randomPoints(rand.New(rand.NewPCG(1, 2)), n, 34.05, -118.25, 1.5)
+		b.Run(fmt.Sprintf("grid/%d", n), func(b *testing.B) {
+			for b.Loop() {
+				ClusterPoints(points, 3000, 2)
+			}
+		})
+		b.Run(fmt.Sprintf("bruteforce/%d", n), func(b *testing.B) {
+			for b.Loop() {
+				bruteForce(points, 3000, 2)
+			}
+		})
+	}
+}
+
+// track returns a position every second for three minutes from start, as the
+// SBS feed reports them, with position(t) giving the offset in meters east and
+// north of lat/lng.
+func track(id string, lat, lng float64, start time.Time, altitude float64, position func(t float64) (float64, float64)) []STPoint {
+	var points []STPoint
+	for s := 0.0; s <= 180; s++ {
+		east, north := position(s)
+		alt := altitude
+		points = append(points, STPoint{
+			Point:    Point{ID: id, Lat: lat + toDegrees(north/earthRadiusMeters), Lng: lng + toDegrees(east/(earthRadiusMeters*math.Cos(toRadians(lat))))},
+			Time:     start.Add(time.Duration(s) * time.Second),
+			Altitude: &alt,
+		})
+	}
+	return points
+}
+
+func TestClusterSTPoints(t *testing.T) {
+	start := time.Date(2024, 5, 1, 18, 0, 0, 0, time.UTC)
+	orbit := func(phase float64) func(float64) (float64, float64) {
+		return func(s float64) (float64, float64) {
+			a := s/60*2*math.Pi + phase
+			return 400 * math.Cos(a), 400 * math.Sin(a)
+		}
+	}
+
+	// Four aircraft orbiting one scene, 500ft apart vertically.
+	var points []STPoint
+	for i, id := range []string{"news", "police", "sheriff", "fire"} {
+		points = append(points, track(id, 34.05, -118.25, start, 1000+500*float64(i), orbit(float64(i)*math.Pi/2))...)
+	}
+	// Two aircraft crossing at 50 m/s halfway through.
+	points = append(points, track("east", 34.2, -118.4, start, 1200, func(s float64) (float64, float64) { return 50 * (s - 90), 0 })...)
+	points = append(points, track("north", 34.2, -118.4, start, 1200, func(s float64) (float64, float64) { return 0, 50 * (s - 90) })...)
+	// An aircraft orbiting the scene after the others have left.
+	points = append(points, track("late", 34.05, -118.25, start.Add(5*time.Minute), 1000, orbit(0))...)
+
+	members := func(c STCluster) []string {
+		var ids []string
+		for _, p := range c.Points {
+			if !slices.Contains(ids, p.ID) {
+				ids = append(ids, p.ID)
+			}
+		}
+		return ids
+	}
+
+	// Each aircraft has dozens of its own positions within reach; only
+	// other aircraft count, so neither the crossing pair nor the late
+	// arrival is dense with the default min_points.
+	opts := STOptions{EpsMeters: 1000, EpsTime: time.Minute, MinPoints: 3}
+	clusters := ClusterSTPoints(points, opts)
+	require.Len(t, clusters, 1)
+	require.ElementsMatch(t, []string{"news", "police", "sheriff", "fire"}, members(clusters[0]))
+
+	// A snapshot at the crossing clusters the passing pair too.
+	var snapshot []Point
+	for _, p := range points {
+		if p.Time.Equal(start.Add(90 * time.Second)) {
+			snapshot = append(snapshot, p.Point)
+		}
+	}
+	require.Len(t, ClusterPoints(snapshot, 1000, 1), 2)
+
+	// Within 300ft vertically, no orbiting aircraft has another nearby, and
+	// only the crossing pair is dense for even a single other aircraft.
+	opts.EpsAltitude = 300
+	opts.MinPoints = 1
+	clusters = ClusterSTPoints(points, opts)
+	require.Len(t, clusters, 1)
+	require.ElementsMatch(t, []string{"east", "north"}, members(clusters[0]))
+}
+
+func TestTracker(t *testing.T) {
+	ids := 0
+	tracker := NewTracker(TrackerOptions{
+		MaxDistanceMeters: 1000,
+		Expiry:            time.Minute,
+		NewID:             func() string { ids++; return "track-" + strconv.Itoa(ids) },
+	})
+	now := time.Date(2024, 5, 1, 18, 0, 0, 0, time.UTC)
+	at := func(lat float64, ids ...string) Cluster {
+		c := Cluster{ID: "cluster-1"}
+		for _, id := range ids {
+			c.Points = append(c.Points, Point{ID: id, Lat: lat, Lng: -118.25})
+		}
+		return c
+	}
+	update := func(clusters ...Cluster) ([]string, []TrackEvent) {
+		now = now.Add(30 * time.Second)
+		tracks, events := tracker.Update(now, clusters)
+		var got []string
+		for i, tr := range tracks {
+			require.Equal(t, tr.ID, clusters[i].ID)
+			got = append(got, tr.ID)
+		}
+		return got, events
+	}
+
+	got, events := update(at(34.0, "a", "b"), at(34.5, "c", "d"))
+	require.Equal(t, []string{"track-1", "track-2"}, got)
+	require.Equal(t, []TrackEvent{{Type: TrackFormed, ID: "track-1"}, {Type: TrackFormed, ID: "track-2"}}, events)
+
+	// Runs number clusters afresh; tracks follow the members, and a nearby
+	// cluster with new members continues its track.
+	got, events = update(at(34.5, "c", "d", "e"), at(34.001, "x", "y"))
+	require.Equal(t, []string{"track-2", "track-1"}, got)
+	require.Empty(t, events)
+
+	// Split: the part sharing most points keeps the ID.
+	got, events = update(at(34.5, "c", "d"), at(34.6, "e", "f"), at(34.001, "x", "y"))
+	require.Equal(t, []string{"track-2", "track-3", "track-1"}, got)
+	require.Equal(t, []TrackEvent{{Type: TrackSplit, ID: "track-2", Related: []string{"track-3"}}}, events)
+
+	// Merge: track-3 is absorbed into track-2.
+	got, events = update(at(34.55, "c", "d", "e", "f"), at(34.001, "x", "y"))
+	require.Equal(t, []string{"track-2", "track-1"}, got)
+	require.Equal(t, []TrackEvent{{Type: TrackMerged, ID: "track-2", Related: []string{"track-3"}}}, events)
+
+	// A track missing for less than the expiry keeps its ID.
+	_, events = update(at(34.55, "c", "d", "e", "f"))
+	require.Empty(t, events)
+	got, _ = update(at(34.55, "c", "d", "e", "f"), at(34.001, "x", "y"))
+	require.Equal(t, []string{"track-2", "track-1"}, got)
+
+	update(at(34.55, "c", "d"))
+	update(at(34.55, "c", "d"))
+	_, events = update(at(34.55, "c", "d"))
+	require.Equal(t, []TrackEvent{{Type: TrackDissolved, ID: "track-1"}}, events)
+	require.Len(t, tracker.Tracks(), 1)
+}
@@ -0,0 +1,103 @@
+package dbscan
+
+import (
+	"math"
+	"time"
+)
+
+// STPoint is a timestamped point for spatio-temporal clustering.
+type STPoint struct {
+	Point
+	Time     time.Time
+	Altitude *float64 // optional; nil matches any altitude
+}
+
+// STOptions configures ClusterSTPoints. Two points are neighbors when they
+// are within EpsMeters, within EpsTime of each other and, if EpsAltitude is
+// set and both have an altitude, within EpsAltitude vertically.
+type STOptions struct {
+	EpsMeters   float64
+	EpsTime     time.Duration
+	EpsAltitude float64 // same unit as STPoint.Altitude; 0 disables
+	// MinPoints is the number of other objects, by point ID, a point needs
+	// among its neighbors to be dense. An object's own earlier and later
+	// points never count, however often it reports.
+	MinPoints int
+}
+
+// STCluster represents a set of timestamped points assigned to the same
+// cluster.
+type STCluster struct {
+	ID     string
+	Points []STPoint
+}
+
+// ClusterSTPoints performs ST-DBSCAN clustering. Unlike ClusterPoints, a
+// region is only dense if enough other objects were there at about the same
+// time, so objects that visit a place at different times do not cluster, and
+// neither does one object's own densely sampled track.
+func ClusterSTPoints(points []STPoint, opts STOptions) []STCluster {
+	if opts.EpsMeters <= 0 || opts.EpsTime <= 0 || opts.MinPoints <= 0 || len(points) == 0 {
+		return nil
+	}
+
+	spatial := make([]Point, len(points))
+	for i, p := range points {
+		spatial[i] = p.Point
+	}
+	index := newGrid(spatial, opts.EpsMeters)
+
+	dense := func(idx int, neighbors []int) bool {
+		others := make(map[string]bool)
+		for _, i := range neighbors {
+			if id := points[i].ID; id != points[idx].ID {
+				others[id] = true
+				if len(others) >= opts.MinPoints {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	labels, count := labelDense(len(points), dense, func(idx int) []int {
+		neighbors := index.regionQuery(spatial, idx, opts.EpsMeters)
+		kept := neighbors[:0]
+		for _, i := range neighbors {
+			if opts.temporalNeighbors(points[idx], points[i]) {
+				kept = append(kept, i)
+			}
+		}
+		return kept
+	})
+	if count == 0 {
+		return nil
+	}
+
+	clusters := make([]STCluster, count)
+	for i := range clusters {
+		clusters[i] = STCluster{ID: generateClusterID(i + 1), Points: []STPoint{}}
+	}
+	for idx, l := range labels {
+		if l <= 0 {
+			continue
+		}
+		clusters[l-1].Points = append(clusters[l-1].Points, points[idx])
+	}
+	return clusters
+}
+
+// temporalNeighbors applies the time and altitude limits to spatial
+// neighbors a and b.
+func (o STOptions) temporalNeighbors(a, b STPoint) bool {
+	dt := a.Time.Sub(b.Time)
+	if dt < 0 {
+		dt = -dt
+	}
+	if dt > o.EpsTime {
+		return false
+	}
+	if o.EpsAltitude > 0 && a.Altitude != nil && b.Altitude != nil {
+		return math.Abs(*a.Altitude-*b.Altitude) <= o.EpsAltitude
+	}
+	return true
+}
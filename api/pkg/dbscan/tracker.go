@@ -0,0 +1,218 @@
+package dbscan
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Track is a cluster followed across clustering runs.
+type Track struct {
+	ID          string
+	Members     []string // point IDs
+	CentroidLat float64
+	CentroidLng float64
+	FirstSeen   time.Time
+	LastSeen    time.Time
+}
+
+// TrackEventType describes a change in tracked clusters.
+type TrackEventType string
+
+const (
+	// TrackFormed is a cluster that continues no earlier one.
+	TrackFormed TrackEventType = "formed"
+	// TrackSplit is a cluster whose members divided: ID continues with the
+	// part sharing the most points and Related lists new tracks for the rest.
+	TrackSplit TrackEventType = "split"
+	// TrackMerged is clusters that joined: ID is the surviving track and
+	// Related lists the tracks absorbed into it, which end.
+	TrackMerged TrackEventType = "merged"
+	// TrackDissolved is a cluster not seen for the tracker's expiry.
+	TrackDissolved TrackEventType = "dissolved"
+)
+
+// TrackEvent reports a change in tracked clusters.
+type TrackEvent struct {
+	Type    TrackEventType
+	ID      string
+	Related []string
+}
+
+// TrackerOptions configures a Tracker.
+type TrackerOptions struct {
+	// MaxDistanceMeters lets a cluster continue a track it shares no points
+	// with when their centroids are this close, e.g. when one helicopter
+	// leaves a scene as another arrives.
+	MaxDistanceMeters float64
+	// Expiry is how long an unmatched track is kept, so a cluster that
+	// briefly drops below the density threshold keeps its ID.
+	Expiry time.Duration
+	// NewID generates track IDs; it defaults to random UUIDs.
+	NewID func() string
+}
+
+// Tracker gives clusters stable IDs across runs of ClusterPoints, whose
+// cluster IDs only number the clusters of one run. A new cluster continues
+// the track it shares the most points with, or failing that the nearest
+// track within MaxDistanceMeters. Trackers are not safe for concurrent use.
+type Tracker struct {
+	opts   TrackerOptions
+	tracks []*Track
+}
+
+// NewTracker creates a new Tracker.
+func NewTracker(opts TrackerOptions) *Tracker {
+	if opts.NewID == nil {
+		opts.NewID = uuid.NewString
+	}
+	return &Tracker{opts: opts}
+}
+
+// Restore replaces the tracker's tracks, e.g. with those persisted before
+// a restart.
+func (t *Tracker) Restore(tracks []Track) {
+	t.tracks = make([]*Track, len(tracks))
+	for i := range tracks {
+		tr := tracks[i]
+		t.tracks[i] = &tr
+	}
+}
+
+// Tracks returns the current tracks, including unmatched ones not yet
+// expired.
+func (t *Tracker) Tracks() []Track {
+	tracks := make([]Track, len(t.tracks))
+	for i, tr := range t.tracks {
+		tracks[i] = *tr
+	}
+	return tracks
+}
+
+// Update matches one run's clusters to the tracks and returns, for each
+// cluster in order, its track, along with the changes since the last run.
+// Clusters' IDs are replaced with their track IDs.
+func (t *Tracker) Update(now time.Time, clusters []Cluster) ([]Track, []TrackEvent) {
+	type candidate struct {
+		cluster, track int
+		overlap        int
+		distance       float64
+	}
+
+	centroids := make([][2]float64, len(clusters))
+	members := make([][]string, len(clusters))
+	for i, c := range clusters {
+		members[i] = make([]string, len(c.Points))
+		for j, p := range c.Points {
+			members[i][j] = p.ID
+			centroids[i][0] += p.Lat
+			centroids[i][1] += p.Lng
+		}
+		if n := float64(len(c.Points)); n > 0 {
+			centroids[i][0] /= n
+			centroids[i][1] /= n
+		}
+	}
+
+	var candidates []candidate
+	for i := range clusters {
+		for j, tr := range t.tracks {
+			c := candidate{cluster: i, track: j, distance: haversineMeters(centroids[i][0], centroids[i][1], tr.CentroidLat, tr.CentroidLng)}
+			for _, id := range members[i] {
+				if slices.Contains(tr.Members, id) {
+					c.overlap++
+				}
+			}
+			if c.overlap > 0 || c.distance <= t.opts.MaxDistanceMeters {
+				candidates = append(candidates, c)
+			}
+		}
+	}
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		return cmp.Or(
+			cmp.Compare(b.overlap, a.overlap),
+			cmp.Compare(a.distance, b.distance),
+			t.tracks[a.track].FirstSeen.Compare(t.tracks[b.track].FirstSeen),
+		)
+	})
+
+	matched := make([]int, len(clusters)) // track index + 1
+	used := make([]bool, len(t.tracks))
+	for _, c := range candidates {
+		if matched[c.cluster] == 0 && !used[c.track] {
+			matched[c.cluster] = c.track + 1
+			used[c.track] = true
+		}
+	}
+
+	var events []TrackEvent
+	splits := make(map[string][]string)
+	merges := make(map[string][]string)
+	result := make([]Track, len(clusters))
+	for i := range clusters {
+		var tr *Track
+		if matched[i] > 0 {
+			tr = t.tracks[matched[i]-1]
+		} else {
+			tr = &Track{ID: t.opts.NewID(), FirstSeen: now}
+			t.tracks = append(t.tracks, tr)
+			// A cluster sharing points with a track continued by another
+			// cluster split off from it; candidates are sorted best first.
+			parent := ""
+			for _, c := range candidates {
+				if c.cluster == i && c.overlap > 0 {
+					parent = t.tracks[c.track].ID
+					break
+				}
+			}
+			if parent != "" {
+				splits[parent] = append(splits[parent], tr.ID)
+			} else {
+				events = append(events, TrackEvent{Type: TrackFormed, ID: tr.ID})
+			}
+		}
+		tr.Members = members[i]
+		tr.CentroidLat, tr.CentroidLng = centroids[i][0], centroids[i][1]
+		tr.LastSeen = now
+		clusters[i].ID = tr.ID
+		result[i] = *tr
+	}
+
+	// A track left unmatched that shares points with a cluster was absorbed
+	// by that cluster's track.
+	for j := range used {
+		if used[j] {
+			continue
+		}
+		for _, c := range candidates {
+			if c.track == j && c.overlap > 0 {
+				survivor := clusters[c.cluster].ID
+				merges[survivor] = append(merges[survivor], t.tracks[j].ID)
+				t.tracks[j].LastSeen = time.Time{}
+				break
+			}
+		}
+	}
+
+	for _, tr := range t.tracks {
+		if ids, ok := splits[tr.ID]; ok {
+			events = append(events, TrackEvent{Type: TrackSplit, ID: tr.ID, Related: ids})
+		}
+		if ids, ok := merges[tr.ID]; ok {
+			events = append(events, TrackEvent{Type: TrackMerged, ID: tr.ID, Related: ids})
+		}
+	}
+	t.tracks = slices.DeleteFunc(t.tracks, func(tr *Track) bool {
+		if tr.LastSeen.IsZero() {
+			return true // merged
+		}
+		if now.Sub(tr.LastSeen) > t.opts.Expiry {
+			events = append(events, TrackEvent{Type: TrackDissolved, ID: tr.ID})
+			return true
+		}
+		return false
+	})
+	return result, events
+}
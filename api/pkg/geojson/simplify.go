@@ -0,0 +1,78 @@
+package geojson
+
+import "math"
+
+// earthRadiusMeters is the mean Earth radius.
+const earthRadiusMeters = 6371000.0
+
+// Simplify reduces a path of longitude/latitude points with the
+// Douglas–Peucker algorithm and returns the indexes of the points to keep,
+// in order. toleranceMeters is the largest distance a dropped point may lie
+// from the simplified path; a tolerance <= 0 keeps every point. Returning
+// indexes lets callers keep per-vertex data such as timestamps.
+func Simplify(points []Point, toleranceMeters float64) []int {
+	if len(points) <= 2 || toleranceMeters <= 0 {
+		keep := make([]int, len(points))
+		for i := range keep {
+			keep[i] = i
+		}
+		return keep
+	}
+
+	// Project onto a plane in meters around the path's mean latitude. Over
+	// the extent of a flight track the distortion is far below typical
+	// tolerances.
+	var sumLat float64
+	for _, p := range points {
+		sumLat += p.Y
+	}
+	cosLat := math.Cos(sumLat / float64(len(points)) * math.Pi / 180)
+	xy := make([]Point, len(points))
+	for i, p := range points {
+		xy[i] = Point{
+			X: p.X * math.Pi / 180 * earthRadiusMeters * cosLat,
+			Y: p.Y * math.Pi / 180 * earthRadiusMeters,
+		}
+	}
+
+	keep := make([]bool, len(points))
+	keep[0], keep[len(points)-1] = true, true
+
+	// Iterative to bound stack use on long tracks.
+	type span struct{ first, last int }
+	stack := []span{{0, len(points) - 1}}
+	for len(stack) > 0 {
+		s := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		maxDist, index := 0.0, -1
+		for i := s.first + 1; i < s.last; i++ {
+			if d := segmentDistance(xy[i], xy[s.first], xy[s.last]); d > maxDist {
+				maxDist, index = d, i
+			}
+		}
+		if index >= 0 && maxDist > toleranceMeters {
+			keep[index] = true
+			stack = append(stack, span{s.first, index}, span{index, s.last})
+		}
+	}
+
+	var indexes []int
+	for i, k := range keep {
+		if k {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// segmentDistance returns the distance from p to the segment a-b.
+func segmentDistance(p, a, b Point) float64 {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	if dx == 0 && dy == 0 {
+		return math.Hypot(p.X-a.X, p.Y-a.Y)
+	}
+	t := ((p.X-a.X)*dx + (p.Y-a.Y)*dy) / (dx*dx + dy*dy)
+	t = math.Max(0, math.Min(1, t))
+	return math.Hypot(p.X-(a.X+t*dx), p.Y-(a.Y+t*dy))
+}
@@ -0,0 +1,22 @@
+package geojson
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimplify(t *testing.T) {
+	// An L-shaped path with ~1.1km legs, a 1m wobble at index 1 and the
+	// corner at index 2, ~1.6km from the straight line between the ends.
+	points := []Point{
+		{X: 0.00, Y: 0}, {X: 0.01, Y: 0.00001}, {X: 0.02, Y: 0},
+		{X: 0.02, Y: 0.01}, {X: 0.02, Y: 0.02},
+	}
+
+	require.Equal(t, []int{0, 2, 4}, Simplify(points, 100))
+	require.Equal(t, []int{0, 4}, Simplify(points, 2000))
+	require.Equal(t, []int{0, 1, 2, 3, 4}, Simplify(points, 0))
+	require.Equal(t, []int{0}, Simplify(points[:1], 100))
+	require.Empty(t, Simplify(nil, 100))
+}